@@ -42,9 +42,9 @@ func (t Foo) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.B
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -61,9 +61,9 @@ func (t Foo) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.C
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -72,7 +72,7 @@ func (t Foo) WriteBinary(writer io.Writer) error {
 				return err
 			}
 
-			_, err := writer.Write(v)
+			_, err := writer.Write([]byte(v))
 			if err != nil {
 				return err
 			}
@@ -80,7 +80,7 @@ func (t Foo) WriteBinary(writer io.Writer) error {
 		{
 
 			{
-				x := t.D.A
+				x := uint64(t.D.A)
 				bs := make([]byte, 8)
 				binary.LittleEndian.PutUint64(bs, x)
 				_, err := writer.Write(bs)
@@ -91,9 +91,9 @@ func (t Foo) WriteBinary(writer io.Writer) error {
 
 			{
 				v := t.D.B
-				len := len(v)
-				ux := uint64(len) << 1
-				if len < 0 {
+				n := len(v)
+				ux := uint64(n) << 1
+				if n < 0 {
 					ux = ^ux
 				}
 				sz := make([]byte, 8)
@@ -110,9 +110,9 @@ func (t Foo) WriteBinary(writer io.Writer) error {
 		}
 
 		{
-			len := len(t.E)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(t.E)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			bs := make([]byte, 8)
@@ -122,7 +122,7 @@ func (t Foo) WriteBinary(writer io.Writer) error {
 				return err
 			}
 
-			for i := 0; i < len; i++ {
+			for i := 0; i < n; i++ {
 				x := t.E[i]
 				ux := uint64(x) << 1
 				if x < 0 {
@@ -192,46 +192,53 @@ func (t *Foo) DecodeBinary(reader io.Reader) error {
 				x = ^x
 			}
 			t.A = int(x)
+
 		}
 
 		{
-			var sz = make([]byte, 8)
-			if _, err := io.ReadFull(reader, sz); err != nil {
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
 				return err
 			}
 
-			ux := binary.LittleEndian.Uint64(sz)
+			ux := binary.LittleEndian.Uint64(bs)
 			x := int64(ux >> 1)
 			if ux&1 != 0 {
 				x = ^x
 			}
 
-			b := make([]byte, int(x))
+			sz := int(x)
+
+			b := make([]byte, sz)
 			if _, err := io.ReadFull(reader, b); err != nil {
 				return err
 			}
 
 			t.B = string(b)
+
 		}
 
 		{
-			var sz = make([]byte, 8)
-			if _, err := io.ReadFull(reader, sz); err != nil {
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
 				return err
 			}
 
-			ux := binary.LittleEndian.Uint64(sz)
+			ux := binary.LittleEndian.Uint64(bs)
 			x := int64(ux >> 1)
 			if ux&1 != 0 {
 				x = ^x
 			}
 
-			b := make([]byte, int(x))
+			sz := int(x)
+
+			b := make([]byte, sz)
 			if _, err := io.ReadFull(reader, b); err != nil {
 				return err
 			}
 
-			t.C = b
+			t.C = []byte(b)
+
 		}
 		{
 
@@ -242,27 +249,31 @@ func (t *Foo) DecodeBinary(reader io.Reader) error {
 				}
 
 				ux := binary.LittleEndian.Uint64(bs)
-				t.D.A = ux
+				t.D.A = uint64(ux)
+
 			}
 
 			{
-				var sz = make([]byte, 8)
-				if _, err := io.ReadFull(reader, sz); err != nil {
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
 					return err
 				}
 
-				ux := binary.LittleEndian.Uint64(sz)
+				ux := binary.LittleEndian.Uint64(bs)
 				x := int64(ux >> 1)
 				if ux&1 != 0 {
 					x = ^x
 				}
 
-				b := make([]byte, int(x))
+				sz := int(x)
+
+				b := make([]byte, sz)
 				if _, err := io.ReadFull(reader, b); err != nil {
 					return err
 				}
 
 				t.D.B = string(b)
+
 			}
 		}
 
@@ -278,11 +289,11 @@ func (t *Foo) DecodeBinary(reader io.Reader) error {
 				x = ^x
 			}
 
-			len := int(x)
+			sz := int(x)
 
-			t.E = make([]int, len)
+			t.E = make([]int, sz)
 
-			for i := 0; i < len; i++ {
+			for i := 0; i < sz; i++ {
 				var bs = make([]byte, 8)
 				if _, err := io.ReadFull(reader, bs); err != nil {
 					return err
@@ -293,8 +304,10 @@ func (t *Foo) DecodeBinary(reader io.Reader) error {
 				if ux&1 != 0 {
 					x = ^x
 				}
-				t.E[i] = int(x)
+				(t.E)[i] = int(x)
+
 			}
+
 		}
 
 		{
@@ -309,8 +322,10 @@ func (t *Foo) DecodeBinary(reader io.Reader) error {
 				if ux&1 != 0 {
 					x = ^x
 				}
-				t.F[i] = int(x)
+				(t.F)[i] = int(x)
+
 			}
+
 		}
 
 		{
@@ -319,7 +334,8 @@ func (t *Foo) DecodeBinary(reader io.Reader) error {
 				return err
 			}
 
-			t.G = v[0] == 1
+			t.G = bool(v[0] == 1)
+
 		}
 	}
 
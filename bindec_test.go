@@ -8,11 +8,18 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"net"
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
+
+	"github.com/erizocosmico/bindec/internal/testpkg"
+	data2 "github.com/erizocosmico/bindec/internal/testpkg/data"
+	"github.com/erizocosmico/bindec/internal/testpkg/v1/model"
+	model2 "github.com/erizocosmico/bindec/internal/testpkg/v2/model"
 )
 
 var _ = binary.LittleEndian
@@ -160,9 +167,9 @@ func (t StructTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.String
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -255,9 +262,9 @@ func (t StructTestType) WriteBinary(writer io.Writer) error {
 		}
 
 		{
-			len := len(t.Slice)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(t.Slice)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			bs := make([]byte, 8)
@@ -267,7 +274,7 @@ func (t StructTestType) WriteBinary(writer io.Writer) error {
 				return err
 			}
 
-			for i := 0; i < len; i++ {
+			for i := 0; i < n; i++ {
 				x := int16(t.Slice[i])
 				ux := uint16(x) << 1
 				if x < 0 {
@@ -284,9 +291,9 @@ func (t StructTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.Bytes
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -334,9 +341,9 @@ func (t StructTestType) WriteBinary(writer io.Writer) error {
 
 			{
 				v := t.Struct.Flield2
-				len := len(v)
-				ux := uint64(len) << 1
-				if len < 0 {
+				n := len(v)
+				ux := uint64(n) << 1
+				if n < 0 {
 					ux = ^ux
 				}
 				sz := make([]byte, 8)
@@ -369,9 +376,9 @@ func (t StructTestType) WriteBinary(writer io.Writer) error {
 
 			{
 				v := t.NamedStruct.Flield2
-				len := len(v)
-				ux := uint64(len) << 1
-				if len < 0 {
+				n := len(v)
+				ux := uint64(n) << 1
+				if n < 0 {
 					ux = ^ux
 				}
 				sz := make([]byte, 8)
@@ -415,9 +422,9 @@ func (t StructTestType) WriteBinary(writer io.Writer) error {
 
 					{
 						v := (*t.StructPointer).Flield2
-						len := len(v)
-						ux := uint64(len) << 1
-						if len < 0 {
+						n := len(v)
+						ux := uint64(n) << 1
+						if n < 0 {
 							ux = ^ux
 						}
 						sz := make([]byte, 8)
@@ -664,6 +671,7 @@ func (t *StructTestType) DecodeBinary(reader io.Reader) error {
 				}
 
 				t.Pointer = &tmp_t_Pointer
+
 			}
 		}
 
@@ -689,6 +697,7 @@ func (t *StructTestType) DecodeBinary(reader io.Reader) error {
 				}
 
 				t.NilPointer = &tmp_t_NilPointer
+
 			}
 		}
 
@@ -848,7 +857,7 @@ func (t *StructTestType) DecodeBinary(reader io.Reader) error {
 
 		{
 			var v = make([]byte, 1)
-			if _, err := io.ReadFull(reader, v); err != nil {
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
 				return err
 			}
 
@@ -898,6 +907,7 @@ func (t *StructTestType) DecodeBinary(reader io.Reader) error {
 				}
 
 				t.StructPointer = &tmp_t_StructPointer
+
 			}
 		}
 	}
@@ -919,9 +929,9 @@ func (t MapTestType) EncodeBinary() ([]byte, error) {
 func (t MapTestType) WriteBinary(writer io.Writer) error {
 
 	{
-		len := len(t)
-		ux := uint64(len) << 1
-		if len < 0 {
+		n := len(t)
+		ux := uint64(n) << 1
+		if n < 0 {
 			ux = ^ux
 		}
 		bs := make([]byte, 8)
@@ -1078,9 +1088,9 @@ func (t SliceTestType) EncodeBinary() ([]byte, error) {
 func (t SliceTestType) WriteBinary(writer io.Writer) error {
 
 	{
-		len := len(t)
-		ux := uint64(len) << 1
-		if len < 0 {
+		n := len(t)
+		ux := uint64(n) << 1
+		if n < 0 {
 			ux = ^ux
 		}
 		bs := make([]byte, 8)
@@ -1090,7 +1100,7 @@ func (t SliceTestType) WriteBinary(writer io.Writer) error {
 			return err
 		}
 
-		for i := 0; i < len; i++ {
+		for i := 0; i < n; i++ {
 			x := uint16(t[i])
 			bs := make([]byte, 2)
 			binary.LittleEndian.PutUint16(bs, x)
@@ -1853,9 +1863,9 @@ func (t StringTestType) WriteBinary(writer io.Writer) error {
 
 	{
 		v := t
-		len := len(v)
-		ux := uint64(len) << 1
-		if len < 0 {
+		n := len(v)
+		ux := uint64(n) << 1
+		if n < 0 {
 			ux = ^ux
 		}
 		sz := make([]byte, 8)
@@ -1925,9 +1935,9 @@ func (t BytesTestType) WriteBinary(writer io.Writer) error {
 
 	{
 		v := t
-		len := len(v)
-		ux := uint64(len) << 1
-		if len < 0 {
+		n := len(v)
+		ux := uint64(n) << 1
+		if n < 0 {
 			ux = ^ux
 		}
 		sz := make([]byte, 8)
@@ -2049,9 +2059,9 @@ func (t AlphaTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2108,6 +2118,7 @@ func (t *AlphaTestType) DecodeBinary(reader io.Reader) error {
 					return fmt.Errorf("field '%v' contains non alpha characters", "S")
 				}
 			}
+
 		}
 	}
 
@@ -2130,9 +2141,9 @@ func (t AlphanumTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2189,6 +2200,7 @@ func (t *AlphanumTestType) DecodeBinary(reader io.Reader) error {
 					return fmt.Errorf("field '%v' contains non alphanumeric characters", "S")
 				}
 			}
+
 		}
 	}
 
@@ -2211,9 +2223,9 @@ func (t NumericTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2270,6 +2282,7 @@ func (t *NumericTestType) DecodeBinary(reader io.Reader) error {
 					return fmt.Errorf("field '%v' contains non numeric characters", "S")
 				}
 			}
+
 		}
 	}
 
@@ -2292,9 +2305,9 @@ func (t HexadecimalTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2349,6 +2362,7 @@ func (t *HexadecimalTestType) DecodeBinary(reader io.Reader) error {
 			if !hexadecimalConstraintRegex.MatchString(t.S) {
 				return fmt.Errorf("field '%v' is not a valid hexadecimal string", "S")
 			}
+
 		}
 	}
 
@@ -2371,9 +2385,9 @@ func (t EmailTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2428,6 +2442,7 @@ func (t *EmailTestType) DecodeBinary(reader io.Reader) error {
 			if !emailConstraintRegex.MatchString(t.S) {
 				return fmt.Errorf("field '%v' is not a valid email", "S")
 			}
+
 		}
 	}
 
@@ -2450,9 +2465,9 @@ func (t URLTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2507,6 +2522,7 @@ func (t *URLTestType) DecodeBinary(reader io.Reader) error {
 			if url, err := url.ParseRequestURI(t.S); err != nil || url.Scheme == "" {
 				return fmt.Errorf("field '%v' is not a valid URL", "S")
 			}
+
 		}
 	}
 
@@ -2529,9 +2545,9 @@ func (t Base64TestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2586,6 +2602,7 @@ func (t *Base64TestType) DecodeBinary(reader io.Reader) error {
 			if !base64ConstraintRegex.MatchString(t.S) {
 				return fmt.Errorf("field '%v' is not a valid base64 string", "S")
 			}
+
 		}
 	}
 
@@ -2608,9 +2625,9 @@ func (t ContainsTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2665,6 +2682,7 @@ func (t *ContainsTestType) DecodeBinary(reader io.Reader) error {
 			if !strings.Contains(t.S, "worl") {
 				return fmt.Errorf("field '%v' does not contain '%v'", "S", "worl")
 			}
+
 		}
 	}
 
@@ -2687,9 +2705,9 @@ func (t StartsWithTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2744,6 +2762,7 @@ func (t *StartsWithTestType) DecodeBinary(reader io.Reader) error {
 			if !strings.HasPrefix(t.S, "Hello") {
 				return fmt.Errorf("field '%v' does not start with '%v'", "S", "Hello")
 			}
+
 		}
 	}
 
@@ -2766,9 +2785,9 @@ func (t EndsWithTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -2823,6 +2842,7 @@ func (t *EndsWithTestType) DecodeBinary(reader io.Reader) error {
 			if !strings.HasSuffix(t.S, "world") {
 				return fmt.Errorf("field '%v' does not end with '%v'", "S", "world")
 			}
+
 		}
 	}
 
@@ -2969,9 +2989,9 @@ func (t EqTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.String
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -3041,6 +3061,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint8 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Uint8", 6)
 			}
+
 		}
 
 		{
@@ -3059,6 +3080,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int8 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Int8", 6)
 			}
+
 		}
 
 		{
@@ -3073,6 +3095,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint16 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Uint16", 6)
 			}
+
 		}
 
 		{
@@ -3091,6 +3114,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int16 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Int16", 6)
 			}
+
 		}
 
 		{
@@ -3105,6 +3129,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint32 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Uint32", 6)
 			}
+
 		}
 
 		{
@@ -3123,6 +3148,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int32 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Int32", 6)
 			}
+
 		}
 
 		{
@@ -3137,6 +3163,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint64 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Uint64", 6)
 			}
+
 		}
 
 		{
@@ -3155,6 +3182,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int64 != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Int64", 6)
 			}
+
 		}
 
 		{
@@ -3169,6 +3197,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Uint", 6)
 			}
+
 		}
 
 		{
@@ -3187,6 +3216,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Int", 6)
 			}
+
 		}
 
 		{
@@ -3201,6 +3231,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uintptr != 6 {
 				return fmt.Errorf("field '%v' does not equal %v", "Uintptr", 6)
 			}
+
 		}
 
 		{
@@ -3227,6 +3258,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.String != "hello" {
 				return fmt.Errorf("field '%v' does not equal %v", "String", "hello")
 			}
+
 		}
 
 		{
@@ -3240,6 +3272,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Bool != true {
 				return fmt.Errorf("field '%v' does not equal %v", "Bool", true)
 			}
+
 		}
 
 		{
@@ -3253,6 +3286,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float32 != 3.14 {
 				return fmt.Errorf("field '%v' does not equal %v", "Float32", 3.14)
 			}
+
 		}
 
 		{
@@ -3266,6 +3300,7 @@ func (t *EqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float64 != 3.14 {
 				return fmt.Errorf("field '%v' does not equal %v", "Float64", 3.14)
 			}
+
 		}
 	}
 
@@ -3412,9 +3447,9 @@ func (t NeqTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.String
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -3484,6 +3519,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint8 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Uint8", 6)
 			}
+
 		}
 
 		{
@@ -3502,6 +3538,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int8 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Int8", 6)
 			}
+
 		}
 
 		{
@@ -3516,6 +3553,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint16 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Uint16", 6)
 			}
+
 		}
 
 		{
@@ -3534,6 +3572,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int16 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Int16", 6)
 			}
+
 		}
 
 		{
@@ -3548,6 +3587,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint32 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Uint32", 6)
 			}
+
 		}
 
 		{
@@ -3566,6 +3606,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int32 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Int32", 6)
 			}
+
 		}
 
 		{
@@ -3580,6 +3621,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint64 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Uint64", 6)
 			}
+
 		}
 
 		{
@@ -3598,6 +3640,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int64 == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Int64", 6)
 			}
+
 		}
 
 		{
@@ -3612,6 +3655,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Uint", 6)
 			}
+
 		}
 
 		{
@@ -3630,6 +3674,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Int", 6)
 			}
+
 		}
 
 		{
@@ -3644,6 +3689,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uintptr == 6 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Uintptr", 6)
 			}
+
 		}
 
 		{
@@ -3670,6 +3716,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.String == "hello" {
 				return fmt.Errorf("field '%v' should not be equal to %v", "String", "hello")
 			}
+
 		}
 
 		{
@@ -3683,6 +3730,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Bool == true {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Bool", true)
 			}
+
 		}
 
 		{
@@ -3696,6 +3744,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float32 == 3.14 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Float32", 3.14)
 			}
+
 		}
 
 		{
@@ -3709,6 +3758,7 @@ func (t *NeqTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float64 == 3.14 {
 				return fmt.Errorf("field '%v' should not be equal to %v", "Float64", 3.14)
 			}
+
 		}
 	}
 
@@ -3731,9 +3781,9 @@ func (t UUIDTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -3788,6 +3838,7 @@ func (t *UUIDTestType) DecodeBinary(reader io.Reader) error {
 			if !uuidConstraintRegex.MatchString(t.S) {
 				return fmt.Errorf("field '%v' is not a valid UUID", "S")
 			}
+
 		}
 	}
 
@@ -3810,9 +3861,9 @@ func (t IPTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -3867,6 +3918,7 @@ func (t *IPTestType) DecodeBinary(reader io.Reader) error {
 			if net.ParseIP(t.S) == nil {
 				return fmt.Errorf("field '%v' is not a valid IP address", "S")
 			}
+
 		}
 	}
 
@@ -3889,9 +3941,9 @@ func (t IPv4TestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -3946,6 +3998,7 @@ func (t *IPv4TestType) DecodeBinary(reader io.Reader) error {
 			if ip := net.ParseIP(t.S); ip == nil || ip.To4() == nil {
 				return fmt.Errorf("field '%v' is not a valid IPv4", "S")
 			}
+
 		}
 	}
 
@@ -3968,9 +4021,9 @@ func (t IPv6TestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.S
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -4025,6 +4078,7 @@ func (t *IPv6TestType) DecodeBinary(reader io.Reader) error {
 			if ip := net.ParseIP(t.S); ip == nil || ip.To4() != nil {
 				return fmt.Errorf("field '%v' is not a valid IPv6", "S")
 			}
+
 		}
 	}
 
@@ -4171,9 +4225,9 @@ func (t OneOfTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.String
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -4243,6 +4297,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint8 != 6 && t.Uint8 != 2 && t.Uint8 != 3 {
 				return fmt.Errorf("field 'Uint8' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4261,6 +4316,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int8 != 6 && t.Int8 != 2 && t.Int8 != 3 {
 				return fmt.Errorf("field 'Int8' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4275,6 +4331,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint16 != 6 && t.Uint16 != 2 && t.Uint16 != 3 {
 				return fmt.Errorf("field 'Uint16' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4293,6 +4350,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int16 != 6 && t.Int16 != 2 && t.Int16 != 3 {
 				return fmt.Errorf("field 'Int16' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4307,6 +4365,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint32 != 6 && t.Uint32 != 2 && t.Uint32 != 3 {
 				return fmt.Errorf("field 'Uint32' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4325,6 +4384,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int32 != 6 && t.Int32 != 2 && t.Int32 != 3 {
 				return fmt.Errorf("field 'Int32' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4339,6 +4399,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint64 != 6 && t.Uint64 != 2 && t.Uint64 != 3 {
 				return fmt.Errorf("field 'Uint64' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4357,6 +4418,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int64 != 6 && t.Int64 != 2 && t.Int64 != 3 {
 				return fmt.Errorf("field 'Int64' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4371,6 +4433,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint != 6 && t.Uint != 2 && t.Uint != 3 {
 				return fmt.Errorf("field 'Uint' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4389,6 +4452,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int != 6 && t.Int != 2 && t.Int != 3 {
 				return fmt.Errorf("field 'Int' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4403,6 +4467,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uintptr != 6 && t.Uintptr != 2 && t.Uintptr != 3 {
 				return fmt.Errorf("field 'Uintptr' should have one of these values: %s", "6, 2, 3")
 			}
+
 		}
 
 		{
@@ -4429,6 +4494,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.String != "hello" && t.String != "world" && t.String != "foo" {
 				return fmt.Errorf("field 'String' should have one of these values: %s", "\"hello\", \"world\", \"foo\"")
 			}
+
 		}
 
 		{
@@ -4442,6 +4508,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Bool != true {
 				return fmt.Errorf("field 'Bool' should have one of these values: %s", "true")
 			}
+
 		}
 
 		{
@@ -4455,6 +4522,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float32 != 3.14 && t.Float32 != 1.1 && t.Float32 != 2.2 {
 				return fmt.Errorf("field 'Float32' should have one of these values: %s", "3.14, 1.1, 2.2")
 			}
+
 		}
 
 		{
@@ -4468,6 +4536,7 @@ func (t *OneOfTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float64 != 3.14 && t.Float64 != 1.1 && t.Float64 != 2.2 {
 				return fmt.Errorf("field 'Float64' should have one of these values: %s", "3.14, 1.1, 2.2")
 			}
+
 		}
 	}
 
@@ -4656,6 +4725,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint8 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Uint8", 6)
 			}
+
 		}
 
 		{
@@ -4674,6 +4744,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int8 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Int8", 6)
 			}
+
 		}
 
 		{
@@ -4688,6 +4759,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint16 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Uint16", 6)
 			}
+
 		}
 
 		{
@@ -4706,6 +4778,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int16 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Int16", 6)
 			}
+
 		}
 
 		{
@@ -4720,6 +4793,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint32 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Uint32", 6)
 			}
+
 		}
 
 		{
@@ -4738,6 +4812,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int32 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Int32", 6)
 			}
+
 		}
 
 		{
@@ -4752,6 +4827,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint64 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Uint64", 6)
 			}
+
 		}
 
 		{
@@ -4770,6 +4846,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int64 > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Int64", 6)
 			}
+
 		}
 
 		{
@@ -4784,6 +4861,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Uint", 6)
 			}
+
 		}
 
 		{
@@ -4802,6 +4880,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Int", 6)
 			}
+
 		}
 
 		{
@@ -4816,6 +4895,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uintptr > 6 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Uintptr", 6)
 			}
+
 		}
 
 		{
@@ -4829,6 +4909,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float32 > 3.14 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Float32", 3.14)
 			}
+
 		}
 
 		{
@@ -4842,6 +4923,7 @@ func (t *MaxTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float64 > 3.14 {
 				return fmt.Errorf("field '%v' has a maximum value of %v", "Float64", 3.14)
 			}
+
 		}
 	}
 
@@ -5030,6 +5112,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint8 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Uint8", 6)
 			}
+
 		}
 
 		{
@@ -5048,6 +5131,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int8 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Int8", 6)
 			}
+
 		}
 
 		{
@@ -5062,6 +5146,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint16 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Uint16", 6)
 			}
+
 		}
 
 		{
@@ -5080,6 +5165,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int16 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Int16", 6)
 			}
+
 		}
 
 		{
@@ -5094,6 +5180,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint32 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Uint32", 6)
 			}
+
 		}
 
 		{
@@ -5112,6 +5199,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int32 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Int32", 6)
 			}
+
 		}
 
 		{
@@ -5126,6 +5214,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint64 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Uint64", 6)
 			}
+
 		}
 
 		{
@@ -5144,6 +5233,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int64 < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Int64", 6)
 			}
+
 		}
 
 		{
@@ -5158,6 +5248,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uint < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Uint", 6)
 			}
+
 		}
 
 		{
@@ -5176,6 +5267,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Int < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Int", 6)
 			}
+
 		}
 
 		{
@@ -5190,6 +5282,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Uintptr < 6 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Uintptr", 6)
 			}
+
 		}
 
 		{
@@ -5203,6 +5296,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float32 < 3.14 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Float32", 3.14)
 			}
+
 		}
 
 		{
@@ -5216,6 +5310,7 @@ func (t *MinTestType) DecodeBinary(reader io.Reader) error {
 			if t.Float64 < 3.14 {
 				return fmt.Errorf("field '%v' has a minimum value of %v", "Float64", 3.14)
 			}
+
 		}
 	}
 
@@ -5238,9 +5333,9 @@ func (t MaxLenTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.String
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -5257,9 +5352,9 @@ func (t MaxLenTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.Bytes
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -5275,9 +5370,9 @@ func (t MaxLenTestType) WriteBinary(writer io.Writer) error {
 		}
 
 		{
-			len := len(t.Slice)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(t.Slice)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			bs := make([]byte, 8)
@@ -5287,7 +5382,7 @@ func (t MaxLenTestType) WriteBinary(writer io.Writer) error {
 				return err
 			}
 
-			for i := 0; i < len; i++ {
+			for i := 0; i < n; i++ {
 				x := t.Slice[i]
 				ux := uint64(x) << 1
 				if x < 0 {
@@ -5427,9 +5522,9 @@ func (t MinLenTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.String
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -5446,9 +5541,9 @@ func (t MinLenTestType) WriteBinary(writer io.Writer) error {
 
 		{
 			v := t.Bytes
-			len := len(v)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			sz := make([]byte, 8)
@@ -5464,9 +5559,9 @@ func (t MinLenTestType) WriteBinary(writer io.Writer) error {
 		}
 
 		{
-			len := len(t.Slice)
-			ux := uint64(len) << 1
-			if len < 0 {
+			n := len(t.Slice)
+			ux := uint64(n) << 1
+			if n < 0 {
 				ux = ^ux
 			}
 			bs := make([]byte, 8)
@@ -5476,7 +5571,7 @@ func (t MinLenTestType) WriteBinary(writer io.Writer) error {
 				return err
 			}
 
-			for i := 0; i < len; i++ {
+			for i := 0; i < n; i++ {
 				x := t.Slice[i]
 				ux := uint64(x) << 1
 				if x < 0 {
@@ -5599,3 +5694,5575 @@ func (t *MinLenTestType) DecodeBinary(reader io.Reader) error {
 
 	return nil
 }
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t TimeTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t TimeTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.Time
+			sec := x.Unix()
+			ux := uint64(sec) << 1
+			if sec < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 13)
+			binary.LittleEndian.PutUint64(bs, ux)
+			binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+			switch x.Location() {
+			case time.UTC:
+				bs[12] = 0
+			case time.Local:
+				bs[12] = 1
+			default:
+				bs[12] = 2
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+
+			if bs[12] == 2 {
+				_, offset := x.Zone()
+				name := x.Location().String()
+				uoffset := uint32(int32(offset)) << 1
+				if offset < 0 {
+					uoffset = ^uoffset
+				}
+				bs := make([]byte, 12)
+				binary.LittleEndian.PutUint32(bs, uoffset)
+				binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+
+				if _, err := writer.Write([]byte(name)); err != nil {
+					return err
+				}
+			}
+		}
+
+		{
+			if x := t.TimePointer; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					x := (*t.TimePointer)
+					sec := x.Unix()
+					ux := uint64(sec) << 1
+					if sec < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 13)
+					binary.LittleEndian.PutUint64(bs, ux)
+					binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+					switch x.Location() {
+					case time.UTC:
+						bs[12] = 0
+					case time.Local:
+						bs[12] = 1
+					default:
+						bs[12] = 2
+					}
+
+					if _, err := writer.Write(bs); err != nil {
+						return err
+					}
+
+					if bs[12] == 2 {
+						_, offset := x.Zone()
+						name := x.Location().String()
+						uoffset := uint32(int32(offset)) << 1
+						if offset < 0 {
+							uoffset = ^uoffset
+						}
+						bs := make([]byte, 12)
+						binary.LittleEndian.PutUint32(bs, uoffset)
+						binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+						if _, err := writer.Write(bs); err != nil {
+							return err
+						}
+
+						if _, err := writer.Write([]byte(name)); err != nil {
+							return err
+						}
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.NilTime; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					x := (*t.NilTime)
+					sec := x.Unix()
+					ux := uint64(sec) << 1
+					if sec < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 13)
+					binary.LittleEndian.PutUint64(bs, ux)
+					binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+					switch x.Location() {
+					case time.UTC:
+						bs[12] = 0
+					case time.Local:
+						bs[12] = 1
+					default:
+						bs[12] = 2
+					}
+
+					if _, err := writer.Write(bs); err != nil {
+						return err
+					}
+
+					if bs[12] == 2 {
+						_, offset := x.Zone()
+						name := x.Location().String()
+						uoffset := uint32(int32(offset)) << 1
+						if offset < 0 {
+							uoffset = ^uoffset
+						}
+						bs := make([]byte, 12)
+						binary.LittleEndian.PutUint32(bs, uoffset)
+						binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+						if _, err := writer.Write(bs); err != nil {
+							return err
+						}
+
+						if _, err := writer.Write([]byte(name)); err != nil {
+							return err
+						}
+					}
+				}
+
+			}
+		}
+
+		{
+			x := t.Duration
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *TimeTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *TimeTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 13)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			sec := int64(ux >> 1)
+			if ux&1 != 0 {
+				sec = ^sec
+			}
+			nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+			tm := time.Unix(sec, nsec)
+			switch bs[12] {
+			case 0:
+				tm = tm.UTC()
+			case 1:
+				tm = tm.Local()
+			case 2:
+				var bs = make([]byte, 12)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint32(bs)
+				offset := int32(ux >> 1)
+				if ux&1 != 0 {
+					offset = ^offset
+				}
+
+				ux2 := binary.LittleEndian.Uint64(bs[4:])
+				x := int64(ux2 >> 1)
+				if ux2&1 != 0 {
+					x = ^x
+				}
+
+				if x < 0 || x > math.MaxInt32 {
+					return fmt.Errorf("invalid time zone name length %d", x)
+				}
+
+				// The length is not trusted to allocate the bytes to read.
+				b, err := io.ReadAll(io.LimitReader(reader, x))
+				if err != nil {
+					return err
+				}
+				if int64(len(b)) < x {
+					return io.ErrUnexpectedEOF
+				}
+
+				name := string(b)
+				loc, err := time.LoadLocation(name)
+				if err != nil {
+					loc = time.FixedZone(name, int(offset))
+				} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+					loc = time.FixedZone(name, int(offset))
+				}
+				tm = tm.In(loc)
+			default:
+				return fmt.Errorf("invalid time zone kind: %d", bs[12])
+			}
+
+			t.Time = time.Time(tm)
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.TimePointer = nil
+			} else {
+				var tmp_t_TimePointer time.Time
+
+				{
+					var bs = make([]byte, 13)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					sec := int64(ux >> 1)
+					if ux&1 != 0 {
+						sec = ^sec
+					}
+					nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+					tm := time.Unix(sec, nsec)
+					switch bs[12] {
+					case 0:
+						tm = tm.UTC()
+					case 1:
+						tm = tm.Local()
+					case 2:
+						var bs = make([]byte, 12)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint32(bs)
+						offset := int32(ux >> 1)
+						if ux&1 != 0 {
+							offset = ^offset
+						}
+
+						ux2 := binary.LittleEndian.Uint64(bs[4:])
+						x := int64(ux2 >> 1)
+						if ux2&1 != 0 {
+							x = ^x
+						}
+
+						if x < 0 || x > math.MaxInt32 {
+							return fmt.Errorf("invalid time zone name length %d", x)
+						}
+
+						// The length is not trusted to allocate the bytes to read.
+						b, err := io.ReadAll(io.LimitReader(reader, x))
+						if err != nil {
+							return err
+						}
+						if int64(len(b)) < x {
+							return io.ErrUnexpectedEOF
+						}
+
+						name := string(b)
+						loc, err := time.LoadLocation(name)
+						if err != nil {
+							loc = time.FixedZone(name, int(offset))
+						} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+							loc = time.FixedZone(name, int(offset))
+						}
+						tm = tm.In(loc)
+					default:
+						return fmt.Errorf("invalid time zone kind: %d", bs[12])
+					}
+
+					tmp_t_TimePointer = time.Time(tm)
+
+				}
+
+				t.TimePointer = &tmp_t_TimePointer
+
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.NilTime = nil
+			} else {
+				var tmp_t_NilTime time.Time
+
+				{
+					var bs = make([]byte, 13)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					sec := int64(ux >> 1)
+					if ux&1 != 0 {
+						sec = ^sec
+					}
+					nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+					tm := time.Unix(sec, nsec)
+					switch bs[12] {
+					case 0:
+						tm = tm.UTC()
+					case 1:
+						tm = tm.Local()
+					case 2:
+						var bs = make([]byte, 12)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint32(bs)
+						offset := int32(ux >> 1)
+						if ux&1 != 0 {
+							offset = ^offset
+						}
+
+						ux2 := binary.LittleEndian.Uint64(bs[4:])
+						x := int64(ux2 >> 1)
+						if ux2&1 != 0 {
+							x = ^x
+						}
+
+						if x < 0 || x > math.MaxInt32 {
+							return fmt.Errorf("invalid time zone name length %d", x)
+						}
+
+						// The length is not trusted to allocate the bytes to read.
+						b, err := io.ReadAll(io.LimitReader(reader, x))
+						if err != nil {
+							return err
+						}
+						if int64(len(b)) < x {
+							return io.ErrUnexpectedEOF
+						}
+
+						name := string(b)
+						loc, err := time.LoadLocation(name)
+						if err != nil {
+							loc = time.FixedZone(name, int(offset))
+						} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+							loc = time.FixedZone(name, int(offset))
+						}
+						tm = tm.In(loc)
+					default:
+						return fmt.Errorf("invalid time zone kind: %d", bs[12])
+					}
+
+					tmp_t_NilTime = time.Time(tm)
+
+				}
+
+				t.NilTime = &tmp_t_NilTime
+
+			}
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Duration = time.Duration(x)
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t BeforeTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t BeforeTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.Time
+			sec := x.Unix()
+			ux := uint64(sec) << 1
+			if sec < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 13)
+			binary.LittleEndian.PutUint64(bs, ux)
+			binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+			switch x.Location() {
+			case time.UTC:
+				bs[12] = 0
+			case time.Local:
+				bs[12] = 1
+			default:
+				bs[12] = 2
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+
+			if bs[12] == 2 {
+				_, offset := x.Zone()
+				name := x.Location().String()
+				uoffset := uint32(int32(offset)) << 1
+				if offset < 0 {
+					uoffset = ^uoffset
+				}
+				bs := make([]byte, 12)
+				binary.LittleEndian.PutUint32(bs, uoffset)
+				binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+
+				if _, err := writer.Write([]byte(name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *BeforeTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *BeforeTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 13)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			sec := int64(ux >> 1)
+			if ux&1 != 0 {
+				sec = ^sec
+			}
+			nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+			tm := time.Unix(sec, nsec)
+			switch bs[12] {
+			case 0:
+				tm = tm.UTC()
+			case 1:
+				tm = tm.Local()
+			case 2:
+				var bs = make([]byte, 12)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint32(bs)
+				offset := int32(ux >> 1)
+				if ux&1 != 0 {
+					offset = ^offset
+				}
+
+				ux2 := binary.LittleEndian.Uint64(bs[4:])
+				x := int64(ux2 >> 1)
+				if ux2&1 != 0 {
+					x = ^x
+				}
+
+				if x < 0 || x > math.MaxInt32 {
+					return fmt.Errorf("invalid time zone name length %d", x)
+				}
+
+				// The length is not trusted to allocate the bytes to read.
+				b, err := io.ReadAll(io.LimitReader(reader, x))
+				if err != nil {
+					return err
+				}
+				if int64(len(b)) < x {
+					return io.ErrUnexpectedEOF
+				}
+
+				name := string(b)
+				loc, err := time.LoadLocation(name)
+				if err != nil {
+					loc = time.FixedZone(name, int(offset))
+				} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+					loc = time.FixedZone(name, int(offset))
+				}
+				tm = tm.In(loc)
+			default:
+				return fmt.Errorf("invalid time zone kind: %d", bs[12])
+			}
+
+			t.Time = time.Time(tm)
+
+			if !(t.Time).Before(time.Unix(1546300800, 0).UTC()) {
+				return fmt.Errorf("field '%v' should be before %v", "Time", time.Unix(1546300800, 0).UTC())
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t AfterTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t AfterTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.Time
+			sec := x.Unix()
+			ux := uint64(sec) << 1
+			if sec < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 13)
+			binary.LittleEndian.PutUint64(bs, ux)
+			binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+			switch x.Location() {
+			case time.UTC:
+				bs[12] = 0
+			case time.Local:
+				bs[12] = 1
+			default:
+				bs[12] = 2
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+
+			if bs[12] == 2 {
+				_, offset := x.Zone()
+				name := x.Location().String()
+				uoffset := uint32(int32(offset)) << 1
+				if offset < 0 {
+					uoffset = ^uoffset
+				}
+				bs := make([]byte, 12)
+				binary.LittleEndian.PutUint32(bs, uoffset)
+				binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+
+				if _, err := writer.Write([]byte(name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *AfterTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *AfterTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 13)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			sec := int64(ux >> 1)
+			if ux&1 != 0 {
+				sec = ^sec
+			}
+			nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+			tm := time.Unix(sec, nsec)
+			switch bs[12] {
+			case 0:
+				tm = tm.UTC()
+			case 1:
+				tm = tm.Local()
+			case 2:
+				var bs = make([]byte, 12)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint32(bs)
+				offset := int32(ux >> 1)
+				if ux&1 != 0 {
+					offset = ^offset
+				}
+
+				ux2 := binary.LittleEndian.Uint64(bs[4:])
+				x := int64(ux2 >> 1)
+				if ux2&1 != 0 {
+					x = ^x
+				}
+
+				if x < 0 || x > math.MaxInt32 {
+					return fmt.Errorf("invalid time zone name length %d", x)
+				}
+
+				// The length is not trusted to allocate the bytes to read.
+				b, err := io.ReadAll(io.LimitReader(reader, x))
+				if err != nil {
+					return err
+				}
+				if int64(len(b)) < x {
+					return io.ErrUnexpectedEOF
+				}
+
+				name := string(b)
+				loc, err := time.LoadLocation(name)
+				if err != nil {
+					loc = time.FixedZone(name, int(offset))
+				} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+					loc = time.FixedZone(name, int(offset))
+				}
+				tm = tm.In(loc)
+			default:
+				return fmt.Errorf("invalid time zone kind: %d", bs[12])
+			}
+
+			t.Time = time.Time(tm)
+
+			if !(t.Time).After(time.Unix(1546300800, 0).UTC()) {
+				return fmt.Errorf("field '%v' should be after %v", "Time", time.Unix(1546300800, 0).UTC())
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t NotZeroTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t NotZeroTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.Time
+			sec := x.Unix()
+			ux := uint64(sec) << 1
+			if sec < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 13)
+			binary.LittleEndian.PutUint64(bs, ux)
+			binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+			switch x.Location() {
+			case time.UTC:
+				bs[12] = 0
+			case time.Local:
+				bs[12] = 1
+			default:
+				bs[12] = 2
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+
+			if bs[12] == 2 {
+				_, offset := x.Zone()
+				name := x.Location().String()
+				uoffset := uint32(int32(offset)) << 1
+				if offset < 0 {
+					uoffset = ^uoffset
+				}
+				bs := make([]byte, 12)
+				binary.LittleEndian.PutUint32(bs, uoffset)
+				binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+
+				if _, err := writer.Write([]byte(name)); err != nil {
+					return err
+				}
+			}
+		}
+
+		{
+			x := t.Duration
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *NotZeroTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *NotZeroTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 13)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			sec := int64(ux >> 1)
+			if ux&1 != 0 {
+				sec = ^sec
+			}
+			nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+			tm := time.Unix(sec, nsec)
+			switch bs[12] {
+			case 0:
+				tm = tm.UTC()
+			case 1:
+				tm = tm.Local()
+			case 2:
+				var bs = make([]byte, 12)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint32(bs)
+				offset := int32(ux >> 1)
+				if ux&1 != 0 {
+					offset = ^offset
+				}
+
+				ux2 := binary.LittleEndian.Uint64(bs[4:])
+				x := int64(ux2 >> 1)
+				if ux2&1 != 0 {
+					x = ^x
+				}
+
+				if x < 0 || x > math.MaxInt32 {
+					return fmt.Errorf("invalid time zone name length %d", x)
+				}
+
+				// The length is not trusted to allocate the bytes to read.
+				b, err := io.ReadAll(io.LimitReader(reader, x))
+				if err != nil {
+					return err
+				}
+				if int64(len(b)) < x {
+					return io.ErrUnexpectedEOF
+				}
+
+				name := string(b)
+				loc, err := time.LoadLocation(name)
+				if err != nil {
+					loc = time.FixedZone(name, int(offset))
+				} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+					loc = time.FixedZone(name, int(offset))
+				}
+				tm = tm.In(loc)
+			default:
+				return fmt.Errorf("invalid time zone kind: %d", bs[12])
+			}
+
+			t.Time = time.Time(tm)
+
+			if (t.Time).IsZero() {
+				return fmt.Errorf("field '%v' should not be zero", "Time")
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Duration = time.Duration(x)
+
+			if t.Duration == 0 {
+				return fmt.Errorf("field '%v' should not be zero", "Duration")
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t DurationTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t DurationTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.Duration
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *DurationTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *DurationTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Duration = time.Duration(x)
+
+			if t.Duration > time.Duration(3600000000000) {
+				return fmt.Errorf("field '%v' has a maximum value of %v", "Duration", time.Duration(3600000000000))
+			}
+			if t.Duration < time.Duration(1000000000) {
+				return fmt.Errorf("field '%v' has a minimum value of %v", "Duration", time.Duration(1000000000))
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t DurationNanosTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t DurationNanosTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.Duration
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			x := t.OneOf
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *DurationNanosTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *DurationNanosTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Duration = time.Duration(x)
+
+			if t.Duration > time.Duration(1000000) {
+				return fmt.Errorf("field '%v' has a maximum value of %v", "Duration", time.Duration(1000000))
+			}
+			if t.Duration < time.Duration(1000) {
+				return fmt.Errorf("field '%v' has a minimum value of %v", "Duration", time.Duration(1000))
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.OneOf = time.Duration(x)
+
+			if t.OneOf != 1000 && t.OneOf != 2000 {
+				return fmt.Errorf("field 'OneOf' should have one of these values: %s", "1000, 2000")
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t PointerConstraintTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t PointerConstraintTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			if x := t.String; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := (*t.String)
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.Int; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					x := (*t.Int)
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.Time; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					x := (*t.Time)
+					sec := x.Unix()
+					ux := uint64(sec) << 1
+					if sec < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 13)
+					binary.LittleEndian.PutUint64(bs, ux)
+					binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+					switch x.Location() {
+					case time.UTC:
+						bs[12] = 0
+					case time.Local:
+						bs[12] = 1
+					default:
+						bs[12] = 2
+					}
+
+					if _, err := writer.Write(bs); err != nil {
+						return err
+					}
+
+					if bs[12] == 2 {
+						_, offset := x.Zone()
+						name := x.Location().String()
+						uoffset := uint32(int32(offset)) << 1
+						if offset < 0 {
+							uoffset = ^uoffset
+						}
+						bs := make([]byte, 12)
+						binary.LittleEndian.PutUint32(bs, uoffset)
+						binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+						if _, err := writer.Write(bs); err != nil {
+							return err
+						}
+
+						if _, err := writer.Write([]byte(name)); err != nil {
+							return err
+						}
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.Slice; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					n := len((*t.Slice))
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+
+					for i := 0; i < n; i++ {
+						x := (*t.Slice)[i]
+						ux := uint64(x) << 1
+						if x < 0 {
+							ux = ^ux
+						}
+						bs := make([]byte, 8)
+						binary.LittleEndian.PutUint64(bs, ux)
+						_, err := writer.Write(bs)
+						if err != nil {
+							return err
+						}
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.Nil; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := (*t.Nil)
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *PointerConstraintTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *PointerConstraintTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.String = nil
+			} else {
+				var tmp_t_String string
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+					if sz > 5 {
+						return fmt.Errorf("field '%v' has a maximum length of %v", "String", 5)
+					}
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_String = string(b)
+
+				}
+
+				t.String = &tmp_t_String
+				for _, ru := range *t.String {
+					if !unicode.IsLetter(ru) {
+						return fmt.Errorf("field '%v' contains non alpha characters", "String")
+					}
+				}
+
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Int = nil
+			} else {
+				var tmp_t_Int int
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					tmp_t_Int = int(x)
+
+				}
+
+				t.Int = &tmp_t_Int
+				if *t.Int < 1 {
+					return fmt.Errorf("field '%v' has a minimum value of %v", "Int", 1)
+				}
+
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Time = nil
+			} else {
+				var tmp_t_Time time.Time
+
+				{
+					var bs = make([]byte, 13)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					sec := int64(ux >> 1)
+					if ux&1 != 0 {
+						sec = ^sec
+					}
+					nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+					tm := time.Unix(sec, nsec)
+					switch bs[12] {
+					case 0:
+						tm = tm.UTC()
+					case 1:
+						tm = tm.Local()
+					case 2:
+						var bs = make([]byte, 12)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint32(bs)
+						offset := int32(ux >> 1)
+						if ux&1 != 0 {
+							offset = ^offset
+						}
+
+						ux2 := binary.LittleEndian.Uint64(bs[4:])
+						x := int64(ux2 >> 1)
+						if ux2&1 != 0 {
+							x = ^x
+						}
+
+						if x < 0 || x > math.MaxInt32 {
+							return fmt.Errorf("invalid time zone name length %d", x)
+						}
+
+						// The length is not trusted to allocate the bytes to read.
+						b, err := io.ReadAll(io.LimitReader(reader, x))
+						if err != nil {
+							return err
+						}
+						if int64(len(b)) < x {
+							return io.ErrUnexpectedEOF
+						}
+
+						name := string(b)
+						loc, err := time.LoadLocation(name)
+						if err != nil {
+							loc = time.FixedZone(name, int(offset))
+						} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+							loc = time.FixedZone(name, int(offset))
+						}
+						tm = tm.In(loc)
+					default:
+						return fmt.Errorf("invalid time zone kind: %d", bs[12])
+					}
+
+					tmp_t_Time = time.Time(tm)
+
+				}
+
+				t.Time = &tmp_t_Time
+				if !(*t.Time).After(time.Unix(1546300800, 0).UTC()) {
+					return fmt.Errorf("field '%v' should be after %v", "Time", time.Unix(1546300800, 0).UTC())
+				}
+
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Slice = nil
+			} else {
+				var tmp_t_Slice []int
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					if sz > 2 {
+						return fmt.Errorf("field '%v' has a maximum length of %v", "Slice", 2)
+					}
+
+					tmp_t_Slice = make([]int, sz)
+
+					for i := 0; i < sz; i++ {
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+						(tmp_t_Slice)[i] = int(x)
+
+					}
+
+				}
+
+				t.Slice = &tmp_t_Slice
+
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Nil = nil
+			} else {
+				var tmp_t_Nil string
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+					if sz < 1 {
+						return fmt.Errorf("field '%v' has a minimum length of %v", "Nil", 1)
+					}
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_Nil = string(b)
+
+				}
+
+				t.Nil = &tmp_t_Nil
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t TrailingMaybeTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t TrailingMaybeTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.ID
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			if x := t.Name; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := (*t.Name)
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.Tags; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					n := len((*t.Tags))
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+
+					for i := 0; i < n; i++ {
+						v := (*t.Tags)[i]
+						n := len(v)
+						ux := uint64(n) << 1
+						if n < 0 {
+							ux = ^ux
+						}
+						sz := make([]byte, 8)
+						binary.LittleEndian.PutUint64(sz, ux)
+						if _, err := writer.Write(sz); err != nil {
+							return err
+						}
+
+						_, err := writer.Write([]byte(v))
+						if err != nil {
+							return err
+						}
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *TrailingMaybeTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *TrailingMaybeTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.ID = int64(x)
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Name = nil
+			} else {
+				var tmp_t_Name string
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_Name = string(b)
+
+				}
+
+				t.Name = &tmp_t_Name
+
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Tags = nil
+			} else {
+				var tmp_t_Tags []string
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					tmp_t_Tags = make([]string, sz)
+
+					for i := 0; i < sz; i++ {
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+
+						sz := int(x)
+
+						b := make([]byte, sz)
+						if _, err := io.ReadFull(reader, b); err != nil {
+							return err
+						}
+
+						(tmp_t_Tags)[i] = string(b)
+
+					}
+
+				}
+
+				t.Tags = &tmp_t_Tags
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t DelegateTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t DelegateTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			v, err := t.URL.MarshalBinary()
+			if err != nil {
+				return err
+			}
+
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			if _, err := writer.Write(v); err != nil {
+				return err
+			}
+		}
+
+		{
+			if x := t.Int; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v, err := (*t.Int).GobEncode()
+					if err != nil {
+						return err
+					}
+
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					if _, err := writer.Write(v); err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			if err := t.Foo.WriteBinary(writer); err != nil {
+				return err
+			}
+		}
+
+		{
+			v, err := t.Marshaler.MarshalBinary()
+			if err != nil {
+				return err
+			}
+
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			if _, err := writer.Write(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *DelegateTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *DelegateTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			if x < 0 || x > math.MaxInt32 {
+				return fmt.Errorf("invalid length %d", x)
+			}
+
+			sz := int(x)
+
+			// The length is not trusted to allocate the bytes to read.
+			b, err := io.ReadAll(io.LimitReader(reader, x))
+			if err != nil {
+				return err
+			}
+			if len(b) < sz {
+				return io.ErrUnexpectedEOF
+			}
+
+			if err := (t.URL).UnmarshalBinary(b); err != nil {
+				return err
+			}
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Int = nil
+			} else {
+				var tmp_t_Int big.Int
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					if x < 0 || x > math.MaxInt32 {
+						return fmt.Errorf("invalid length %d", x)
+					}
+
+					sz := int(x)
+
+					// The length is not trusted to allocate the bytes to read.
+					b, err := io.ReadAll(io.LimitReader(reader, x))
+					if err != nil {
+						return err
+					}
+					if len(b) < sz {
+						return io.ErrUnexpectedEOF
+					}
+
+					if err := (tmp_t_Int).GobDecode(b); err != nil {
+						return err
+					}
+
+				}
+
+				t.Int = &tmp_t_Int
+
+			}
+		}
+
+		{
+			if err := (t.Foo).DecodeBinary(reader); err != nil {
+				return err
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			if x < 0 || x > math.MaxInt32 {
+				return fmt.Errorf("invalid length %d", x)
+			}
+
+			sz := int(x)
+
+			// The length is not trusted to allocate the bytes to read.
+			b, err := io.ReadAll(io.LimitReader(reader, x))
+			if err != nil {
+				return err
+			}
+			if len(b) < sz {
+				return io.ErrUnexpectedEOF
+			}
+
+			if err := (t.Marshaler).UnmarshalBinary(b); err != nil {
+				return err
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t StructCyclic) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t StructCyclic) WriteBinary(writer io.Writer) error {
+
+	{
+		if err := encodeStructCyclic(writer, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *StructCyclic) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *StructCyclic) DecodeBinary(reader io.Reader) error {
+	var depth int
+
+	{
+		if err := decodeStructCyclic(reader, t, depth+1); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+// encodeStructCyclic writes the binary-encoded representation of the recursive
+// type StructCyclic to the given writer.
+func encodeStructCyclic(writer io.Writer, t StructCyclic) error {
+	{
+
+		{
+			x := t.Value
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			if x := t.Cycle; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					if err := encodeStructCyclic(writer, (*t.Cycle)); err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeStructCyclic reads the binary representation of the recursive type
+// StructCyclic from the given reader at the given depth.
+func decodeStructCyclic(reader io.Reader, t *StructCyclic, depth int) error {
+	if depth > 10000 {
+		return fmt.Errorf("maximum decoding depth of %d exceeded", 10000)
+	}
+
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Value = int(x)
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Cycle = nil
+			} else {
+				var tmp_t_Cycle StructCyclic
+
+				{
+					if err := decodeStructCyclic(reader, &tmp_t_Cycle, depth+1); err != nil {
+						return err
+					}
+
+				}
+
+				t.Cycle = &tmp_t_Cycle
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t TreeTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t TreeTestType) WriteBinary(writer io.Writer) error {
+
+	{
+		if err := encodeTreeTestType(writer, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *TreeTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *TreeTestType) DecodeBinary(reader io.Reader) error {
+	var depth int
+
+	{
+		if err := decodeTreeTestType(reader, t, depth+1); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+// encodeTreeTestType writes the binary-encoded representation of the recursive
+// type TreeTestType to the given writer.
+func encodeTreeTestType(writer io.Writer, t TreeTestType) error {
+	{
+
+		{
+			x := t.Value
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			n := len(t.Children)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				if err := encodeTreeTestType(writer, t.Children[i]); err != nil {
+					return err
+				}
+			}
+		}
+
+		{
+			n := len(t.Index)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for k, v := range t.Index {
+
+				{
+					v := k
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					if x := v; x == nil {
+						if _, err := writer.Write([]byte{0}); err != nil {
+							return err
+						}
+					} else {
+						if _, err := writer.Write([]byte{1}); err != nil {
+							return err
+						}
+
+						{
+							if err := encodeTreeTestType(writer, (*v)); err != nil {
+								return err
+							}
+						}
+
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeTreeTestType reads the binary representation of the recursive type
+// TreeTestType from the given reader at the given depth.
+func decodeTreeTestType(reader io.Reader, t *TreeTestType, depth int) error {
+	if depth > 10000 {
+		return fmt.Errorf("maximum decoding depth of %d exceeded", 10000)
+	}
+
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Value = int(x)
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Children = make([]TreeTestType, sz)
+
+			for i := 0; i < sz; i++ {
+				if err := decodeTreeTestType(reader, &(t.Children)[i], depth+1); err != nil {
+					return err
+				}
+
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Index = make(map[string]*TreeTestType, sz)
+
+			for i := 0; i < sz; i++ {
+				var key string
+				var value *TreeTestType
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					key = string(b)
+
+				}
+
+				{
+					var v = make([]byte, 1)
+					if _, err := io.ReadFull(reader, v); err != nil {
+						return err
+					}
+
+					if v[0] == 0 {
+						value = nil
+					} else {
+						var tmp_value TreeTestType
+
+						{
+							if err := decodeTreeTestType(reader, &tmp_value, depth+1); err != nil {
+								return err
+							}
+
+						}
+
+						value = &tmp_value
+
+					}
+				}
+
+				(t.Index)[key] = value
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t MutualATestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t MutualATestType) WriteBinary(writer io.Writer) error {
+
+	{
+		if err := encodeMutualATestType(writer, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *MutualATestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *MutualATestType) DecodeBinary(reader io.Reader) error {
+	var depth int
+
+	{
+		if err := decodeMutualATestType(reader, t, depth+1); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+// encodeMutualATestType writes the binary-encoded representation of the recursive
+// type MutualATestType to the given writer.
+func encodeMutualATestType(writer io.Writer, t MutualATestType) error {
+	{
+
+		{
+			v := t.Name
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			_, err := writer.Write([]byte(v))
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			if x := t.B; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+
+					{
+						n := len((*t.B).As)
+						ux := uint64(n) << 1
+						if n < 0 {
+							ux = ^ux
+						}
+						bs := make([]byte, 8)
+						binary.LittleEndian.PutUint64(bs, ux)
+						_, err := writer.Write(bs)
+						if err != nil {
+							return err
+						}
+
+						for i := 0; i < n; i++ {
+							if err := encodeMutualATestType(writer, (*t.B).As[i]); err != nil {
+								return err
+							}
+						}
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeMutualATestType reads the binary representation of the recursive type
+// MutualATestType from the given reader at the given depth.
+func decodeMutualATestType(reader io.Reader, t *MutualATestType, depth int) error {
+	if depth > 10000 {
+		return fmt.Errorf("maximum decoding depth of %d exceeded", 10000)
+	}
+
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			b := make([]byte, sz)
+			if _, err := io.ReadFull(reader, b); err != nil {
+				return err
+			}
+
+			t.Name = string(b)
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.B = nil
+			} else {
+				var tmp_t_B MutualBTestType
+				{
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+
+						sz := int(x)
+
+						tmp_t_B.As = make([]MutualATestType, sz)
+
+						for i := 0; i < sz; i++ {
+							if err := decodeMutualATestType(reader, &(tmp_t_B.As)[i], depth+1); err != nil {
+								return err
+							}
+
+						}
+
+					}
+				}
+
+				t.B = &tmp_t_B
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t UnionTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t UnionTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			switch u := t.Event.(type) {
+			case nil:
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+
+			case CreatedTestType:
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+
+					{
+						x := u.ID
+						ux := uint64(x) << 1
+						if x < 0 {
+							ux = ^ux
+						}
+						bs := make([]byte, 8)
+						binary.LittleEndian.PutUint64(bs, ux)
+						_, err := writer.Write(bs)
+						if err != nil {
+							return err
+						}
+					}
+				}
+
+			case *DeletedTestType:
+				if _, err := writer.Write([]byte{2}); err != nil {
+					return err
+				}
+
+				{
+					if x := u; x == nil {
+						if _, err := writer.Write([]byte{0}); err != nil {
+							return err
+						}
+					} else {
+						if _, err := writer.Write([]byte{1}); err != nil {
+							return err
+						}
+
+						{
+
+							{
+								x := (*u).ID
+								ux := uint64(x) << 1
+								if x < 0 {
+									ux = ^ux
+								}
+								bs := make([]byte, 8)
+								binary.LittleEndian.PutUint64(bs, ux)
+								_, err := writer.Write(bs)
+								if err != nil {
+									return err
+								}
+							}
+
+							{
+								v := (*u).Reason
+								n := len(v)
+								ux := uint64(n) << 1
+								if n < 0 {
+									ux = ^ux
+								}
+								sz := make([]byte, 8)
+								binary.LittleEndian.PutUint64(sz, ux)
+								if _, err := writer.Write(sz); err != nil {
+									return err
+								}
+
+								_, err := writer.Write([]byte(v))
+								if err != nil {
+									return err
+								}
+							}
+						}
+
+					}
+				}
+
+			default:
+				return fmt.Errorf("type %T is not part of the union", u)
+			}
+		}
+
+		{
+			n := len(t.Events)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				switch u := t.Events[i].(type) {
+				case nil:
+					if _, err := writer.Write([]byte{0}); err != nil {
+						return err
+					}
+
+				case CreatedTestType:
+					if _, err := writer.Write([]byte{1}); err != nil {
+						return err
+					}
+
+					{
+
+						{
+							x := u.ID
+							ux := uint64(x) << 1
+							if x < 0 {
+								ux = ^ux
+							}
+							bs := make([]byte, 8)
+							binary.LittleEndian.PutUint64(bs, ux)
+							_, err := writer.Write(bs)
+							if err != nil {
+								return err
+							}
+						}
+					}
+
+				case *DeletedTestType:
+					if _, err := writer.Write([]byte{2}); err != nil {
+						return err
+					}
+
+					{
+						if x := u; x == nil {
+							if _, err := writer.Write([]byte{0}); err != nil {
+								return err
+							}
+						} else {
+							if _, err := writer.Write([]byte{1}); err != nil {
+								return err
+							}
+
+							{
+
+								{
+									x := (*u).ID
+									ux := uint64(x) << 1
+									if x < 0 {
+										ux = ^ux
+									}
+									bs := make([]byte, 8)
+									binary.LittleEndian.PutUint64(bs, ux)
+									_, err := writer.Write(bs)
+									if err != nil {
+										return err
+									}
+								}
+
+								{
+									v := (*u).Reason
+									n := len(v)
+									ux := uint64(n) << 1
+									if n < 0 {
+										ux = ^ux
+									}
+									sz := make([]byte, 8)
+									binary.LittleEndian.PutUint64(sz, ux)
+									if _, err := writer.Write(sz); err != nil {
+										return err
+									}
+
+									_, err := writer.Write([]byte(v))
+									if err != nil {
+										return err
+									}
+								}
+							}
+
+						}
+					}
+
+				default:
+					return fmt.Errorf("type %T is not part of the union", u)
+				}
+			}
+		}
+
+		{
+			switch u := t.Value.(type) {
+			case nil:
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+
+			case StringTestType:
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := u
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			case IntTestType:
+				if _, err := writer.Write([]byte{2}); err != nil {
+					return err
+				}
+
+				{
+					x := u
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+
+			default:
+				return fmt.Errorf("type %T is not part of the union", u)
+			}
+		}
+
+		{
+			if err := encodeUnionTestType_ExprTestType(writer, t.Expr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *UnionTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *UnionTestType) DecodeBinary(reader io.Reader) error {
+	var depth int
+	{
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.Event = nil
+
+			case 1:
+				var tmp_t_Event CreatedTestType
+				{
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+						tmp_t_Event.ID = int(x)
+
+					}
+				}
+
+				t.Event = tmp_t_Event
+
+			case 2:
+				var tmp_t_Event *DeletedTestType
+
+				{
+					var v = make([]byte, 1)
+					if _, err := io.ReadFull(reader, v); err != nil {
+						return err
+					}
+
+					if v[0] == 0 {
+						tmp_t_Event = nil
+					} else {
+						var tmp_tmp_t_Event DeletedTestType
+						{
+
+							{
+								var bs = make([]byte, 8)
+								if _, err := io.ReadFull(reader, bs); err != nil {
+									return err
+								}
+
+								ux := binary.LittleEndian.Uint64(bs)
+								x := int64(ux >> 1)
+								if ux&1 != 0 {
+									x = ^x
+								}
+								tmp_tmp_t_Event.ID = int(x)
+
+							}
+
+							{
+								var bs = make([]byte, 8)
+								if _, err := io.ReadFull(reader, bs); err != nil {
+									return err
+								}
+
+								ux := binary.LittleEndian.Uint64(bs)
+								x := int64(ux >> 1)
+								if ux&1 != 0 {
+									x = ^x
+								}
+
+								sz := int(x)
+
+								b := make([]byte, sz)
+								if _, err := io.ReadFull(reader, b); err != nil {
+									return err
+								}
+
+								tmp_tmp_t_Event.Reason = string(b)
+
+							}
+						}
+
+						tmp_t_Event = &tmp_tmp_t_Event
+
+					}
+				}
+
+				t.Event = tmp_t_Event
+
+			default:
+				return fmt.Errorf("invalid type for union EventTestType: %d", v[0])
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Events = make([]EventTestType, sz)
+
+			for i := 0; i < sz; i++ {
+				var v = make([]byte, 1)
+				if _, err := io.ReadFull(reader, v); err != nil {
+					return err
+				}
+
+				switch v[0] {
+				case 0:
+					(t.Events)[i] = nil
+
+				case 1:
+					var tmp__t_Events__i_ CreatedTestType
+					{
+
+						{
+							var bs = make([]byte, 8)
+							if _, err := io.ReadFull(reader, bs); err != nil {
+								return err
+							}
+
+							ux := binary.LittleEndian.Uint64(bs)
+							x := int64(ux >> 1)
+							if ux&1 != 0 {
+								x = ^x
+							}
+							tmp__t_Events__i_.ID = int(x)
+
+						}
+					}
+
+					(t.Events)[i] = tmp__t_Events__i_
+
+				case 2:
+					var tmp__t_Events__i_ *DeletedTestType
+
+					{
+						var v = make([]byte, 1)
+						if _, err := io.ReadFull(reader, v); err != nil {
+							return err
+						}
+
+						if v[0] == 0 {
+							tmp__t_Events__i_ = nil
+						} else {
+							var tmp_tmp__t_Events__i_ DeletedTestType
+							{
+
+								{
+									var bs = make([]byte, 8)
+									if _, err := io.ReadFull(reader, bs); err != nil {
+										return err
+									}
+
+									ux := binary.LittleEndian.Uint64(bs)
+									x := int64(ux >> 1)
+									if ux&1 != 0 {
+										x = ^x
+									}
+									tmp_tmp__t_Events__i_.ID = int(x)
+
+								}
+
+								{
+									var bs = make([]byte, 8)
+									if _, err := io.ReadFull(reader, bs); err != nil {
+										return err
+									}
+
+									ux := binary.LittleEndian.Uint64(bs)
+									x := int64(ux >> 1)
+									if ux&1 != 0 {
+										x = ^x
+									}
+
+									sz := int(x)
+
+									b := make([]byte, sz)
+									if _, err := io.ReadFull(reader, b); err != nil {
+										return err
+									}
+
+									tmp_tmp__t_Events__i_.Reason = string(b)
+
+								}
+							}
+
+							tmp__t_Events__i_ = &tmp_tmp__t_Events__i_
+
+						}
+					}
+
+					(t.Events)[i] = tmp__t_Events__i_
+
+				default:
+					return fmt.Errorf("invalid type for union EventTestType: %d", v[0])
+				}
+
+			}
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.Value = nil
+
+			case 1:
+				var tmp_t_Value StringTestType
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_Value = StringTestType(b)
+
+				}
+
+				t.Value = tmp_t_Value
+
+			case 2:
+				var tmp_t_Value IntTestType
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					tmp_t_Value = IntTestType(x)
+
+				}
+
+				t.Value = tmp_t_Value
+
+			default:
+				return fmt.Errorf("invalid type for union interface{}: %d", v[0])
+			}
+
+		}
+
+		{
+			if err := decodeUnionTestType_ExprTestType(reader, &t.Expr, depth+1); err != nil {
+				return err
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// encodeUnionTestType_ExprTestType writes the binary-encoded representation of the recursive
+// type ExprTestType to the given writer.
+func encodeUnionTestType_ExprTestType(writer io.Writer, t ExprTestType) error {
+
+	{
+		switch u := t.(type) {
+		case nil:
+			if _, err := writer.Write([]byte{0}); err != nil {
+				return err
+			}
+
+		case LiteralTestType:
+			if _, err := writer.Write([]byte{1}); err != nil {
+				return err
+			}
+
+			{
+				x := u
+				ux := uint64(x) << 1
+				if x < 0 {
+					ux = ^ux
+				}
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint64(bs, ux)
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+			}
+
+		case BinaryTestType:
+			if _, err := writer.Write([]byte{2}); err != nil {
+				return err
+			}
+
+			{
+
+				{
+					v := u.Op
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					if err := encodeUnionTestType_ExprTestType(writer, u.Left); err != nil {
+						return err
+					}
+				}
+
+				{
+					if err := encodeUnionTestType_ExprTestType(writer, u.Right); err != nil {
+						return err
+					}
+				}
+			}
+
+		default:
+			return fmt.Errorf("type %T is not part of the union", u)
+		}
+	}
+
+	return nil
+}
+
+// decodeUnionTestType_ExprTestType reads the binary representation of the recursive type
+// ExprTestType from the given reader at the given depth.
+func decodeUnionTestType_ExprTestType(reader io.Reader, t *ExprTestType, depth int) error {
+	if depth > 10000 {
+		return fmt.Errorf("maximum decoding depth of %d exceeded", 10000)
+	}
+
+	{
+		var v = make([]byte, 1)
+		if _, err := io.ReadFull(reader, v); err != nil {
+			return err
+		}
+
+		switch v[0] {
+		case 0:
+			*t = nil
+
+		case 1:
+			var tmp_t LiteralTestType
+
+			{
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint64(bs)
+				x := int64(ux >> 1)
+				if ux&1 != 0 {
+					x = ^x
+				}
+				tmp_t = LiteralTestType(x)
+
+			}
+
+			*t = tmp_t
+
+		case 2:
+			var tmp_t BinaryTestType
+			{
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t.Op = string(b)
+
+				}
+
+				{
+					if err := decodeUnionTestType_ExprTestType(reader, &tmp_t.Left, depth+1); err != nil {
+						return err
+					}
+
+				}
+
+				{
+					if err := decodeUnionTestType_ExprTestType(reader, &tmp_t.Right, depth+1); err != nil {
+						return err
+					}
+
+				}
+			}
+
+			*t = tmp_t
+
+		default:
+			return fmt.Errorf("invalid type for union ExprTestType: %d", v[0])
+		}
+
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t PairTestType[K, V]) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t PairTestType[K, V]) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			switch v := any(t.Key).(type) {
+			case int:
+
+				{
+					x := v
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+			case string:
+
+				{
+					v := v
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			default:
+				return fmt.Errorf("type %T of type parameter K can not be encoded", v)
+			}
+		}
+
+		{
+			switch v := any(t.Value).(type) {
+			case int:
+
+				{
+					x := v
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+			case string:
+
+				{
+					v := v
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			default:
+				return fmt.Errorf("type %T of type parameter V can not be encoded", v)
+			}
+		}
+
+		{
+			n := len(t.Extra)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				switch v := any(t.Extra[i]).(type) {
+				case int:
+
+					{
+						x := v
+						ux := uint64(x) << 1
+						if x < 0 {
+							ux = ^ux
+						}
+						bs := make([]byte, 8)
+						binary.LittleEndian.PutUint64(bs, ux)
+						_, err := writer.Write(bs)
+						if err != nil {
+							return err
+						}
+					}
+				case string:
+
+					{
+						v := v
+						n := len(v)
+						ux := uint64(n) << 1
+						if n < 0 {
+							ux = ^ux
+						}
+						sz := make([]byte, 8)
+						binary.LittleEndian.PutUint64(sz, ux)
+						if _, err := writer.Write(sz); err != nil {
+							return err
+						}
+
+						_, err := writer.Write([]byte(v))
+						if err != nil {
+							return err
+						}
+					}
+
+				default:
+					return fmt.Errorf("type %T of type parameter V can not be encoded", v)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *PairTestType[K, V]) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *PairTestType[K, V]) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			switch p := any(&t.Key).(type) {
+			case *int:
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					*p = int(x)
+
+				}
+			case *string:
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					*p = string(b)
+
+				}
+
+			default:
+				return fmt.Errorf("type %T of type parameter K can not be decoded", t.Key)
+			}
+
+		}
+
+		{
+			switch p := any(&t.Value).(type) {
+			case *int:
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					*p = int(x)
+
+				}
+			case *string:
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					*p = string(b)
+
+				}
+
+			default:
+				return fmt.Errorf("type %T of type parameter V can not be decoded", t.Value)
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Extra = make([]V, sz)
+
+			for i := 0; i < sz; i++ {
+				switch p := any(&(t.Extra)[i]).(type) {
+				case *int:
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+						*p = int(x)
+
+					}
+				case *string:
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+
+						sz := int(x)
+
+						b := make([]byte, sz)
+						if _, err := io.ReadFull(reader, b); err != nil {
+							return err
+						}
+
+						*p = string(b)
+
+					}
+
+				default:
+					return fmt.Errorf("type %T of type parameter V can not be decoded", (t.Extra)[i])
+				}
+
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t PageTestType[T]) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t PageTestType[T]) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			n := len(t.Items)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				if err := t.Items[i].WriteBinary(writer); err != nil {
+					return err
+				}
+			}
+		}
+
+		{
+			x := t.Next
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *PageTestType[T]) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *PageTestType[T]) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Items = make([]T, sz)
+
+			for i := 0; i < sz; i++ {
+				d, ok := any(&(t.Items)[i]).(interface{ DecodeBinary(io.Reader) error })
+				if !ok {
+					return fmt.Errorf("type %T of type parameter T can not be decoded", (t.Items)[i])
+				}
+
+				if err := d.DecodeBinary(reader); err != nil {
+					return err
+				}
+
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Next = int(x)
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t ListTestType[T]) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t ListTestType[T]) WriteBinary(writer io.Writer) error {
+
+	{
+		if err := encodeListTestType[T](writer, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *ListTestType[T]) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *ListTestType[T]) DecodeBinary(reader io.Reader) error {
+	var depth int
+
+	{
+		if err := decodeListTestType[T](reader, t, depth+1); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+// encodeListTestType writes the binary-encoded representation of the recursive
+// type ListTestType[T] to the given writer.
+func encodeListTestType[T int | string](writer io.Writer, t ListTestType[T]) error {
+	{
+
+		{
+			switch v := any(t.Value).(type) {
+			case int:
+
+				{
+					x := v
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+			case string:
+
+				{
+					v := v
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			default:
+				return fmt.Errorf("type %T of type parameter T can not be encoded", v)
+			}
+		}
+
+		{
+			if x := t.Next; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					if err := encodeListTestType[T](writer, (*t.Next)); err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeListTestType reads the binary representation of the recursive type
+// ListTestType[T] from the given reader at the given depth.
+func decodeListTestType[T int | string](reader io.Reader, t *ListTestType[T], depth int) error {
+	if depth > 10000 {
+		return fmt.Errorf("maximum decoding depth of %d exceeded", 10000)
+	}
+
+	{
+
+		{
+			switch p := any(&t.Value).(type) {
+			case *int:
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					*p = int(x)
+
+				}
+			case *string:
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					*p = string(b)
+
+				}
+
+			default:
+				return fmt.Errorf("type %T of type parameter T can not be decoded", t.Value)
+			}
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Next = nil
+			} else {
+				var tmp_t_Next ListTestType[T]
+
+				{
+					if err := decodeListTestType[T](reader, &tmp_t_Next, depth+1); err != nil {
+						return err
+					}
+
+				}
+
+				t.Next = &tmp_t_Next
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t ComplexTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t ComplexTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			c := complex64(t.C64)
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint32(bs, math.Float32bits(real(c)))
+			binary.LittleEndian.PutUint32(bs[4:], math.Float32bits(imag(c)))
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			c := complex128(t.C128)
+			bs := make([]byte, 16)
+			binary.LittleEndian.PutUint64(bs, math.Float64bits(real(c)))
+			binary.LittleEndian.PutUint64(bs[8:], math.Float64bits(imag(c)))
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			v := t.Samples
+			n := len(v)
+			bs := make([]byte, 8+n*8)
+			binary.LittleEndian.PutUint64(bs, uint64(n)<<1)
+			for i, c := range v {
+				binary.LittleEndian.PutUint32(bs[8+i*8:], math.Float32bits(real(c)))
+				binary.LittleEndian.PutUint32(bs[12+i*8:], math.Float32bits(imag(c)))
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+		}
+
+		{
+			v := t.Wide
+			n := len(v)
+			bs := make([]byte, 8+n*16)
+			binary.LittleEndian.PutUint64(bs, uint64(n)<<1)
+			for i, c := range v {
+				binary.LittleEndian.PutUint64(bs[8+i*16:], math.Float64bits(real(c)))
+				binary.LittleEndian.PutUint64(bs[16+i*16:], math.Float64bits(imag(c)))
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+		}
+
+		{
+			v := t.Named
+			n := len(v)
+			bs := make([]byte, 8+n*8)
+			binary.LittleEndian.PutUint64(bs, uint64(n)<<1)
+			for i, c := range v {
+				binary.LittleEndian.PutUint32(bs[8+i*8:], math.Float32bits(real(c)))
+				binary.LittleEndian.PutUint32(bs[12+i*8:], math.Float32bits(imag(c)))
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+		}
+
+		{
+			if x := t.Optional; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					c := complex128((*t.Optional))
+					bs := make([]byte, 16)
+					binary.LittleEndian.PutUint64(bs, math.Float64bits(real(c)))
+					binary.LittleEndian.PutUint64(bs[8:], math.Float64bits(imag(c)))
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			for i := 0; i < 2; i++ {
+				c := complex64(t.Fixed[i])
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint32(bs, math.Float32bits(real(c)))
+				binary.LittleEndian.PutUint32(bs[4:], math.Float32bits(imag(c)))
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		{
+			v := t.Bounded
+			n := len(v)
+			bs := make([]byte, 8+n*8)
+			binary.LittleEndian.PutUint64(bs, uint64(n)<<1)
+			for i, c := range v {
+				binary.LittleEndian.PutUint32(bs[8+i*8:], math.Float32bits(real(c)))
+				binary.LittleEndian.PutUint32(bs[12+i*8:], math.Float32bits(imag(c)))
+			}
+
+			if _, err := writer.Write(bs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *ComplexTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *ComplexTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+			re := math.Float32frombits(binary.LittleEndian.Uint32(bs))
+			im := math.Float32frombits(binary.LittleEndian.Uint32(bs[4:]))
+			t.C64 = complex64(complex(re, im))
+
+		}
+
+		{
+			var bs = make([]byte, 16)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+			re := math.Float64frombits(binary.LittleEndian.Uint64(bs))
+			im := math.Float64frombits(binary.LittleEndian.Uint64(bs[8:]))
+			t.C128 = complex128(complex(re, im))
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+			if sz < 0 || sz > math.MaxInt32 {
+				return fmt.Errorf("invalid slice length %d", sz)
+			}
+
+			data := make([]byte, sz*8)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return err
+			}
+
+			t.Samples = make([]complex64, sz)
+			for i := 0; i < sz; i++ {
+				re := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8:]))
+				im := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8+4:]))
+				(t.Samples)[i] = complex64(complex(re, im))
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+			if sz < 0 || sz > math.MaxInt32 {
+				return fmt.Errorf("invalid slice length %d", sz)
+			}
+
+			data := make([]byte, sz*16)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return err
+			}
+
+			t.Wide = make([]complex128, sz)
+			for i := 0; i < sz; i++ {
+				re := math.Float64frombits(binary.LittleEndian.Uint64(data[i*16:]))
+				im := math.Float64frombits(binary.LittleEndian.Uint64(data[i*16+8:]))
+				(t.Wide)[i] = complex128(complex(re, im))
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+			if sz < 0 || sz > math.MaxInt32 {
+				return fmt.Errorf("invalid slice length %d", sz)
+			}
+
+			data := make([]byte, sz*8)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return err
+			}
+
+			t.Named = make([]IQSampleTestType, sz)
+			for i := 0; i < sz; i++ {
+				re := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8:]))
+				im := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8+4:]))
+				(t.Named)[i] = IQSampleTestType(complex(re, im))
+			}
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Optional = nil
+			} else {
+				var tmp_t_Optional complex128
+
+				{
+					var bs = make([]byte, 16)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+					re := math.Float64frombits(binary.LittleEndian.Uint64(bs))
+					im := math.Float64frombits(binary.LittleEndian.Uint64(bs[8:]))
+					tmp_t_Optional = complex128(complex(re, im))
+
+				}
+
+				t.Optional = &tmp_t_Optional
+
+			}
+		}
+
+		{
+			for i := 0; i < 2; i++ {
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+				re := math.Float32frombits(binary.LittleEndian.Uint32(bs))
+				im := math.Float32frombits(binary.LittleEndian.Uint32(bs[4:]))
+				(t.Fixed)[i] = complex64(complex(re, im))
+
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+			if sz < 0 || sz > math.MaxInt32 {
+				return fmt.Errorf("invalid slice length %d", sz)
+			}
+
+			if sz > 4 {
+				return fmt.Errorf("field '%v' has a maximum length of %v", "Bounded", 4)
+			}
+
+			data := make([]byte, sz*8)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return err
+			}
+
+			t.Bounded = make([]complex64, sz)
+			for i := 0; i < sz; i++ {
+				re := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8:]))
+				im := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8+4:]))
+				(t.Bounded)[i] = complex64(complex(re, im))
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t EmbeddedTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t EmbeddedTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			x := t.BaseTestType.ID
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			v := t.BaseTestType.Name
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			_, err := writer.Write([]byte(v))
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			tmp_t_AuditTestType := t.AuditTestType
+			if tmp_t_AuditTestType == nil {
+				tmp_t_AuditTestType = new(AuditTestType)
+			}
+			{
+
+				{
+					x := (*tmp_t_AuditTestType).LevelTestType.Level
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					v := (*tmp_t_AuditTestType).CreatedBy
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+		}
+
+		{
+			if x := t.StringTestType; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := (*t.StringTestType)
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			switch u := t.EventTestType.(type) {
+			case nil:
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+
+			case CreatedTestType:
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+
+					{
+						x := u.ID
+						ux := uint64(x) << 1
+						if x < 0 {
+							ux = ^ux
+						}
+						bs := make([]byte, 8)
+						binary.LittleEndian.PutUint64(bs, ux)
+						_, err := writer.Write(bs)
+						if err != nil {
+							return err
+						}
+					}
+				}
+
+			case *DeletedTestType:
+				if _, err := writer.Write([]byte{2}); err != nil {
+					return err
+				}
+
+				{
+					if x := u; x == nil {
+						if _, err := writer.Write([]byte{0}); err != nil {
+							return err
+						}
+					} else {
+						if _, err := writer.Write([]byte{1}); err != nil {
+							return err
+						}
+
+						{
+
+							{
+								x := (*u).ID
+								ux := uint64(x) << 1
+								if x < 0 {
+									ux = ^ux
+								}
+								bs := make([]byte, 8)
+								binary.LittleEndian.PutUint64(bs, ux)
+								_, err := writer.Write(bs)
+								if err != nil {
+									return err
+								}
+							}
+
+							{
+								v := (*u).Reason
+								n := len(v)
+								ux := uint64(n) << 1
+								if n < 0 {
+									ux = ^ux
+								}
+								sz := make([]byte, 8)
+								binary.LittleEndian.PutUint64(sz, ux)
+								if _, err := writer.Write(sz); err != nil {
+									return err
+								}
+
+								_, err := writer.Write([]byte(v))
+								if err != nil {
+									return err
+								}
+							}
+						}
+
+					}
+				}
+
+			default:
+				return fmt.Errorf("type %T is not part of the union", u)
+			}
+		}
+
+		{
+			v := t.Value
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			_, err := writer.Write([]byte(v))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *EmbeddedTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *EmbeddedTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.BaseTestType.ID = int(x)
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+			if sz > 5 {
+				return fmt.Errorf("field '%v' has a maximum length of %v", "Name", 5)
+			}
+
+			b := make([]byte, sz)
+			if _, err := io.ReadFull(reader, b); err != nil {
+				return err
+			}
+
+			t.BaseTestType.Name = string(b)
+
+		}
+
+		{
+			t.AuditTestType = new(AuditTestType)
+			{
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					(*t.AuditTestType).LevelTestType.Level = int(x)
+
+				}
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					(*t.AuditTestType).CreatedBy = string(b)
+
+				}
+			}
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.StringTestType = nil
+			} else {
+				var tmp_t_StringTestType StringTestType
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_StringTestType = StringTestType(b)
+
+				}
+
+				t.StringTestType = &tmp_t_StringTestType
+
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.EventTestType = nil
+
+			case 1:
+				var tmp_t_EventTestType CreatedTestType
+				{
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+						tmp_t_EventTestType.ID = int(x)
+
+					}
+				}
+
+				t.EventTestType = tmp_t_EventTestType
+
+			case 2:
+				var tmp_t_EventTestType *DeletedTestType
+
+				{
+					var v = make([]byte, 1)
+					if _, err := io.ReadFull(reader, v); err != nil {
+						return err
+					}
+
+					if v[0] == 0 {
+						tmp_t_EventTestType = nil
+					} else {
+						var tmp_tmp_t_EventTestType DeletedTestType
+						{
+
+							{
+								var bs = make([]byte, 8)
+								if _, err := io.ReadFull(reader, bs); err != nil {
+									return err
+								}
+
+								ux := binary.LittleEndian.Uint64(bs)
+								x := int64(ux >> 1)
+								if ux&1 != 0 {
+									x = ^x
+								}
+								tmp_tmp_t_EventTestType.ID = int(x)
+
+							}
+
+							{
+								var bs = make([]byte, 8)
+								if _, err := io.ReadFull(reader, bs); err != nil {
+									return err
+								}
+
+								ux := binary.LittleEndian.Uint64(bs)
+								x := int64(ux >> 1)
+								if ux&1 != 0 {
+									x = ^x
+								}
+
+								sz := int(x)
+
+								b := make([]byte, sz)
+								if _, err := io.ReadFull(reader, b); err != nil {
+									return err
+								}
+
+								tmp_tmp_t_EventTestType.Reason = string(b)
+
+							}
+						}
+
+						tmp_t_EventTestType = &tmp_tmp_t_EventTestType
+
+					}
+				}
+
+				t.EventTestType = tmp_t_EventTestType
+
+			default:
+				return fmt.Errorf("invalid type for union EventTestType: %d", v[0])
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			b := make([]byte, sz)
+			if _, err := io.ReadFull(reader, b); err != nil {
+				return err
+			}
+
+			t.Value = string(b)
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t AccessorTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t AccessorTestType) WriteBinary(writer io.Writer) error {
+	{
+		{
+
+			{
+				x := t.Account.ID
+				ux := uint64(x) << 1
+				if x < 0 {
+					ux = ^ux
+				}
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint64(bs, ux)
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+			}
+
+			{
+				v := t.Account.Owner()
+				n := len(v)
+				ux := uint64(n) << 1
+				if n < 0 {
+					ux = ^ux
+				}
+				sz := make([]byte, 8)
+				binary.LittleEndian.PutUint64(sz, ux)
+				if _, err := writer.Write(sz); err != nil {
+					return err
+				}
+
+				_, err := writer.Write([]byte(v))
+				if err != nil {
+					return err
+				}
+			}
+
+			{
+				x := t.Account.Balance()
+				ux := uint64(x) << 1
+				if x < 0 {
+					ux = ^ux
+				}
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint64(bs, ux)
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		{
+			n := len(t.Accounts)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+
+				{
+					x := t.Accounts[i].ID
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					v := t.Accounts[i].Owner()
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					x := t.Accounts[i].Balance()
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		{
+			v, err := t.Token.MarshalBinary()
+			if err != nil {
+				return err
+			}
+
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			if _, err := writer.Write(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *AccessorTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *AccessorTestType) DecodeBinary(reader io.Reader) error {
+	{
+		{
+
+			{
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint64(bs)
+				x := int64(ux >> 1)
+				if ux&1 != 0 {
+					x = ^x
+				}
+				t.Account.ID = int(x)
+
+			}
+			{
+				var tmp_t_Account_owner string
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_Account_owner = string(b)
+
+				}
+
+				t.Account.SetOwner(tmp_t_Account_owner)
+			}
+			{
+				var tmp_t_Account_balance int64
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					tmp_t_Account_balance = int64(x)
+
+				}
+
+				t.Account.SetBalance(tmp_t_Account_balance)
+			}
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Accounts = make([]testpkg.Account, sz)
+
+			for i := 0; i < sz; i++ {
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					(t.Accounts)[i].ID = int(x)
+
+				}
+				{
+					var tmp__t_Accounts__i__owner string
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+
+						sz := int(x)
+
+						b := make([]byte, sz)
+						if _, err := io.ReadFull(reader, b); err != nil {
+							return err
+						}
+
+						tmp__t_Accounts__i__owner = string(b)
+
+					}
+
+					(t.Accounts)[i].SetOwner(tmp__t_Accounts__i__owner)
+				}
+				{
+					var tmp__t_Accounts__i__balance int64
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+						tmp__t_Accounts__i__balance = int64(x)
+
+					}
+
+					(t.Accounts)[i].SetBalance(tmp__t_Accounts__i__balance)
+				}
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			if x < 0 || x > math.MaxInt32 {
+				return fmt.Errorf("invalid length %d", x)
+			}
+
+			sz := int(x)
+
+			// The length is not trusted to allocate the bytes to read.
+			b, err := io.ReadAll(io.LimitReader(reader, x))
+			if err != nil {
+				return err
+			}
+			if len(b) < sz {
+				return io.ErrUnexpectedEOF
+			}
+
+			if err := (t.Token).UnmarshalBinary(b); err != nil {
+				return err
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t AliasTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t AliasTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			n := len(t.V1)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+
+				{
+					v := t.V1[i].Name
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		{
+			n := len(t.V2)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+
+				{
+					v := t.V2[i].Name
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					v := t.V2[i].Email
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		{
+			n := len(t.Points)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+
+				{
+					x := t.Points[i].X
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					x := t.Points[i].Y
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *AliasTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *AliasTestType) DecodeBinary(reader io.Reader) error {
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.V1 = make([]model.User, sz)
+
+			for i := 0; i < sz; i++ {
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					(t.V1)[i].Name = string(b)
+
+				}
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.V2 = make([]model2.User, sz)
+
+			for i := 0; i < sz; i++ {
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					(t.V2)[i].Name = string(b)
+
+				}
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					(t.V2)[i].Email = string(b)
+
+				}
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Points = make([]data2.Point, sz)
+
+			for i := 0; i < sz; i++ {
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					(t.Points)[i].X = int(x)
+
+				}
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					(t.Points)[i].Y = int(x)
+
+				}
+			}
+
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+// WARNING! This is code generated by bindec, do not modify manually.
+
+package bindec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+var _ = binary.LittleEndian
+var _ = math.Abs
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t BytesOnlyTestType) EncodeBinary() ([]byte, error) {
+	var buf = bytes.NewBuffer(nil)
+	err := func(writer io.Writer) error {
+		{
+
+			{
+				v := t.Name
+				n := len(v)
+				ux := uint64(n) << 1
+				if n < 0 {
+					ux = ^ux
+				}
+				sz := make([]byte, 8)
+				binary.LittleEndian.PutUint64(sz, ux)
+				if _, err := writer.Write(sz); err != nil {
+					return err
+				}
+
+				_, err := writer.Write([]byte(v))
+				if err != nil {
+					return err
+				}
+			}
+
+			{
+				n := len(t.Items)
+				ux := uint64(n) << 1
+				if n < 0 {
+					ux = ^ux
+				}
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint64(bs, ux)
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+
+				for i := 0; i < n; i++ {
+					x := t.Items[i]
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	}(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *BytesOnlyTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader io.Reader = bytes.NewReader(data)
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			b := make([]byte, sz)
+			if _, err := io.ReadFull(reader, b); err != nil {
+				return err
+			}
+
+			t.Name = string(b)
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Items = make([]int, sz)
+
+			for i := 0; i < sz; i++ {
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint64(bs)
+				x := int64(ux >> 1)
+				if ux&1 != 0 {
+					x = ^x
+				}
+				(t.Items)[i] = int(x)
+
+			}
+
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/erizocosmico/bindec"
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// compat compares the schemas of the types at two revisions and prints the
+// changes. It returns the exit code, which is 1 if any of the changes is
+// breaking and 2 if the schemas could not be loaded.
+func compat(args []string) int {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bindec compat [flags] OLD NEW\n\nOLD and NEW are the directories of the package at two revisions, or schema files written by bindec -schema.\n\n")
+		fs.PrintDefaults()
+	}
+
+	var types, tags string
+	var refs, changesJSON bool
+	var maxDepth int
+	fs.StringVar(&types, "type", "", "Comma-separated list of types to compare. By default, all the types of the schemas, or the ones with a //bindec:generate directive in packages.")
+	fs.StringVar(&tags, "tags", "", "Comma-separated list of build tags used to load the packages.")
+	fs.BoolVar(&refs, "refs", false, "Track references of the types in packages, as they were generated with -refs.")
+	fs.IntVar(&maxDepth, "maxdepth", bindec.DefaultMaxDepth, "Maximum depth of recursive types in packages, as they were generated with -maxdepth.")
+	fs.BoolVar(&changesJSON, "json", false, "Print the changes as a JSON array instead of text.")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	opts := bindec.Options{
+		Types:           splitTags(types),
+		Tags:            splitTags(tags),
+		MaxDepth:        maxDepth,
+		TrackReferences: refs,
+	}
+
+	var schemas [2]*schema.Schema
+	for i, path := range fs.Args() {
+		s, err := compatSchema(path, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			return 2
+		}
+		schemas[i] = s
+	}
+
+	changes := schema.Compare(schemas[0], schemas[1])
+	if changesJSON {
+		if changes == nil {
+			changes = []schema.Change{}
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		assert(enc.Encode(changes))
+	} else {
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+
+	if schema.HasBreaking(changes) {
+		return 1
+	}
+	return 0
+}
+
+// compatSchema returns the schema of the types of the options at the given
+// path, which is either a schema file or the directory of a package, which is
+// loaded with the options. Types of schema files that are not given are
+// ignored, and the ones given must be in them.
+func compatSchema(path string, opts bindec.Options) (*schema.Schema, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		opts.Path = path
+		return bindec.Schema(opts)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := schema.Parse(data)
+	if err != nil || len(opts.Types) == 0 {
+		return s, err
+	}
+
+	var named []schema.Named
+	for _, t := range opts.Types {
+		var found bool
+		for _, n := range s.Types {
+			if n.Name == t {
+				named = append(named, n)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("type %s not found in the schema", t)
+		}
+	}
+	s.Types = named
+	return s, nil
+}
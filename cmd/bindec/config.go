@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/erizocosmico/bindec"
+)
+
+// configFile is the name of the configuration file that is read by default
+// from the working directory.
+const configFile = "bindec.json"
+
+// config describes the packages and types to generate and their options.
+type config struct {
+	// Tags are the build tags used to load the packages.
+	Tags []string `json:"tags"`
+	// MaxDepth is the maximum depth of recursive types of all types.
+	MaxDepth int `json:"maxdepth"`
+	// Refs enables reference tracking for all types.
+	Refs bool `json:"refs"`
+	// Packages to generate.
+	Packages []packageConfig `json:"packages"`
+}
+
+// packageConfig describes a package, or packages if its path is a pattern
+// such as "./...", to generate.
+type packageConfig struct {
+	// Path of the package. Relative paths are relative to the directory of
+	// the config file.
+	Path string `json:"path"`
+	// Output is the name of the generated file, relative to the directory
+	// of the package.
+	Output string `json:"output"`
+	// Package is the import path of the package declaring the types, if
+	// it's not this one. It requires Functions.
+	Package string `json:"package"`
+	// Functions generates functions instead of methods.
+	Functions bool `json:"functions"`
+	// Methods to generate for all types of the package, in the format
+	// accepted by bindec.ParseMethods.
+	Methods string `json:"methods"`
+	// Types to generate. If there are none, all types with a
+	// //bindec:generate directive are generated.
+	Types []typeConfig `json:"types"`
+}
+
+// typeConfig describes a type to generate.
+type typeConfig struct {
+	Name     string `json:"name"`
+	Recv     string `json:"recv"`
+	MaxDepth int    `json:"maxdepth"`
+	Refs     bool   `json:"refs"`
+	Methods  string `json:"methods"`
+}
+
+// readConfig reads the configuration file at the given path.
+func readConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %s", path, err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %s", path, err)
+	}
+
+	return &cfg, nil
+}
+
+func (c *config) validate() error {
+	if len(c.Packages) == 0 {
+		return fmt.Errorf("no packages to generate")
+	}
+
+	if c.MaxDepth < 0 {
+		return fmt.Errorf("maxdepth must be a positive number, got %d", c.MaxDepth)
+	}
+
+	for i, p := range c.Packages {
+		if p.Path == "" {
+			return fmt.Errorf("package %d has no path", i)
+		}
+
+		if strings.ContainsRune(p.Output, filepath.Separator) {
+			return fmt.Errorf("output of package %s must be a file name, got %q", p.Path, p.Output)
+		}
+
+		if isPattern(p.Path) && (len(p.Types) > 0 || p.Package != "") {
+			return fmt.Errorf("types and package can not be given for pattern %s, use //bindec:generate directives instead", p.Path)
+		}
+
+		if p.Methods != "" {
+			if _, err := bindec.ParseMethods(p.Methods); err != nil {
+				return fmt.Errorf("invalid methods of package %s: %s", p.Path, err)
+			}
+		}
+
+		if p.Package != "" && !p.Functions {
+			return fmt.Errorf("package %s of %s requires functions, methods can't be declared on types of other packages", p.Package, p.Path)
+		}
+
+		var seen = make(map[string]bool)
+		for _, t := range p.Types {
+			if !token.IsIdentifier(t.Name) {
+				return fmt.Errorf("invalid type name %q in package %s", t.Name, p.Path)
+			}
+
+			if seen[t.Name] {
+				return fmt.Errorf("type %s is repeated in package %s", t.Name, p.Path)
+			}
+			seen[t.Name] = true
+
+			if t.Recv != "" && !token.IsIdentifier(t.Recv) {
+				return fmt.Errorf("invalid receiver name %q of type %s", t.Recv, t.Name)
+			}
+
+			if t.MaxDepth < 0 {
+				return fmt.Errorf("maxdepth of type %s must be a positive number, got %d", t.Name, t.MaxDepth)
+			}
+
+			if t.Methods != "" {
+				if _, err := bindec.ParseMethods(t.Methods); err != nil {
+					return fmt.Errorf("invalid methods of type %s: %s", t.Name, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// options returns the options to generate the given package, whose path is
+// relative to the given directory.
+func (c *config) options(dir string, p packageConfig) bindec.Options {
+	path := p.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	opts := bindec.Options{
+		Path:            path,
+		Package:         p.Package,
+		Functions:       p.Functions,
+		Methods:         parseMethods(p.Methods),
+		MaxDepth:        c.MaxDepth,
+		TrackReferences: c.Refs,
+		Tags:            c.Tags,
+	}
+
+	if len(p.Types) > 0 {
+		opts.TypeOptions = make(map[string]bindec.TypeOptions)
+	}
+
+	for _, t := range p.Types {
+		opts.Types = append(opts.Types, t.Name)
+		opts.TypeOptions[t.Name] = bindec.TypeOptions{
+			Recv:            t.Recv,
+			MaxDepth:        t.MaxDepth,
+			TrackReferences: t.Refs,
+			Methods:         parseMethods(t.Methods),
+		}
+	}
+
+	return opts
+}
+
+// parseMethods parses the methods of a config, which have already been
+// validated.
+func parseMethods(spec string) map[string]string {
+	if spec == "" {
+		return nil
+	}
+
+	methods, _ := bindec.ParseMethods(spec)
+	return methods
+}
+
+// override replaces the options of the config, including the ones of each
+// package and type, with the ones explicitly set in the command line.
+func (c *config) override(set map[string]bool, maxDepth int, refs, functions bool, methods string, tags []string) {
+	if set["tags"] {
+		c.Tags = tags
+	}
+
+	if set["maxdepth"] {
+		c.MaxDepth = maxDepth
+	}
+
+	if set["refs"] {
+		c.Refs = refs
+	}
+
+	for i := range c.Packages {
+		if set["functions"] {
+			c.Packages[i].Functions = functions
+		}
+
+		if set["methods"] {
+			c.Packages[i].Methods = methods
+		}
+
+		for j := range c.Packages[i].Types {
+			t := &c.Packages[i].Types[j]
+			if set["maxdepth"] {
+				t.MaxDepth = maxDepth
+			}
+
+			if set["refs"] {
+				t.Refs = refs
+			}
+
+			if set["methods"] {
+				t.Methods = methods
+			}
+		}
+	}
+}
+
+// generateConfig generates all the packages of the config, whose paths are
+// relative to the given directory, and prints a summary of the results. It
+// returns the exit code, like generatePackages.
+func generateConfig(cfg *config, dir string, check bool) int {
+	var results []result
+	for _, p := range cfg.Packages {
+		opts := cfg.options(dir, p)
+		if len(opts.Types) == 0 && opts.Package == "" {
+			// Packages without types are generated just like they are when
+			// given as arguments, using their directives.
+			opts.Path = dir
+			pkgs, err := bindec.GeneratePackages(opts, p.Path)
+			if err != nil {
+				results = append(results, result{bindec.PackageResult{Path: p.Path, Err: err}, ""})
+				continue
+			}
+
+			for _, r := range pkgs {
+				results = append(results, packageResult(r, p.Output, opts))
+			}
+			continue
+		}
+
+		file := p.Output
+		if file == "" {
+			file = defaultFile(opts.Types)
+		}
+
+		r := bindec.PackageResult{Path: p.Path, Dir: opts.Path, Types: opts.Types}
+		if isTestFile(file) {
+			r.Code, r.Err = bindec.Generate(opts)
+		} else {
+			r.Code, r.TestCode, r.Err = bindec.GenerateFiles(opts)
+		}
+		results = append(results, result{r, filepath.Join(r.Dir, file)})
+	}
+
+	return writeResults(results, dir, check)
+}
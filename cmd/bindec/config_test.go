@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erizocosmico/bindec"
+)
+
+func TestReadConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{
+			"valid",
+			`{"maxdepth": 5, "packages": [{"path": "./foo", "types": [{"name": "Foo", "recv": "f"}]}]}`,
+			"",
+		},
+		{"unknown field", `{"packages": [{"path": "./foo"}], "foo": 1}`, `unknown field "foo"`},
+		{"invalid json", `{"packages": `, "unexpected EOF"},
+		{"no packages", `{}`, "no packages to generate"},
+		{"negative maxdepth", `{"maxdepth": -1, "packages": [{"path": "."}]}`, "maxdepth must be a positive number, got -1"},
+		{"no path", `{"packages": [{"output": "foo.go"}]}`, "package 0 has no path"},
+		{
+			"output with directory",
+			`{"packages": [{"path": ".", "output": "foo/bar.go"}]}`,
+			`output of package . must be a file name, got "foo/bar.go"`,
+		},
+		{
+			"types of pattern",
+			`{"packages": [{"path": "./...", "types": [{"name": "Foo"}]}]}`,
+			"types and package can not be given for pattern ./...",
+		},
+		{
+			"package of pattern",
+			`{"packages": [{"path": "./...", "package": "foo", "functions": true}]}`,
+			"types and package can not be given for pattern ./...",
+		},
+		{
+			"invalid package methods",
+			`{"packages": [{"path": ".", "methods": "Foo"}]}`,
+			"invalid methods of package .",
+		},
+		{
+			"package without functions",
+			`{"packages": [{"path": ".", "package": "foo"}]}`,
+			"package foo of . requires functions",
+		},
+		{
+			"invalid type name",
+			`{"packages": [{"path": ".", "types": [{"name": "1Foo"}]}]}`,
+			`invalid type name "1Foo" in package .`,
+		},
+		{
+			"repeated type",
+			`{"packages": [{"path": ".", "types": [{"name": "Foo"}, {"name": "Foo"}]}]}`,
+			"type Foo is repeated in package .",
+		},
+		{
+			"invalid receiver",
+			`{"packages": [{"path": ".", "types": [{"name": "Foo", "recv": "a b"}]}]}`,
+			`invalid receiver name "a b" of type Foo`,
+		},
+		{
+			"negative type maxdepth",
+			`{"packages": [{"path": ".", "types": [{"name": "Foo", "maxdepth": -2}]}]}`,
+			"maxdepth of type Foo must be a positive number, got -2",
+		},
+		{
+			"invalid type methods",
+			`{"packages": [{"path": ".", "types": [{"name": "Foo", "methods": "WriteBinary:1x"}]}]}`,
+			"invalid methods of type Foo",
+		},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("bindec%d.json", i))
+			if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := readConfig(path)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if cfg.MaxDepth != 5 || cfg.Packages[0].Types[0].Recv != "f" {
+					t.Errorf("unexpected config: %+v", cfg)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got: %v", tt.err, err)
+			}
+
+			if err != nil && !strings.HasPrefix(err.Error(), "invalid config file "+path+": ") {
+				t.Errorf("expected error to mention the config file, got: %s", err)
+			}
+		})
+	}
+
+	if _, err := readConfig(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+}
+
+func TestConfigOverride(t *testing.T) {
+	newConfig := func() *config {
+		return &config{
+			Tags:     []string{"foo"},
+			MaxDepth: 5,
+			Packages: []packageConfig{
+				{
+					Path:    "./a",
+					Methods: "encode",
+					Types: []typeConfig{
+						{Name: "A", Recv: "a", MaxDepth: 3, Refs: true, Methods: "decode"},
+						{Name: "B"},
+					},
+				},
+				{Path: "./b", Functions: true},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		set      []string
+		expected func(*config)
+	}{
+		{"nothing set", nil, func(*config) {}},
+		{
+			"tags",
+			[]string{"tags"},
+			func(c *config) {
+				c.Tags = []string{"bar", "baz"}
+			},
+		},
+		{
+			"maxdepth",
+			[]string{"maxdepth"},
+			func(c *config) {
+				c.MaxDepth = 20
+				c.Packages[0].Types[0].MaxDepth = 20
+				c.Packages[0].Types[1].MaxDepth = 20
+			},
+		},
+		{
+			"refs",
+			[]string{"refs"},
+			func(c *config) {
+				c.Refs = true
+				c.Packages[0].Types[1].Refs = true
+			},
+		},
+		{
+			"functions",
+			[]string{"functions"},
+			func(c *config) {
+				c.Packages[1].Functions = false
+			},
+		},
+		{
+			"methods",
+			[]string{"methods"},
+			func(c *config) {
+				c.Packages[0].Methods = "stream"
+				c.Packages[1].Methods = "stream"
+				c.Packages[0].Types[0].Methods = "stream"
+				c.Packages[0].Types[1].Methods = "stream"
+			},
+		},
+		{
+			"all",
+			[]string{"tags", "maxdepth", "refs", "functions", "methods"},
+			func(c *config) {
+				c.Tags = []string{"bar", "baz"}
+				c.MaxDepth = 20
+				c.Refs = true
+				c.Packages[0].Methods = "stream"
+				c.Packages[1].Methods = "stream"
+				c.Packages[1].Functions = false
+				c.Packages[0].Types[0] = typeConfig{Name: "A", Recv: "a", MaxDepth: 20, Refs: true, Methods: "stream"}
+				c.Packages[0].Types[1] = typeConfig{Name: "B", MaxDepth: 20, Refs: true, Methods: "stream"}
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var set = make(map[string]bool)
+			for _, name := range tt.set {
+				set[name] = true
+			}
+
+			cfg := newConfig()
+			cfg.override(set, 20, true, false, "stream", []string{"bar", "baz"})
+
+			expected := newConfig()
+			tt.expected(expected)
+			if !reflect.DeepEqual(cfg, expected) {
+				t.Errorf("expected %+v, got %+v", expected, cfg)
+			}
+		})
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	cfg := &config{
+		Tags:     []string{"foo"},
+		MaxDepth: 5,
+		Packages: []packageConfig{
+			{
+				Path:    "a",
+				Methods: "encode",
+				Types: []typeConfig{
+					{Name: "A", Recv: "x", MaxDepth: 3, Refs: true, Methods: "decode"},
+					{Name: "B"},
+				},
+			},
+			{Path: "/b", Package: "example.com/c", Functions: true},
+		},
+	}
+
+	expected := bindec.Options{
+		Path:     filepath.Join("/root", "a"),
+		Methods:  map[string]string{"EncodeBinary": "", "WriteBinary": ""},
+		Types:    []string{"A", "B"},
+		MaxDepth: 5,
+		Tags:     []string{"foo"},
+		TypeOptions: map[string]bindec.TypeOptions{
+			"A": {
+				Recv:            "x",
+				MaxDepth:        3,
+				TrackReferences: true,
+				Methods:         map[string]string{"DecodeBinaryFromBytes": "", "DecodeBinary": ""},
+			},
+			"B": {},
+		},
+	}
+
+	if opts := cfg.options("/root", cfg.Packages[0]); !reflect.DeepEqual(opts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, opts)
+	}
+
+	expected = bindec.Options{
+		Path:      "/b",
+		Package:   "example.com/c",
+		Functions: true,
+		MaxDepth:  5,
+		Tags:      []string{"foo"},
+	}
+
+	if opts := cfg.options("/root", cfg.Packages[1]); !reflect.DeepEqual(opts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, opts)
+	}
+}
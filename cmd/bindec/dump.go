@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/erizocosmico/bindec"
+	"github.com/erizocosmico/bindec/dynamic"
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// dump decodes the value of a type encoded in a file and prints it with the
+// offsets and sizes of all the values it contains. It returns the exit code,
+// which is not zero if the value could not be decoded, in which case the
+// part decoded before the problem was found is printed anyway.
+func dump(args []string) int {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bindec dump -type=TYPE [flags] FILE\n\nFILE can be - to read from the standard input.\n\n")
+		fs.PrintDefaults()
+	}
+
+	var typ, schemaPath, tags string
+	var refs, annotatedJSON bool
+	var maxDepth int
+	fs.StringVar(&typ, "type", "", "Type of the encoded value.")
+	fs.StringVar(&schemaPath, "schema", "", "Schema file written by bindec -schema. By default, the schema of the type is read from the package in the working directory.")
+	fs.BoolVar(&refs, "refs", false, "Decode the value with reference tracking, when the schema is read from the package.")
+	fs.IntVar(&maxDepth, "maxdepth", bindec.DefaultMaxDepth, "Maximum depth of recursive types that will be decoded, when the schema is read from the package.")
+	fs.StringVar(&tags, "tags", "", "Comma-separated list of build tags used to load the package.")
+	fs.BoolVar(&annotatedJSON, "json", false, "Print the value as JSON annotated with offsets and sizes instead of text.")
+	fs.Parse(args)
+
+	if typ == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	s, err := dumpSchema(schemaPath, typ, refs, maxDepth, splitTags(tags))
+	assert(err)
+
+	data, err := readInput(fs.Arg(0))
+	assert(err)
+
+	v, err := dynamic.Decode(s, typ, data)
+	if v == nil {
+		assert(err)
+	}
+
+	if annotatedJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		assert(enc.Encode(v))
+	} else {
+		assert(dynamic.WriteText(os.Stdout, typ, v))
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if left := len(data) - v.Size; left > 0 {
+		fmt.Fprintf(os.Stderr, "%d bytes left after the value, starting at offset %d\n", left, v.Size)
+	}
+	return 0
+}
+
+// dumpSchema returns the schema at the given path, or the schema of the
+// given type of the package in the working directory if there is no path.
+func dumpSchema(path, typ string, refs bool, maxDepth int, tags []string) (*schema.Schema, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return schema.Parse(data)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	return bindec.Schema(bindec.Options{
+		Path:            wd,
+		Types:           []string{typ},
+		MaxDepth:        maxDepth,
+		TrackReferences: refs,
+		Tags:            tags,
+	})
+}
+
+// readInput reads the file at the given path, or the standard input if the
+// path is -.
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
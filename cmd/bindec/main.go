@@ -1,29 +1,126 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/erizocosmico/bindec"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
+// jsonOutput reports whether the problems found are printed as JSON instead
+// of text.
+var jsonOutput bool
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "dump":
+			os.Exit(dump(os.Args[2:]))
+		case "encode":
+			os.Exit(transcode("encode", os.Args[2:], true))
+		case "decode":
+			os.Exit(transcode("decode", os.Args[2:], false))
+		case "compat":
+			os.Exit(compat(os.Args[2:]))
+		}
+	}
+
 	var fs flag.FlagSet
-	var recv, path, typ, output string
+	var recv, path, typ, output, tags string
+	var maxDepth int
+	var refs, check, functions, schemaOutput bool
+	var configPath, pkg, methods string
 	fs.StringVar(&recv, "recv", "t", "Name given to the receiver type on the generated methods. For multiple types, separate with commas e.g. -recv=t,x,c.")
-	fs.StringVar(&typ, "type", "", "Type/s to generate encoder and decoder for. Separate with commas for more than one e.g. -type=A,B,C.")
-	fs.StringVar(&output, "o", "", "Generated file name, by default TYPE_bindec.go.")
+	fs.StringVar(&typ, "type", "", "Type/s to generate encoder and decoder for. Separate with commas for more than one e.g. -type=A,B,C. By default, all types with a //bindec:generate directive.")
+	fs.StringVar(&output, "o", "", "Generated file name, by default TYPE_bindec.go, or generated_bindec.go if no types are given. Types declared in test files are generated in a file with the same name ending in _test.go, unless it's already a test file. When generating multiple packages, the name of the file in each package. Use - to write to the standard output.")
+	fs.BoolVar(&refs, "refs", false, "Track references so values pointed to more than once are only encoded once and remain shared when decoded.")
+	fs.IntVar(&maxDepth, "maxdepth", bindec.DefaultMaxDepth, "Maximum depth of recursive types that will be decoded.")
+	fs.StringVar(&tags, "tags", "", "Comma-separated list of build tags used to load the package.")
+	fs.BoolVar(&check, "check", false, "Check that the generated file is up to date instead of writing it. If it's not, a diff is printed and the exit code is 1.")
+	fs.BoolVar(&functions, "functions", false, "Generate EncodeTYPE and DecodeTYPE functions instead of methods.")
+	fs.StringVar(&pkg, "package", "", "Import path of the package declaring the types, if it's not the one being generated. Requires -functions.")
+	fs.StringVar(&methods, "methods", "", "Comma-separated list of methods to generate, which can be all, encode, decode, bytes, stream or the name of a method, such as WriteBinary, optionally renamed, such as WriteBinary:Encode. By default, all of them.")
+	fs.BoolVar(&jsonOutput, "json", false, "Print the problems found as a JSON array of diagnostics with their positions instead of text.")
+	fs.BoolVar(&schemaOutput, "schema", false, "Write a JSON schema describing the binary layout of the types instead of generating code, by default to TYPE_schema.json, or bindec_schema.json if no types are given.")
+	fs.StringVar(&configPath, "config", "", "Config file describing the packages and types to generate. By default, "+configFile+" is used if it exists in the working directory and no types, receivers, output file or packages are given. -tags, -maxdepth, -refs, -functions and -methods override its values for every package and type, and -type, -recv, -o, -package, -schema and packages can't be given with it.")
 	fs.Parse(os.Args[1:])
 
-	if typ == "" {
-		fmt.Println("-type argument is mandatory!")
-		os.Exit(1)
+	var methodsOpt map[string]string
+	if methods != "" {
+		var err error
+		methodsOpt, err = bindec.ParseMethods(methods)
+		assert(err)
 	}
 
 	args := fs.Args()
+	if check && output == "-" {
+		assert(fmt.Errorf("-check can not be used to check the standard output"))
+	}
+
+	if jsonOutput && output == "-" {
+		assert(fmt.Errorf("-json can not be used to write to the standard output"))
+	}
+
+	var set = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if configPath == "" && !set["type"] && !set["recv"] && !set["o"] && !set["package"] && len(args) == 0 {
+		if _, err := os.Stat(configFile); err == nil {
+			configPath = configFile
+		}
+	}
+
+	if schemaOutput && (configPath != "" || isMultiPackage(args)) {
+		assert(fmt.Errorf("-schema can only be used with a single package"))
+	}
+
+	if configPath != "" {
+		if set["type"] || set["recv"] || set["o"] || set["package"] || len(args) > 0 {
+			assert(fmt.Errorf("-type, -recv, -o, -package and packages can not be given with a config file, add them to the config file instead"))
+		}
+
+		cfg, err := readConfig(configPath)
+		assert(err)
+		cfg.override(set, maxDepth, refs, functions, methods, splitTags(tags))
+
+		dir, err := filepath.Abs(filepath.Dir(configPath))
+		assert(err)
+
+		os.Exit(generateConfig(cfg, dir, check))
+	}
+
+	if isMultiPackage(args) {
+		if typ != "" || recv != "t" || pkg != "" {
+			assert(fmt.Errorf("-type, -recv and -package can not be used with multiple packages, use //bindec:generate directives instead"))
+		}
+
+		if output == "-" {
+			assert(fmt.Errorf("-o - can not be used with multiple packages"))
+		}
+
+		wd, err := os.Getwd()
+		assert(err)
+
+		os.Exit(generatePackages(args, output, check, bindec.Options{
+			Path:            wd,
+			Functions:       functions,
+			Methods:         methodsOpt,
+			MaxDepth:        maxDepth,
+			TrackReferences: refs,
+			Tags:            splitTags(tags),
+		}))
+	}
+
 	if l := len(args); l > 1 {
 		fs.Usage()
 		os.Exit(1)
@@ -35,8 +132,19 @@ func main() {
 		assert(err)
 	}
 
+	var types []string
+	if typ != "" {
+		types = strings.Split(typ, ",")
+	}
 	recvs := strings.Split(recv, ",")
-	types := strings.Split(typ, ",")
+
+	if pkg != "" && !functions {
+		assert(fmt.Errorf("-package can only be used with -functions, methods can't be declared on types of other packages"))
+	}
+
+	if len(types) == 0 && recv != "t" {
+		assert(fmt.Errorf("-recv can only be used with -type, use the recv option of the //bindec:generate directive instead"))
+	}
 
 	if len(recvs) == 0 || recv == "t" {
 		recvs = make([]string, len(types))
@@ -52,30 +160,376 @@ func main() {
 		))
 	}
 
-	filename := strings.ToLower(strings.Join(types, "_")) + "_bindec.go"
-	content, err := bindec.Generate(bindec.Options{
-		Path:  path,
-		Recvs: recvs,
-		Types: types,
-	})
-	assert(err)
+	opts := bindec.Options{
+		Path:            path,
+		Package:         pkg,
+		Functions:       functions,
+		Methods:         methodsOpt,
+		Recvs:           recvs,
+		Types:           types,
+		MaxDepth:        maxDepth,
+		TrackReferences: refs,
+		Tags:            splitTags(tags),
+	}
 
 	file := output
-	if file == "" {
-		file = filepath.Join(path, filename)
+	if file == "" && schemaOutput {
+		file = filepath.Join(path, defaultSchemaFile(types))
+	} else if file == "" {
+		file = filepath.Join(path, defaultFile(types))
+	}
+
+	// Types declared in test files are generated in a test file, unless
+	// everything is already written to one.
+	var content, testContent []byte
+	var err error
+	if schemaOutput {
+		content, err = generateSchema(opts)
+	} else if output == "-" || isTestFile(file) {
+		content, err = bindec.Generate(opts)
+	} else {
+		content, testContent, err = bindec.GenerateFiles(opts)
+	}
+	assert(err)
+
+	if output == "-" {
+		_, err = os.Stdout.Write(content)
+		assert(err)
+		return
+	}
+
+	files := []generatedFile{{file, content}, {testFile(file), testContent}}
+	if check {
+		var diags bindec.Diagnostics
+		for _, f := range files {
+			if f.code != nil && !checkFile(f.path, f.path, f.code) {
+				diags = append(diags, staleDiagnostic(f.path))
+			}
+		}
+
+		if len(diags) > 0 {
+			if jsonOutput {
+				printJSON(diags)
+			}
+			os.Exit(1)
+		}
+	} else {
+		for _, f := range files {
+			if f.code != nil {
+				assert(os.WriteFile(f.path, f.code, 0644))
+			}
+		}
+	}
+
+	if jsonOutput {
+		printJSON(nil)
+	}
+}
+
+// isMultiPackage reports whether the arguments are patterns matching more
+// than one package, such as "./...".
+func isMultiPackage(args []string) bool {
+	return len(args) > 1 || (len(args) == 1 && isPattern(args[0]))
+}
+
+// isPattern reports whether the path is a pattern that can match more than
+// one package.
+func isPattern(path string) bool {
+	return strings.Contains(path, "...")
+}
+
+// generatedFile is a file with generated code. If its code is nil, there
+// is nothing to write to it.
+type generatedFile struct {
+	path string
+	code []byte
+}
+
+// isTestFile reports whether the file at the given path is a test file.
+func isTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.go")
+}
+
+// testFile returns the path of the test file the code of the types declared
+// in test files is written to, along with the given generated file.
+func testFile(file string) string {
+	return strings.TrimSuffix(file, ".go") + "_test.go"
+}
+
+// defaultFile returns the name of the generated file for the given types.
+func defaultFile(types []string) string {
+	if len(types) == 0 {
+		return "generated_bindec.go"
+	}
+	return strings.ToLower(strings.Join(types, "_")) + "_bindec.go"
+}
+
+// defaultSchemaFile returns the name of the schema file for the given types.
+func defaultSchemaFile(types []string) string {
+	if len(types) == 0 {
+		return "bindec_schema.json"
+	}
+	return strings.ToLower(strings.Join(types, "_")) + "_schema.json"
+}
+
+// generateSchema returns the schema of the types to generate with the given
+// options as indented JSON.
+func generateSchema(opts bindec.Options) ([]byte, error) {
+	s, err := bindec.Schema(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// packageResult returns the result of generating a package, whose code is
+// written to the file with the given name in its directory, or the default
+// one if it's empty. The code of the types declared in test files is written
+// to a test file along with it, unless the file is already a test file, in
+// which case the code of all the types is generated in it with the options.
+func packageResult(r bindec.PackageResult, name string, opts bindec.Options) result {
+	if name == "" {
+		name = "generated_bindec.go"
+	}
+
+	if isTestFile(name) && r.TestCode != nil {
+		if r.Code == nil {
+			r.Code, r.TestCode = r.TestCode, nil
+		} else {
+			opts.Path = r.Dir
+			r.Code, r.Err = bindec.Generate(opts)
+			r.TestCode = nil
+		}
+	}
+
+	return result{r, filepath.Join(r.Dir, name)}
+}
+
+// result is the result of generating a package along with the path of the
+// file its code is written to. The code of its test files is written to the
+// test file of that one.
+type result struct {
+	bindec.PackageResult
+	file string
+}
+
+// files returns the files with the generated code of the result.
+func (r result) files() []generatedFile {
+	var files []generatedFile
+	if r.Code != nil {
+		files = append(files, generatedFile{r.file, r.Code})
+	}
+
+	if r.TestCode != nil {
+		files = append(files, generatedFile{testFile(r.file), r.TestCode})
 	}
+	return files
+}
 
-	f, err := os.Create(file)
+// generatePackages generates the code of all the packages matching the given
+// patterns and prints a summary of the results. If check is true, the
+// generated files are checked instead of written. It returns the exit code,
+// which is not zero if any package failed or is not up to date.
+func generatePackages(patterns []string, output string, check bool, opts bindec.Options) int {
+	if strings.ContainsRune(output, filepath.Separator) {
+		assert(fmt.Errorf("-o must be a file name when generating multiple packages, got %q", output))
+	}
+
+	pkgs, err := bindec.GeneratePackages(opts, patterns...)
 	assert(err)
 
-	_, err = f.Write(content)
+	var results = make([]result, len(pkgs))
+	for i, r := range pkgs {
+		results[i] = packageResult(r, output, opts)
+	}
+
+	return writeResults(results, opts.Path, check)
+}
+
+// writeResults writes the generated files of the given results, or checks
+// them if check is true, and prints a summary. File names are printed
+// relative to the given directory. It returns the exit code, which is not
+// zero if any package failed or is not up to date.
+func writeResults(results []result, dir string, check bool) int {
+	// When printing JSON, only the problems found are printed.
+	printf := fmt.Printf
+	if jsonOutput {
+		printf = func(string, ...interface{}) (int, error) { return 0, nil }
+	}
+
+	var generated, stale, skipped, failed int
+	var diags bindec.Diagnostics
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			diags = append(diags, diagnostics(r.Err)...)
+			printf("FAIL  %s: %s\n", r.Path, r.Err)
+			continue
+		}
+
+		files := r.files()
+		if len(files) == 0 {
+			skipped++
+			printf("SKIP  %s: no types to generate\n", r.Path)
+			continue
+		}
+
+		var names []string
+		for _, f := range files {
+			name := f.path
+			if rel, err := filepath.Rel(dir, f.path); err == nil {
+				name = rel
+			}
+			names = append(names, name)
+		}
+
+		if check {
+			var stalePaths []string
+			for i, f := range files {
+				if !checkFile(f.path, names[i], f.code) {
+					stalePaths = append(stalePaths, names[i])
+					diags = append(diags, staleDiagnostic(f.path))
+				}
+			}
+
+			if len(stalePaths) == 0 {
+				generated++
+				printf("OK    %s: up to date\n", r.Path)
+			} else {
+				stale++
+				printf("STALE %s: %s is not up to date\n", r.Path, strings.Join(stalePaths, ", "))
+			}
+			continue
+		}
+
+		var err error
+		for _, f := range files {
+			if err = os.WriteFile(f.path, f.code, 0644); err != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			failed++
+			diags = append(diags, diagnostics(err)...)
+			printf("FAIL  %s: %s\n", r.Path, err)
+			continue
+		}
+
+		types := strings.Join(r.Types, ", ")
+		if types == "" {
+			types = "annotated types"
+		}
+
+		generated++
+		printf("OK    %s: %s -> %s\n", r.Path, types, strings.Join(names, ", "))
+	}
+
+	if check {
+		printf("\n%d up to date, %d stale, %d skipped, %d failed\n", generated, stale, skipped, failed)
+	} else {
+		printf("\n%d generated, %d skipped, %d failed\n", generated, skipped, failed)
+	}
+
+	if jsonOutput {
+		printJSON(diags)
+	}
+
+	if failed > 0 || stale > 0 {
+		return 1
+	}
+	return 0
+}
+
+// checkFile reports whether the file at the given path has the given
+// content. If it doesn't, a unified diff between them is printed, in which
+// the file is referred to by name, unless JSON is printed.
+func checkFile(file, name string, content []byte) bool {
+	current, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		assert(err)
+	}
+
+	if bytes.Equal(current, content) {
+		return true
+	} else if jsonOutput {
+		return false
+	}
+
+	var lines []string
+	if len(current) > 0 {
+		lines = difflib.SplitLines(string(current))
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines,
+		B:        difflib.SplitLines(string(content)),
+		FromFile: name,
+		ToFile:   name + " (generated)",
+		Context:  3,
+	})
 	assert(err)
-	assert(f.Close())
+
+	fmt.Print(diff)
+	return false
+}
+
+func splitTags(tags string) []string {
+	var result []string
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// diagnostics returns the diagnostics of the given error. Errors that are
+// not caused by the declarations of the types are a diagnostic without a
+// position.
+func diagnostics(err error) bindec.Diagnostics {
+	var diags bindec.Diagnostics
+	if errors.As(err, &diags) {
+		return diags
+	}
+	return bindec.Diagnostics{{Message: err.Error()}}
+}
+
+// staleDiagnostic returns the diagnostic of a generated file that is not up
+// to date.
+func staleDiagnostic(file string) bindec.Diagnostic {
+	return bindec.Diagnostic{
+		Pos:     token.Position{Filename: file},
+		Message: "generated code is not up to date",
+	}
+}
+
+// printJSON prints the given diagnostics as a JSON array.
+func printJSON(diags bindec.Diagnostics) {
+	if diags == nil {
+		diags = bindec.Diagnostics{}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(diags); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 }
 
 func assert(err error) {
 	if err != nil {
-		fmt.Println(err)
+		if jsonOutput {
+			printJSON(diagnostics(err))
+		} else {
+			fmt.Println(err)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erizocosmico/bindec"
+)
+
+// binary is the path of the bindec command built for the tests.
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "bindec")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	binary = filepath.Join(dir, "bindec")
+	out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to build bindec: %s\n%s", err, out)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// writeModule writes a module with the given files to a temporary directory
+// and returns its path.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/q\n\ngo 1.22\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// run runs the bindec command in the given directory and returns its output
+// and exit code.
+func run(t *testing.T, dir string, args ...string) (string, int) {
+	t.Helper()
+
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if exit, ok := err.(*exec.ExitError); ok {
+		return string(out), exit.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(out), 0
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestGenerateTestFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"q.go":      "package q\n\n//bindec:generate\ntype A struct{ X int }\n",
+		"q_test.go": "package q\n\n//bindec:generate\ntype B struct{ Y string }\n",
+	})
+
+	if out, code := run(t, dir, "."); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	code := readFile(t, filepath.Join(dir, "generated_bindec.go"))
+	if !strings.Contains(code, "func (t A) WriteBinary(") || strings.Contains(code, "func (t B) WriteBinary(") {
+		t.Errorf("expected only A in generated_bindec.go, got:\n%s", code)
+	}
+
+	testCode := readFile(t, filepath.Join(dir, "generated_bindec_test.go"))
+	if !strings.Contains(testCode, "func (t B) WriteBinary(") || strings.Contains(testCode, "func (t A) WriteBinary(") {
+		t.Errorf("expected only B in generated_bindec_test.go, got:\n%s", testCode)
+	}
+
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected generated package to build: %s\n%s", err, out)
+	}
+
+	if out, code := run(t, dir, "-check", "."); code != 0 {
+		t.Errorf("expected generated files to be up to date, got exit code %d:\n%s", code, out)
+	}
+}
+
+func TestGeneratePackagesTestFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"q.go":        "package q\n\n//bindec:generate\ntype A struct{ X int }\n",
+		"q_test.go":   "package q\n\n//bindec:generate\ntype B struct{ Y string }\n",
+		"r/r.go":      "package r\n\ntype C struct{ X int }\n",
+		"r/r_test.go": "package r\n\n//bindec:generate\ntype D struct{ C C }\n",
+	})
+
+	if out, code := run(t, dir, "./..."); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	code := readFile(t, filepath.Join(dir, "generated_bindec.go"))
+	if !strings.Contains(code, "func (t A) WriteBinary(") || strings.Contains(code, "func (t B) WriteBinary(") {
+		t.Errorf("expected only A in generated_bindec.go, got:\n%s", code)
+	}
+
+	testCode := readFile(t, filepath.Join(dir, "generated_bindec_test.go"))
+	if !strings.Contains(testCode, "func (t B) WriteBinary(") || strings.Contains(testCode, "func (t A) WriteBinary(") {
+		t.Errorf("expected only B in generated_bindec_test.go, got:\n%s", testCode)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "r", "generated_bindec.go")); !os.IsNotExist(err) {
+		t.Errorf("expected no generated_bindec.go in r, got: %v", err)
+	}
+
+	testCode = readFile(t, filepath.Join(dir, "r", "generated_bindec_test.go"))
+	if !strings.Contains(testCode, "func (t D) WriteBinary(") {
+		t.Errorf("expected D in r/generated_bindec_test.go, got:\n%s", testCode)
+	}
+
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected generated packages to build: %s\n%s", err, out)
+	}
+
+	if out, code := run(t, dir, "-check", "./..."); code != 0 {
+		t.Errorf("expected generated files to be up to date, got exit code %d:\n%s", code, out)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"q.go": "package q\n\n//bindec:generate\ntype A struct{ X int }\n",
+	})
+	file := filepath.Join(dir, "generated_bindec.go")
+
+	out, code := run(t, dir, "-check", ".")
+	if code != 1 {
+		t.Errorf("expected exit code 1 for missing file, got %d:\n%s", code, out)
+	}
+
+	if !strings.Contains(out, "--- generated_bindec.go\n+++ generated_bindec.go (generated)\n") {
+		t.Errorf("expected diff of missing file, got:\n%s", out)
+	}
+
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("expected check not to write the file, got: %v", err)
+	}
+
+	if out, code := run(t, dir, "."); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	if out, code := run(t, dir, "-check", "."); code != 0 || out != "" {
+		t.Errorf("expected up to date file, got exit code %d:\n%s", code, out)
+	}
+
+	content := readFile(t, file)
+	stale := strings.Replace(content, "func (t A) WriteBinary(", "// stale\nfunc (t A) WriteBinary(", 1)
+	if err := os.WriteFile(file, []byte(stale), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code = run(t, dir, "-check", ".")
+	if code != 1 {
+		t.Errorf("expected exit code 1 for stale file, got %d:\n%s", code, out)
+	}
+
+	if !strings.Contains(out, "\n-// stale\n") {
+		t.Errorf("expected diff removing the stale line, got:\n%s", out)
+	}
+
+	if readFile(t, file) != stale {
+		t.Errorf("expected check not to write the file")
+	}
+
+	out, code = run(t, dir, "-check", "-json", ".")
+	if code != 1 {
+		t.Errorf("expected exit code 1 for stale file, got %d:\n%s", code, out)
+	}
+
+	var diags bindec.Diagnostics
+	if err := json.Unmarshal([]byte(out), &diags); err != nil {
+		t.Fatalf("expected JSON diagnostics, got %s:\n%s", err, out)
+	}
+
+	expected := bindec.Diagnostics{staleDiagnostic("generated_bindec.go")}
+	if !reflect.DeepEqual(diags, expected) {
+		t.Errorf("expected diagnostics %+v, got %+v", expected, diags)
+	}
+}
+
+func TestStdout(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"q.go": "package q\n\n//bindec:generate\ntype A struct{ X int }\n",
+	})
+
+	if out, code := run(t, dir, "."); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	file := filepath.Join(dir, "generated_bindec.go")
+	content := readFile(t, file)
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := run(t, dir, "-o", "-", ".")
+	if code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	if out != content {
+		t.Errorf("expected generated code in the standard output, got:\n%s", out)
+	}
+
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written, got: %v", err)
+	}
+}
+
+func TestCompatOptions(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"q.go": "package q\n\n//bindec:generate\ntype A struct{ Next *A }\n",
+	})
+
+	if out, code := run(t, dir, "-schema", "-refs", "-maxdepth", "5", "-o", "schema.json", "."); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	if out, code := run(t, dir, "compat", "-refs", "-maxdepth", "5", "schema.json", "."); code != 0 || out != "" {
+		t.Errorf("expected no changes, got exit code %d:\n%s", code, out)
+	}
+
+	if out, code := run(t, dir, "compat", "schema.json", "."); code != 1 {
+		t.Errorf("expected breaking changes without the options, got exit code %d:\n%s", code, out)
+	}
+}
+
+func TestCompatMissingType(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"q.go": "package q\n\n//bindec:generate\ntype A struct{ X int }\n",
+	})
+
+	if out, code := run(t, dir, "-schema", "-o", "schema.json", "."); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	cmd := exec.Command(binary, "compat", "-type=A,B", "schema.json", "schema.json")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 2 {
+		t.Errorf("expected exit code 2, got %v:\n%s", err, out)
+	}
+
+	if !strings.Contains(string(out), "schema.json: type B not found in the schema") {
+		t.Errorf("expected missing type error, got:\n%s", out)
+	}
+}
+
+func TestConfigFlags(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"q.go":        "package q\n\ntype A struct{ Next *A }\n",
+		"bindec.json": `{"packages": [{"path": ".", "types": [{"name": "A", "refs": true, "maxdepth": 5}]}]}`,
+	})
+	file := filepath.Join(dir, "a_bindec.go")
+
+	if out, code := run(t, dir); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	if code := readFile(t, file); !strings.Contains(code, "refs") || !strings.Contains(code, "depth > 5") {
+		t.Errorf("expected the options of the config file, got:\n%s", code)
+	}
+
+	// Flags replace the values of every type of the config file.
+	if out, code := run(t, dir, "-refs=false", "-maxdepth=7"); code != 0 {
+		t.Fatalf("unexpected exit code %d:\n%s", code, out)
+	}
+
+	if code := readFile(t, file); strings.Contains(code, "refs") || !strings.Contains(code, "depth > 7") {
+		t.Errorf("expected the options of the flags, got:\n%s", code)
+	}
+
+	for _, args := range [][]string{
+		{"-config=bindec.json", "-type=A"},
+		{"-config=bindec.json", "-recv=a"},
+		{"-config=bindec.json", "-o=a.go"},
+		{"-config=bindec.json", "-package=example.com/q", "-functions"},
+		{"-config=bindec.json", "."},
+	} {
+		out, code := run(t, dir, args...)
+		if code != 1 || !strings.Contains(out, "can not be given with a config file") {
+			t.Errorf("expected %v to be rejected, got exit code %d:\n%s", args, code, out)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/erizocosmico/bindec"
+	"github.com/erizocosmico/bindec/dynamic"
+)
+
+// transcode converts a JSON document to the data a type encodes it as, if
+// encode is true, or the data encoded by a type to a JSON document
+// otherwise. It returns the exit code, which is not zero if the input could
+// not be converted or does not satisfy the constraints of the type.
+func transcode(name string, args []string, encode bool) int {
+	input, output := "encoded data", "JSON document"
+	if encode {
+		input, output = output, input
+	}
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bindec %s -type=TYPE [flags] FILE\n\nConverts the %s of the type in FILE to the %s.\nFILE can be - to read from the standard input.\n\n", name, input, output)
+		fs.PrintDefaults()
+	}
+
+	var typ, schemaPath, tags, outputPath string
+	var refs bool
+	var maxDepth int
+	fs.StringVar(&typ, "type", "", "Type of the value.")
+	fs.StringVar(&schemaPath, "schema", "", "Schema file written by bindec -schema. By default, the schema of the type is read from the package in the working directory.")
+	fs.BoolVar(&refs, "refs", false, "Encode the value with reference tracking, when the schema is read from the package.")
+	fs.IntVar(&maxDepth, "maxdepth", bindec.DefaultMaxDepth, "Maximum depth of recursive types, when the schema is read from the package.")
+	fs.StringVar(&tags, "tags", "", "Comma-separated list of build tags used to load the package.")
+	fs.StringVar(&outputPath, "o", "-", fmt.Sprintf("File the %s is written to. Use - to write to the standard output.", output))
+	fs.Parse(args)
+
+	if typ == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	s, err := dumpSchema(schemaPath, typ, refs, maxDepth, splitTags(tags))
+	assert(err)
+
+	data, err := readInput(fs.Arg(0))
+	assert(err)
+
+	var result []byte
+	if encode {
+		result, err = dynamic.JSONToBinary(s, typ, data)
+	} else {
+		result, err = dynamic.BinaryToJSON(s, typ, data)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", fs.Arg(0), err)
+		return 1
+	}
+
+	if !encode {
+		var buf bytes.Buffer
+		assert(json.Indent(&buf, result, "", "  "))
+		buf.WriteByte('\n')
+		result = buf.Bytes()
+	}
+
+	if outputPath == "-" {
+		_, err = os.Stdout.Write(result)
+	} else {
+		err = os.WriteFile(outputPath, result, 0644)
+	}
+	assert(err)
+	return 0
+}
@@ -0,0 +1,14 @@
+// Command bindectags checks the bindec struct tags of the given packages.
+// It can also be used with go vet:
+//
+//	go vet -vettool=$(which bindectags) ./...
+package main
+
+import (
+	"github.com/erizocosmico/bindec/passes/tags"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(tags.Analyzer)
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/erizocosmico/bindec/internal/validate"
 )
 
 // Constraint to be checked on a struct field.
@@ -96,11 +99,19 @@ func parseConstraint(
 		ctx.addImport(i)
 	}
 
-	if d, ok := constraintDecls[name]; ok {
+	if d, ok := constraintDecl(name); ok {
 		ctx.addDecl(d)
 	}
 
 	ptr := isPtr(typ)
+	if isTime(typ) {
+		return parseTimeConstraint(name, args, field, ptr)
+	}
+
+	if isDuration(typ) {
+		return parseDurationConstraint(name, args, field, ptr)
+	}
+
 	switch name {
 	case "alpha",
 		"alphanum",
@@ -174,11 +185,64 @@ func parseConstraint(
 		}
 
 		return lenConstraint{field, n, constraintTemplates[name]}, nil
+	case "before", "after", "notzero":
+		return nil, fmt.Errorf("constraint %q can only be used on time.Time and time.Duration fields", name)
 	default:
 		return nil, fmt.Errorf("constraint not found: %s", name)
 	}
 }
 
+func parseTimeConstraint(
+	name, args string,
+	field string, ptr bool,
+) (Constraint, error) {
+	switch name {
+	case "before", "after":
+		t, err := time.Parse(time.RFC3339Nano, args)
+		if err != nil {
+			return nil, fmt.Errorf("%s value %q is not a valid RFC 3339 time", name, args)
+		}
+
+		arg := fmt.Sprintf("time.Unix(%d, %d).UTC()", t.Unix(), t.Nanosecond())
+		return argConstraint{field, ptr, arg, constraintTemplates[name]}, nil
+	case "notzero":
+		return stringConstraint{field, ptr, notZeroTimeTpl}, nil
+	default:
+		return nil, fmt.Errorf("constraint %q can not be used on time.Time fields", name)
+	}
+}
+
+func parseDurationConstraint(
+	name, args string,
+	field string, ptr bool,
+) (Constraint, error) {
+	switch name {
+	case "max", "min", "eq", "neq":
+		d, err := validate.ParseDuration(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s value %q is not a valid duration", name, args)
+		}
+
+		arg := fmt.Sprintf("time.Duration(%d)", d)
+		return argConstraint{field, ptr, arg, constraintTemplates[name]}, nil
+	case "oneof":
+		var options []string
+		for _, a := range strings.Fields(args) {
+			d, err := validate.ParseDuration(a)
+			if err != nil {
+				return nil, fmt.Errorf("oneof value %q is not a valid duration", a)
+			}
+			options = append(options, strconv.FormatInt(int64(d), 10))
+		}
+
+		return oneOf{field, ptr, options}, nil
+	case "notzero":
+		return stringConstraint{field, ptr, constraintTemplates[name]}, nil
+	default:
+		return nil, fmt.Errorf("constraint %q can not be used on time.Duration fields", name)
+	}
+}
+
 func isString(t Type) bool {
 	switch t := t.(type) {
 	case Basic:
@@ -195,6 +259,24 @@ func isString(t Type) bool {
 	}
 }
 
+func isTime(t Type) bool {
+	if m, ok := t.(Maybe); ok {
+		t = m.Elem
+	}
+
+	_, ok := t.(Time)
+	return ok
+}
+
+func isDuration(t Type) bool {
+	if m, ok := t.(Maybe); ok {
+		t = m.Elem
+	}
+
+	_, ok := t.(Duration)
+	return ok
+}
+
 func isNumber(t Type) bool {
 	switch t := t.(type) {
 	case Basic:
@@ -269,6 +351,9 @@ var constraints = map[string]bool{
 	"min":         true,
 	"maxlen":      true,
 	"minlen":      true,
+	"before":      true,
+	"after":       true,
+	"notzero":     false,
 }
 
 var constraintTemplates = map[string]string{
@@ -292,6 +377,9 @@ var constraintTemplates = map[string]string{
 	"contains":    containsTpl,
 	"startswith":  startsWithTpl,
 	"endswith":    endsWithTpl,
+	"before":      beforeTpl,
+	"after":       afterTpl,
+	"notzero":     notZeroTpl,
 }
 
 const (
@@ -360,6 +448,18 @@ const (
 	}`
 	minlenTpl = `if sz < %[1]d {
 	return fmt.Errorf("field '%%v' has a minimum length of %%v", %[2]q, %[1]d)
+}`
+	beforeTpl = `if !(%[1]s).Before(%[3]s) {
+	return fmt.Errorf("field '%%v' should be before %%v", %[2]q, %[3]s)
+}`
+	afterTpl = `if !(%[1]s).After(%[3]s) {
+	return fmt.Errorf("field '%%v' should be after %%v", %[2]q, %[3]s)
+}`
+	notZeroTpl = `if %[1]s == 0 {
+	return fmt.Errorf("field '%%v' should not be zero", %[2]q)
+}`
+	notZeroTimeTpl = `if (%[1]s).IsZero() {
+	return fmt.Errorf("field '%%v' should not be zero", %[2]q)
 }`
 )
 
@@ -376,14 +476,18 @@ var constraintImports = map[string][]string{
 	"contains":   []string{"strings"},
 	"startswith": []string{"strings"},
 	"endswith":   []string{"strings"},
+	"before":     []string{"time"},
+	"after":      []string{"time"},
 }
 
-var constraintDecls = map[string]string{
-	"numeric":     `var numericConstraintRegex = regexp.MustCompile("^[-+]?[0-9]+(?:\\.[0-9]+)?$")`,
-	"hexadecimal": `var hexadecimalConstraintRegex = regexp.MustCompile("^[0-9a-fA-F]+$")`,
-	"email":       `var emailConstraintRegex = regexp.MustCompile("^(?:(?:(?:(?:[a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_` + "`" + `{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+(?:\\.([a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_` + "`" + `{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+)*)|(?:(?:\\x22)(?:(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(?:\\x20|\\x09)+)?(?:(?:[\\x01-\\x08\\x0b\\x0c\\x0e-\\x1f\\x7f]|\\x21|[\\x23-\\x5b]|[\\x5d-\\x7e]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[\\x01-\\x09\\x0b\\x0c\\x0d-\\x7f]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}]))))*(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(\\x20|\\x09)+)?(?:\\x22))))@(?:(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.)+(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.?$")`,
-	"base64":      `var base64ConstraintRegex = regexp.MustCompile("^(?:[A-Za-z0-9+\\/]{4})*(?:[A-Za-z0-9+\\/]{2}==|[A-Za-z0-9+\\/]{3}=|[A-Za-z0-9+\\/]{4})$")`,
-	"uuid":        `var uuidConstraintRegex = regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$")`,
+// constraintDecl returns the declaration of the regular expression needed by
+// the constraint with the given name, if any.
+func constraintDecl(name string) (string, bool) {
+	pattern, ok := validate.Patterns[name]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("var %sConstraintRegex = regexp.MustCompile(%q)", name, pattern), true
 }
 
 func isValueOfType(v string, typ Type) bool {
@@ -426,6 +530,7 @@ func constraintsToCode(cs []Constraint, recv string) string {
 	var buf bytes.Buffer
 	for _, c := range cs {
 		buf.WriteString(c.Validator(recv))
+		buf.WriteString("\n")
 	}
 	return buf.String()
 }
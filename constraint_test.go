@@ -1,8 +1,13 @@
 package bindec
 
 import (
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/erizocosmico/bindec/dynamic"
+	"github.com/erizocosmico/bindec/schema"
 	"github.com/stretchr/testify/require"
 )
 
@@ -1649,4 +1654,234 @@ func assertConstraints(t *testing.T, in encoderDecoder, ok bool) {
 	} else {
 		require.Error(t, err)
 	}
+
+	// The transcoder checks the constraints just like the generated code.
+	_, dynErr := dynamic.BinaryToJSON(constraintsSchema(t), reflect.TypeOf(in).Elem().Name(), bs)
+	if ok {
+		require.NoError(t, dynErr)
+	} else {
+		require.IsType(t, &dynamic.ConstraintError{}, dynErr)
+		require.Equal(t, err.Error(), dynErr.(*dynamic.ConstraintError).Message)
+	}
+
+	// So does Unmarshal, with the same errors.
+	refBs, err2 := Marshal(in)
+	require.NoError(t, err2)
+	require.Equal(t, bs, refBs)
+
+	refErr := Unmarshal(bs, reflect.New(reflect.TypeOf(in).Elem()).Interface())
+	if ok {
+		require.NoError(t, refErr)
+	} else {
+		require.EqualError(t, refErr, err.Error())
+	}
+}
+
+var (
+	constraintsSchemaOnce sync.Once
+	constraintsSchemaData *schema.Schema
+	constraintsSchemaErr  error
+)
+
+// constraintsSchema returns the schema of the types with constraints, which
+// is only generated once for all tests.
+func constraintsSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	constraintsSchemaOnce.Do(func() {
+		constraintsSchemaData, constraintsSchemaErr = Schema(Options{
+			Types: []string{
+				"AlphaTestType", "AlphanumTestType", "NumericTestType",
+				"HexadecimalTestType", "EmailTestType", "URLTestType",
+				"Base64TestType", "IPv4TestType", "IPv6TestType", "IPTestType",
+				"UUIDTestType", "ContainsTestType", "StartsWithTestType",
+				"EndsWithTestType", "MinLenTestType", "MaxLenTestType",
+				"OneOfTestType", "EqTestType", "NeqTestType", "MinTestType",
+				"MaxTestType", "BeforeTestType", "AfterTestType",
+				"NotZeroTestType", "DurationTestType", "DurationNanosTestType",
+				"PointerConstraintTestType",
+			},
+		})
+	})
+	require.NoError(t, constraintsSchemaErr)
+	return constraintsSchemaData
+}
+
+func TestBefore(t *testing.T) {
+	testCases := []struct {
+		name string
+		time time.Time
+		ok   bool
+	}{
+		{"before", time.Date(2018, 12, 31, 0, 0, 0, 0, time.UTC), true},
+		{"equal", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"after", time.Date(2019, 1, 1, 0, 0, 0, 1, time.UTC), false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assertConstraints(t, &BeforeTestType{Time: tt.time}, tt.ok)
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	testCases := []struct {
+		name string
+		time time.Time
+		ok   bool
+	}{
+		{"before", time.Date(2018, 12, 31, 0, 0, 0, 0, time.UTC), false},
+		{"equal", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"after", time.Date(2019, 1, 1, 0, 0, 0, 1, time.UTC), true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assertConstraints(t, &AfterTestType{Time: tt.time}, tt.ok)
+		})
+	}
+}
+
+func TestNotZero(t *testing.T) {
+	testCases := []struct {
+		name     string
+		time     time.Time
+		duration time.Duration
+		ok       bool
+	}{
+		{"not zero", time.Now(), time.Second, true},
+		{"zero time", time.Time{}, time.Second, false},
+		{"zero duration", time.Now(), 0, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assertConstraints(t, &NotZeroTestType{Time: tt.time, Duration: tt.duration}, tt.ok)
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	testCases := []struct {
+		duration time.Duration
+		ok       bool
+	}{
+		{time.Millisecond, false},
+		{time.Second, true},
+		{time.Minute, true},
+		{time.Hour, true},
+		{2 * time.Hour, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.duration.String(), func(t *testing.T) {
+			assertConstraints(t, &DurationTestType{Duration: tt.duration}, tt.ok)
+		})
+	}
+}
+
+func TestDurationNanos(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input DurationNanosTestType
+		ok    bool
+	}{
+		{"valid", DurationNanosTestType{Duration: time.Microsecond, OneOf: 2000}, true},
+		{"small", DurationNanosTestType{Duration: 999, OneOf: 1000}, false},
+		{"big", DurationNanosTestType{Duration: 1000001, OneOf: 1000}, false},
+		{"not one of", DurationNanosTestType{Duration: time.Millisecond, OneOf: 3000}, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			assertConstraints(t, &input, tt.ok)
+		})
+	}
+}
+
+func TestPointerConstraints(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	tm := func(t time.Time) *time.Time { return &t }
+	slice := func(s ...int) *[]int { return &s }
+
+	testCases := []struct {
+		name  string
+		input PointerConstraintTestType
+		ok    bool
+	}{
+		{"nil", PointerConstraintTestType{}, true},
+		{
+			"valid",
+			PointerConstraintTestType{
+				String: str("hello"),
+				Int:    num(1),
+				Time:   tm(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+				Slice:  slice(1, 2),
+			},
+			true,
+		},
+		{"long string", PointerConstraintTestType{String: str("hello!")}, false},
+		{"non alpha string", PointerConstraintTestType{String: str("h3llo")}, false},
+		{"small int", PointerConstraintTestType{Int: num(0)}, false},
+		{"early time", PointerConstraintTestType{Time: tm(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))}, false},
+		{"long slice", PointerConstraintTestType{Slice: slice(1, 2, 3)}, false},
+		{"empty string", PointerConstraintTestType{Nil: str("")}, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			assertConstraints(t, &input, tt.ok)
+		})
+	}
+}
+
+func TestPointerRefsConstraints(t *testing.T) {
+	s, err := Schema(Options{
+		Types:           []string{"PointerRefsConstraintTestType"},
+		TrackReferences: true,
+	})
+	require.NoError(t, err)
+
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	testCases := []struct {
+		name  string
+		input PointerRefsConstraintTestType
+		err   string
+	}{
+		{"valid", PointerRefsConstraintTestType{A: str("foo"), B: str("bar"), C: num(3)}, ""},
+		{"long value", PointerRefsConstraintTestType{B: str("hello"), C: num(3)}, "field 'B' has a maximum length of 3"},
+		{"long reference", func() PointerRefsConstraintTestType {
+			s := str("hello")
+			return PointerRefsConstraintTestType{A: s, B: s}
+		}(), "field 'B' has a maximum length of 3"},
+		{"big value", PointerRefsConstraintTestType{C: num(4)}, "field 'C' has a maximum value of 3"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			bs, err := tt.input.EncodeBinary()
+			require.NoError(t, err)
+
+			var result PointerRefsConstraintTestType
+			err = result.DecodeBinaryFromBytes(bs)
+			_, dynErr := dynamic.BinaryToJSON(s, "PointerRefsConstraintTestType", bs)
+			refErr := UnmarshalWithOptions(bs, new(PointerRefsConstraintTestType), ReflectOptions{TrackReferences: true})
+			if tt.err == "" {
+				require.NoError(t, err)
+				require.NoError(t, dynErr)
+				require.NoError(t, refErr)
+				return
+			}
+
+			require.EqualError(t, err, tt.err)
+			require.IsType(t, &dynamic.ConstraintError{}, dynErr)
+			require.Equal(t, tt.err, dynErr.(*dynamic.ConstraintError).Message)
+			require.EqualError(t, refErr, tt.err)
+		})
+	}
 }
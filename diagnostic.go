@@ -0,0 +1,82 @@
+package bindec
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Diagnostic is a problem in the declaration of a type that prevents
+// generating its code, such as a field of an unsupported type or an invalid
+// struct tag.
+type Diagnostic struct {
+	// Pos is the position of the field or the struct tag with the problem,
+	// or of the type if the problem is not in any of its fields.
+	Pos token.Position `json:"pos"`
+	// Type is the name of the type being generated.
+	Type string `json:"type,omitempty"`
+	// Field is the path of the field with the problem from the type being
+	// generated, if any.
+	Field string `json:"field,omitempty"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (d Diagnostic) Error() string {
+	var prefix string
+	if d.Pos.IsValid() {
+		prefix = d.Pos.String() + ": "
+	}
+
+	if d.Field != "" {
+		return fmt.Sprintf("%s%s: on field %s: %s", prefix, d.Type, d.Field, d.Message)
+	}
+	return fmt.Sprintf("%s%s: %s", prefix, d.Type, d.Message)
+}
+
+// Diagnostics are all the problems found generating a set of types. It's
+// the error returned when the declarations of the types have problems.
+type Diagnostics []Diagnostic
+
+// Error implements the error interface.
+func (d Diagnostics) Error() string {
+	var lines = make([]string, len(d))
+	for i, diag := range d {
+		lines[i] = diag.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fieldDiagnostics returns the diagnostics of an error found parsing the
+// field f at the given position, which can be the field or its tag. If the
+// error already contains the diagnostics of the fields of the field, they
+// are returned instead.
+func fieldDiagnostics(ctx *parseContext, f *types.Var, pos token.Pos, err error) Diagnostics {
+	var diags Diagnostics
+	if errors.As(err, &diags) {
+		return diags
+	}
+
+	return Diagnostics{{
+		Pos:     ctx.pkg.fset.Position(pos),
+		Field:   fieldPath(ctx.path, f.Name()),
+		Message: err.Error(),
+	}}
+}
+
+// typeDiagnostics returns the diagnostics of an error found parsing the
+// type with the given name, which is declared at the given position.
+func typeDiagnostics(pkg *packageInfo, name string, pos token.Pos, err error) Diagnostics {
+	var diags Diagnostics
+	if !errors.As(err, &diags) {
+		diags = Diagnostics{{Pos: pkg.fset.Position(pos), Message: err.Error()}}
+	}
+
+	for i := range diags {
+		diags[i].Type = name
+	}
+	return diags
+}
@@ -0,0 +1,30 @@
+package bindec
+
+import (
+	"go/token"
+	"testing"
+)
+
+func TestDiagnosticError(t *testing.T) {
+	pos := token.Position{Filename: "foo.go", Line: 3, Column: 2}
+	testCases := []struct {
+		diag     Diagnostic
+		expected string
+	}{
+		{Diagnostic{pos, "Foo", "Bar.Baz", "invalid"}, "foo.go:3:2: Foo: on field Bar.Baz: invalid"},
+		{Diagnostic{pos, "Foo", "", "invalid"}, "foo.go:3:2: Foo: invalid"},
+		{Diagnostic{token.Position{}, "Foo", "", "invalid"}, "Foo: invalid"},
+	}
+
+	for _, tt := range testCases {
+		if err := tt.diag.Error(); err != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, err)
+		}
+	}
+
+	diags := Diagnostics{testCases[0].diag, testCases[1].diag}
+	expected := "foo.go:3:2: Foo: on field Bar.Baz: invalid\nfoo.go:3:2: Foo: invalid"
+	if err := diags.Error(); err != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+}
@@ -0,0 +1,186 @@
+package dynamic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erizocosmico/bindec/internal/validate"
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// ConstraintError is a value of a field that does not satisfy one of the
+// constraints of the field.
+type ConstraintError struct {
+	// Path of the field from the checked value, such as "Items[2].Name".
+	Path string
+	// Constraint that is not satisfied.
+	Constraint schema.Constraint
+	// Message describes the problem.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Check returns a *ConstraintError if any of the values of fields contained
+// in the given value does not satisfy the constraints of its field, in the
+// same order and with the same messages as the generated decoders.
+// Constraints of fields that are pointers are checked on the values they
+// point to, if any, including the values referenced by maybes with
+// reference tracking.
+func Check(v *Value) error {
+	c := &checker{refs: make(map[uint64]*Value)}
+	return c.check(v, "")
+}
+
+type checker struct {
+	// refs are the values of maybes with reference tracking by ID.
+	refs map[uint64]*Value
+}
+
+func (c *checker) check(v *Value, path string) error {
+	switch v.Type.Kind {
+	case schema.Maybe:
+		if v.Type.Refs && !v.Ref && len(v.Elems) > 0 {
+			c.refs[v.ID] = v.Elems[0]
+		}
+	case schema.Delegate:
+		// The layout of delegates has its own references.
+		refs := c.refs
+		c.refs = make(map[uint64]*Value)
+		defer func() { c.refs = refs }()
+	}
+
+	for i, f := range v.Fields {
+		fpath := fieldPath(path, f.Name)
+		var cs []schema.Constraint
+		if i < len(v.Type.Fields) {
+			cs = v.Type.Fields[i].Constraints
+		}
+
+		// Lengths are checked before reading the contents of the field, and
+		// the rest of constraints after.
+		if err := c.checkField(f.Value, cs, f.Name, fpath, true); err != nil {
+			return err
+		}
+
+		if err := c.check(f.Value, fpath); err != nil {
+			return err
+		}
+
+		if err := c.checkField(f.Value, cs, f.Name, fpath, false); err != nil {
+			return err
+		}
+	}
+
+	for i, k := range v.Keys {
+		if err := c.check(k, fmt.Sprintf("%s[key %d]", path, i)); err != nil {
+			return err
+		}
+	}
+
+	for i, e := range v.Elems {
+		epath := path
+		if k := v.Type.Kind; k == schema.Array || k == schema.Slice || k == schema.Map {
+			epath = fmt.Sprintf("%s[%d]", path, i)
+		}
+
+		if err := c.check(e, epath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkField returns an error if the value of the field with the given name
+// does not satisfy one of the constraints checked with its length, if
+// length is true, or one of the rest, otherwise.
+func (c *checker) checkField(v *Value, cs []schema.Constraint, name, path string, length bool) error {
+	if len(cs) == 0 {
+		return nil
+	}
+
+	if v.Type.Kind == schema.Maybe {
+		switch {
+		case v.Ref:
+			if v = c.refs[v.ID]; v == nil {
+				return nil
+			}
+		case len(v.Elems) == 0:
+			return nil
+		default:
+			v = v.Elems[0]
+		}
+	}
+
+	// Fields promoted from flattened embedded structs are named after their
+	// path, but the generated code only uses their name.
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	for _, sc := range cs {
+		cc, err := validate.Compile(sc.Name, sc.Args, name, constraintKind(v.Type))
+		if err != nil {
+			return &ConstraintError{path, sc, fmt.Sprintf("invalid constraint %s: %s", sc.Name, err)}
+		}
+
+		if cc.Len != length {
+			continue
+		}
+
+		if length {
+			err = cc.CheckLen(valueLen(v))
+		} else {
+			err = cc.Check(v.Data)
+		}
+
+		if err != nil {
+			return &ConstraintError{path, sc, err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// constraintKind returns the kind of values of the given type constraints
+// are checked on.
+func constraintKind(t *schema.Type) validate.Kind {
+	switch t.Kind {
+	case schema.Bool:
+		return validate.Bool
+	case schema.Int8, schema.Int16, schema.Int32, schema.Int64, schema.Int:
+		return validate.Int
+	case schema.Uint8, schema.Uint16, schema.Uint32, schema.Uint64, schema.Uint, schema.Uintptr:
+		return validate.Uint
+	case schema.Float32:
+		return validate.Float32
+	case schema.Float64:
+		return validate.Float64
+	case schema.String:
+		return validate.String
+	case schema.Bytes, schema.Slice:
+		return validate.Slice
+	case schema.Time:
+		return validate.Time
+	case schema.Duration:
+		return validate.Duration
+	default:
+		return validate.Invalid
+	}
+}
+
+// valueLen returns the length of strings, bytes and slices.
+func valueLen(v *Value) int {
+	switch data := v.Data.(type) {
+	case string:
+		return len(data)
+	case []byte:
+		return len(data)
+	default:
+		return len(v.Elems)
+	}
+}
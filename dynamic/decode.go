@@ -0,0 +1,526 @@
+package dynamic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// Error is an error decoding a value.
+type Error struct {
+	// Offset is the position in the data of the value that could not be
+	// decoded.
+	Offset int
+	// Path of the value from the decoded type, such as "Items[2].Name", or
+	// empty if it's the type itself.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("offset %d: %s", e.Offset, e.Message)
+	}
+	return fmt.Sprintf("offset %d: %s: %s", e.Offset, e.Path, e.Message)
+}
+
+// maxEmptyElems is the maximum number of elements of slices and maps whose
+// elements may be encoded with no bytes, so invalid data can't make the
+// decoder allocate an unbounded number of them.
+const maxEmptyElems = 1 << 20
+
+// Decode decodes the data encoded by the type with the given name of the
+// schema. If the data is invalid, the part of the value decoded before the
+// problem was found is returned along with an *Error. Bytes left after the
+// value are not decoded, and the size of the value reports how many of them
+// were used.
+func Decode(s *schema.Schema, typ string, data []byte) (*Value, error) {
+	named := Lookup(s, typ)
+	if named == nil {
+		return nil, fmt.Errorf("type %s not found in the schema of package %s", typ, s.Package)
+	}
+
+	return DecodeNamed(named, data)
+}
+
+// DecodeNamed decodes the data encoded by the given type, as Decode does.
+func DecodeNamed(named *schema.Named, data []byte) (*Value, error) {
+	d := &decoder{named: named, data: data}
+	v := new(Value)
+	err := d.decodeRoot(named.Type, v, "")
+	return v, err
+}
+
+// Lookup returns the type of the schema with the given name, or nil if
+// there is none.
+func Lookup(s *schema.Schema, name string) *schema.Named {
+	for i := range s.Types {
+		if s.Types[i].Name == name {
+			return &s.Types[i]
+		}
+	}
+	return nil
+}
+
+type decoder struct {
+	named *schema.Named
+	data  []byte
+	off   int
+	// refs are the values of maybes with reference tracking by ID.
+	refs  []*Value
+	depth int
+}
+
+func (d *decoder) errorf(off int, path string, format string, args ...interface{}) error {
+	return &Error{off, path, fmt.Sprintf(format, args...)}
+}
+
+func (d *decoder) read(n int, path string) ([]byte, error) {
+	if n > len(d.data)-d.off {
+		return nil, d.errorf(
+			d.off, path,
+			"unexpected end of data, expecting %d bytes but only %d are left",
+			n, len(d.data)-d.off,
+		)
+	}
+
+	b := d.data[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+// readLen reads a length prefix, which is encoded like an int64.
+func (d *decoder) readLen(path string) (int, error) {
+	off := d.off
+	b, err := d.read(8, path)
+	if err != nil {
+		return 0, err
+	}
+
+	n := zigzag64(binary.LittleEndian.Uint64(b))
+	if n < 0 || n > math.MaxInt32 {
+		return 0, d.errorf(off, path, "invalid length %d", n)
+	}
+	return int(n), nil
+}
+
+func (d *decoder) decode(t *schema.Type, v *Value, path string) error {
+	v.Type = t
+	v.Offset = d.off
+	err := d.decodeValue(t, v, path)
+	v.Size = d.off - v.Offset
+	return err
+}
+
+// decodeRoot decodes the value of a named type. Data encoded before maybe
+// fields were appended to a struct ends before them, so they are nil if
+// there is nothing left, just like in the generated code. The first field is
+// never missing, so empty data still fails.
+func (d *decoder) decodeRoot(t *schema.Type, v *Value, path string) error {
+	if t.Kind != schema.Struct {
+		return d.decode(t, v, path)
+	}
+
+	optional := len(t.Fields)
+	for optional > 1 && t.Fields[optional-1].Type.Kind == schema.Maybe {
+		optional--
+	}
+
+	v.Type = t
+	v.Offset = d.off
+	defer func() { v.Size = d.off - v.Offset }()
+	for i, f := range t.Fields {
+		fv := new(Value)
+		v.Fields = append(v.Fields, Field{f.Name, fv})
+		if i >= optional && d.off == len(d.data) {
+			fv.Type, fv.Offset = f.Type, d.off
+			continue
+		}
+
+		if err := d.decode(f.Type, fv, fieldPath(path, f.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeValue(t *schema.Type, v *Value, path string) error {
+	if size, ok := basicSizes[t.Kind]; ok {
+		b, err := d.read(size, path)
+		if err != nil {
+			return err
+		}
+		v.Data = decodeBasic(t.Kind, b)
+		return nil
+	}
+
+	switch t.Kind {
+	case schema.String, schema.Bytes, schema.Marshaler:
+		n, err := d.readLen(path)
+		if err != nil {
+			return err
+		}
+
+		b, err := d.read(n, path)
+		if err != nil {
+			return err
+		}
+
+		if t.Kind == schema.String {
+			v.Data = string(b)
+		} else {
+			v.Data = append([]byte(nil), b...)
+		}
+	case schema.Time:
+		tm, err := d.decodeTime(path)
+		if err != nil {
+			return err
+		}
+		v.Data = tm
+	case schema.Array:
+		v.Elems = make([]*Value, 0, int(min64(t.Len, maxEmptyElems)))
+		for i := int64(0); i < t.Len; i++ {
+			elem := new(Value)
+			v.Elems = append(v.Elems, elem)
+			if err := d.decode(t.Elem, elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case schema.Slice, schema.Map:
+		off := d.off
+		n, err := d.readLen(path)
+		if err != nil {
+			return err
+		}
+
+		size := d.minSize(t.Elem, nil)
+		if t.Kind == schema.Map {
+			size += d.minSize(t.Key, nil)
+		}
+
+		if (size > 0 && n > (len(d.data)-d.off)/size) || (size == 0 && n > maxEmptyElems) {
+			return d.errorf(off, path, "invalid length %d, there are only %d bytes left", n, len(d.data)-d.off)
+		}
+
+		for i := 0; i < n; i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if t.Kind == schema.Map {
+				key := new(Value)
+				v.Keys = append(v.Keys, key)
+				if err := d.decode(t.Key, key, fmt.Sprintf("%s[key %d]", path, i)); err != nil {
+					return err
+				}
+			}
+
+			elem := new(Value)
+			v.Elems = append(v.Elems, elem)
+			if err := d.decode(t.Elem, elem, elemPath); err != nil {
+				return err
+			}
+		}
+	case schema.Struct:
+		for _, f := range t.Fields {
+			fv := new(Value)
+			v.Fields = append(v.Fields, Field{f.Name, fv})
+			if err := d.decode(f.Type, fv, fieldPath(path, f.Name)); err != nil {
+				return err
+			}
+		}
+	case schema.Maybe:
+		return d.decodeMaybe(t, v, path)
+	case schema.Union:
+		b, err := d.read(1, path)
+		if err != nil {
+			return err
+		}
+
+		if b[0] == 0 {
+			return nil
+		}
+
+		for _, variant := range t.Variants {
+			if variant.Tag != int(b[0]) {
+				continue
+			}
+
+			v.Tag = variant.Tag
+			v.Variant = variant.Name
+			elem := new(Value)
+			v.Elems = []*Value{elem}
+			return d.decode(variant.Type, elem, path)
+		}
+
+		return d.errorf(v.Offset, path, "invalid union tag %d, there are %d types", b[0], len(t.Variants))
+	case schema.Ref:
+		def, ok := d.named.Definitions[t.Name]
+		if !ok {
+			return d.errorf(d.off, path, "definition of type %s not found", t.Name)
+		}
+
+		if d.depth >= d.named.MaxDepth {
+			return d.errorf(d.off, path, "maximum decoding depth of %d exceeded", d.named.MaxDepth)
+		}
+
+		// The value has the layout of the definition, as if it had been
+		// inlined.
+		v.Type = def
+		d.depth++
+		defer func() { d.depth-- }()
+		return d.decodeValue(def, v, path)
+	case schema.Delegate:
+		return d.decodeDelegate(t, v, path)
+	case schema.TypeParam:
+		return d.errorf(d.off, path, "type parameter %s can't be decoded without its type argument", t.Name)
+	default:
+		return d.errorf(d.off, path, "unknown kind of type %q", t.Kind)
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeMaybe(t *schema.Type, v *Value, path string) error {
+	b, err := d.read(1, path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case b[0] == 0:
+		return nil
+	case b[0] == 1:
+		elem := new(Value)
+		v.Elems = []*Value{elem}
+		if t.Refs {
+			v.ID = uint64(len(d.refs))
+			d.refs = append(d.refs, elem)
+		}
+		return d.decode(t.Elem, elem, path)
+	case b[0] == 2 && t.Refs:
+		b, err := d.read(8, path)
+		if err != nil {
+			return err
+		}
+
+		id := binary.LittleEndian.Uint64(b)
+		if id >= uint64(len(d.refs)) {
+			return d.errorf(v.Offset, path, "invalid reference %d, only %d values were decoded", id, len(d.refs))
+		}
+
+		v.ID = id
+		v.Ref = true
+		return nil
+	default:
+		return d.errorf(v.Offset, path, "invalid maybe kind %d", b[0])
+	}
+}
+
+// decodeDelegate decodes the value of a delegate with its layout, which
+// has its own definitions, references and depth, just like the methods of
+// the type decode it.
+func (d *decoder) decodeDelegate(t *schema.Type, v *Value, path string) error {
+	if t.Layout == nil {
+		return d.errorf(d.off, path, "type %s has its own methods and its layout is unknown", t.Name)
+	}
+
+	named, refs, depth := d.named, d.refs, d.depth
+	d.named, d.refs, d.depth = t.Layout, nil, 0
+	defer func() { d.named, d.refs, d.depth = named, refs, depth }()
+
+	elem := new(Value)
+	v.Elems = []*Value{elem}
+	return d.decodeRoot(t.Layout.Type, elem, path)
+}
+
+func (d *decoder) decodeTime(path string) (time.Time, error) {
+	b, err := d.read(13, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	sec := zigzag64(binary.LittleEndian.Uint64(b))
+	nsec := int64(binary.LittleEndian.Uint32(b[8:]))
+	tm := time.Unix(sec, nsec)
+	switch b[12] {
+	case 0:
+		return tm.UTC(), nil
+	case 1:
+		return tm.Local(), nil
+	case 2:
+		zone, err := d.read(4, path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		offset := int(zigzag32(binary.LittleEndian.Uint32(zone)))
+		n, err := d.readLen(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		name, err := d.read(n, path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return inZone(tm, string(name), offset), nil
+	default:
+		return time.Time{}, d.errorf(d.off-1, path, "invalid time zone kind %d", b[12])
+	}
+}
+
+// inZone returns the time in the zone with the given name and offset,
+// resolving its location just like the generated code does.
+func inZone(tm time.Time, name string, offset int) time.Time {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.FixedZone(name, offset)
+	} else if _, off := tm.In(loc).Zone(); off != offset {
+		loc = time.FixedZone(name, offset)
+	}
+	return tm.In(loc)
+}
+
+// minSize returns the minimum number of bytes a value of the given type is
+// encoded with. Definitions being visited are assumed to have no size.
+func (d *decoder) minSize(t *schema.Type, visiting map[string]bool) int {
+	if size, ok := basicSizes[t.Kind]; ok {
+		return size
+	}
+
+	switch t.Kind {
+	case schema.String, schema.Bytes, schema.Marshaler, schema.Slice, schema.Map:
+		return 8
+	case schema.Time:
+		return 13
+	case schema.Maybe, schema.Union:
+		return 1
+	case schema.Array:
+		if t.Len > maxEmptyElems {
+			return maxEmptyElems
+		}
+		return int(t.Len) * d.minSize(t.Elem, visiting)
+	case schema.Struct:
+		var size int
+		for _, f := range t.Fields {
+			size += d.minSize(f.Type, visiting)
+		}
+		return size
+	case schema.Ref:
+		def, ok := d.named.Definitions[t.Name]
+		if !ok || visiting[t.Name] {
+			return 0
+		}
+
+		if visiting == nil {
+			visiting = make(map[string]bool)
+		}
+		visiting[t.Name] = true
+		defer delete(visiting, t.Name)
+		return d.minSize(def, visiting)
+	case schema.Delegate:
+		if t.Layout == nil {
+			return 0
+		}
+
+		named := d.named
+		d.named = t.Layout
+		defer func() { d.named = named }()
+		return d.minSize(t.Layout.Type, nil)
+	default:
+		return 0
+	}
+}
+
+// basicSizes are the sizes of the kinds of types encoded as a number.
+var basicSizes = map[schema.Kind]int{
+	schema.Bool:       1,
+	schema.Int8:       1,
+	schema.Int16:      2,
+	schema.Int32:      4,
+	schema.Int64:      8,
+	schema.Int:        8,
+	schema.Uint8:      1,
+	schema.Uint16:     2,
+	schema.Uint32:     4,
+	schema.Uint64:     8,
+	schema.Uint:       8,
+	schema.Uintptr:    8,
+	schema.Float32:    4,
+	schema.Float64:    8,
+	schema.Complex64:  8,
+	schema.Complex128: 16,
+	schema.Duration:   8,
+}
+
+func decodeBasic(kind schema.Kind, b []byte) interface{} {
+	switch kind {
+	case schema.Bool:
+		return b[0] == 1
+	case schema.Int8:
+		return int64(int8(b[0]>>1) ^ -int8(b[0]&1))
+	case schema.Int16:
+		ux := binary.LittleEndian.Uint16(b)
+		return int64(int16(ux>>1) ^ -int16(ux&1))
+	case schema.Int32:
+		return int64(zigzag32(binary.LittleEndian.Uint32(b)))
+	case schema.Int64, schema.Int:
+		return zigzag64(binary.LittleEndian.Uint64(b))
+	case schema.Duration:
+		return time.Duration(zigzag64(binary.LittleEndian.Uint64(b)))
+	case schema.Uint8:
+		return uint64(b[0])
+	case schema.Uint16:
+		return uint64(binary.LittleEndian.Uint16(b))
+	case schema.Uint32:
+		return uint64(binary.LittleEndian.Uint32(b))
+	case schema.Uint64, schema.Uint, schema.Uintptr:
+		return binary.LittleEndian.Uint64(b)
+	case schema.Float32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case schema.Float64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case schema.Complex64:
+		re := math.Float32frombits(binary.LittleEndian.Uint32(b))
+		im := math.Float32frombits(binary.LittleEndian.Uint32(b[4:]))
+		return complex128(complex(re, im))
+	default:
+		re := math.Float64frombits(binary.LittleEndian.Uint64(b))
+		im := math.Float64frombits(binary.LittleEndian.Uint64(b[8:]))
+		return complex(re, im)
+	}
+}
+
+func zigzag64(ux uint64) int64 {
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+	return x
+}
+
+func zigzag32(ux uint32) int32 {
+	x := int32(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+	return x
+}
+
+func fieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
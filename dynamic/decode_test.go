@@ -0,0 +1,175 @@
+package dynamic
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/erizocosmico/bindec/schema"
+)
+
+var (
+	int16Type  = &schema.Type{Kind: schema.Int16, Size: 2}
+	stringType = &schema.Type{Kind: schema.String, Prefix: 8}
+	testSchema = &schema.Schema{
+		Version: schema.Version,
+		Package: "foo",
+		Types: []schema.Named{
+			{
+				Name: "Foo",
+				Type: &schema.Type{Kind: schema.Struct, Fields: []schema.Field{
+					{Name: "N", Type: int16Type},
+					{Name: "S", Type: stringType},
+					{Name: "L", Type: &schema.Type{Kind: schema.Slice, Prefix: 8, Elem: int16Type}},
+					{Name: "U", Type: &schema.Type{Kind: schema.Union, Variants: []schema.Variant{
+						{Tag: 1, Name: "Bar", Type: stringType},
+					}}},
+				}},
+				MaxDepth: 10,
+			},
+			{
+				Name: "List",
+				Type: &schema.Type{Kind: schema.Ref, Name: "List"},
+				Definitions: map[string]*schema.Type{
+					"List": {Kind: schema.Maybe, Elem: &schema.Type{Kind: schema.Ref, Name: "List"}},
+				},
+				MaxDepth: 2,
+			},
+			{Name: "Time", Type: &schema.Type{Kind: schema.Time}},
+		},
+	}
+)
+
+// fooData is a Foo with N -3, S "hi", L [1, 2] and U Bar("x").
+var fooData = []byte{
+	5, 0,
+	4, 0, 0, 0, 0, 0, 0, 0, 'h', 'i',
+	4, 0, 0, 0, 0, 0, 0, 0, 2, 0, 4, 0,
+	1, 2, 0, 0, 0, 0, 0, 0, 0, 'x',
+}
+
+func TestDecode(t *testing.T) {
+	v, err := Decode(testSchema, "Foo", fooData)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if v.Size != len(fooData) || len(v.Fields) != 4 {
+		t.Fatalf("unexpected value: %+v", v)
+	}
+
+	if n := v.Fields[0].Value; n.Data != int64(-3) || n.Offset != 0 || n.Size != 2 {
+		t.Errorf("unexpected N: %+v", n)
+	}
+
+	if s := v.Fields[1].Value; s.Data != "hi" || s.Offset != 2 || s.Size != 10 {
+		t.Errorf("unexpected S: %+v", s)
+	}
+
+	l := v.Fields[2].Value
+	if len(l.Elems) != 2 || l.Elems[1].Data != int64(2) || l.Elems[1].Offset != 22 {
+		t.Errorf("unexpected L: %+v", l)
+	}
+
+	u := v.Fields[3].Value
+	if u.Tag != 1 || u.Variant != "Bar" || u.Elems[0].Data != "x" || u.Elems[0].Offset != 25 {
+		t.Errorf("unexpected U: %+v", u)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		typ  string
+		data []byte
+		err  string
+	}{
+		{"truncated", "Foo", fooData[:5], "offset 2: S: unexpected end of data, expecting 8 bytes but only 3 are left"},
+		{"negative length", "Foo", append([]byte{5, 0, 3}, make([]byte, 7)...), "offset 2: S: invalid length -2"},
+		{"long slice", "Foo", append(append([]byte{}, fooData[:12]...), 8, 0, 0, 0, 0, 0, 0, 0, 1, 0), "offset 12: L: invalid length 4, there are only 2 bytes left"},
+		{"invalid union tag", "Foo", append(append([]byte{}, fooData[:24]...), 2), "offset 24: U: invalid union tag 2, there are 1 types"},
+		{"invalid maybe", "List", []byte{1, 3}, "offset 1: invalid maybe kind 3"},
+		{"max depth", "List", []byte{1, 1, 0}, "offset 2: maximum decoding depth of 2 exceeded"},
+		{"unknown type", "Bar", nil, "type Bar not found in the schema of package foo"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(testSchema, tt.typ, tt.data)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected error %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDecodePartial(t *testing.T) {
+	v, err := Decode(testSchema, "Foo", fooData[:len(fooData)-1])
+	if _, ok := err.(*Error); !ok {
+		t.Fatalf("expected decoding error, got: %v", err)
+	}
+
+	if len(v.Fields) != 4 || len(v.Fields[2].Value.Elems) != 2 || v.Fields[3].Value.Variant != "Bar" {
+		t.Errorf("expected value decoded until the error, got: %+v", v)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	v, err := Decode(testSchema, "Foo", fooData)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, "Foo", v); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `  OFFSET     SIZE  VALUE
+       0       34  Foo: struct
+       0        2    N: int16 = -3
+       2       10    S: string = "hi"
+      12       12    L: slice, 2 elements
+      20        2      [0]: int16 = 1
+      22        2      [1]: int16 = 2
+      24       10    U: union, tag 1
+      25        9      (Bar): string = "x"
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	v, err := Decode(testSchema, "List", []byte{1, 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `{"kind":"maybe","offset":0,"size":2,"elems":[{"kind":"maybe","offset":1,"size":1,"null":true}]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	v, err = Decode(testSchema, "Foo", fooData)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err = json.Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, s := range []string{`"name":"N","kind":"int16","offset":0,"size":2,"value":-3`, `"tag":1,"variant":"Bar"`} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("expected %s to contain %s", data, s)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package dynamic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// Encode encodes the value with the layout of its type, just as the
+// generated code encodes the Go value it represents. The offsets and sizes
+// of the value are ignored. Values of maybes with reference tracking are
+// given IDs in the order they are encoded, and references must have the ID
+// of one of the values encoded before them.
+func Encode(v *Value) ([]byte, error) {
+	e := new(encoder)
+	if err := e.encode(v, ""); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+type encoder struct {
+	buf []byte
+	// ids is the number of values of maybes with reference tracking
+	// encoded so far.
+	ids uint64
+}
+
+func (e *encoder) errorf(path string, format string, args ...interface{}) error {
+	return &Error{len(e.buf), path, fmt.Sprintf(format, args...)}
+}
+
+func (e *encoder) uint64(x uint64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, x)
+}
+
+func (e *encoder) uint32(x uint32) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, x)
+}
+
+func (e *encoder) int64(x int64) {
+	ux := uint64(x) << 1
+	if x < 0 {
+		ux = ^ux
+	}
+	e.uint64(ux)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.int64(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) encode(v *Value, path string) error {
+	t := v.Type
+	if t == nil {
+		return e.errorf(path, "value has no type")
+	}
+
+	if _, ok := basicSizes[t.Kind]; ok {
+		return e.encodeBasic(v, path)
+	}
+
+	switch t.Kind {
+	case schema.String:
+		s, ok := v.Data.(string)
+		if !ok {
+			return e.invalidData(v, path)
+		}
+		e.bytes([]byte(s))
+	case schema.Bytes, schema.Marshaler:
+		b, ok := v.Data.([]byte)
+		if !ok && v.Data != nil {
+			return e.invalidData(v, path)
+		}
+		e.bytes(b)
+	case schema.Time:
+		tm, ok := v.Data.(time.Time)
+		if !ok {
+			return e.invalidData(v, path)
+		}
+		e.encodeTime(tm)
+	case schema.Array, schema.Slice:
+		if t.Kind == schema.Array && int64(len(v.Elems)) != t.Len {
+			return e.errorf(path, "array of %d elements has %d", t.Len, len(v.Elems))
+		}
+
+		if t.Kind == schema.Slice {
+			e.int64(int64(len(v.Elems)))
+		}
+
+		for i, elem := range v.Elems {
+			if err := e.encode(elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case schema.Map:
+		if len(v.Keys) != len(v.Elems) {
+			return e.errorf(path, "map has %d keys and %d values", len(v.Keys), len(v.Elems))
+		}
+
+		e.int64(int64(len(v.Elems)))
+		for i, elem := range v.Elems {
+			if err := e.encode(v.Keys[i], fmt.Sprintf("%s[key %d]", path, i)); err != nil {
+				return err
+			}
+
+			if err := e.encode(elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case schema.Struct:
+		if len(v.Fields) != len(t.Fields) {
+			return e.errorf(path, "struct of %d fields has %d", len(t.Fields), len(v.Fields))
+		}
+
+		for _, f := range v.Fields {
+			if err := e.encode(f.Value, fieldPath(path, f.Name)); err != nil {
+				return err
+			}
+		}
+	case schema.Maybe:
+		switch {
+		case v.Ref:
+			if !t.Refs {
+				return e.errorf(path, "reference in a maybe without reference tracking")
+			}
+
+			if v.ID >= e.ids {
+				return e.errorf(path, "invalid reference %d, only %d values were encoded", v.ID, e.ids)
+			}
+
+			e.buf = append(e.buf, 2)
+			e.uint64(v.ID)
+		case len(v.Elems) == 0:
+			e.buf = append(e.buf, 0)
+		default:
+			e.buf = append(e.buf, 1)
+			if t.Refs {
+				e.ids++
+			}
+			return e.encode(v.Elems[0], path)
+		}
+	case schema.Union:
+		if len(v.Elems) == 0 {
+			e.buf = append(e.buf, 0)
+			return nil
+		}
+
+		if v.Tag <= 0 || v.Tag > math.MaxUint8 {
+			return e.errorf(path, "invalid union tag %d", v.Tag)
+		}
+
+		e.buf = append(e.buf, byte(v.Tag))
+		return e.encode(v.Elems[0], path)
+	case schema.Delegate:
+		if len(v.Elems) != 1 {
+			return e.errorf(path, "delegate has %d values", len(v.Elems))
+		}
+
+		// The methods of the type give IDs to the values they encode
+		// starting from zero.
+		ids := e.ids
+		e.ids = 0
+		defer func() { e.ids = ids }()
+		return e.encode(v.Elems[0], path)
+	default:
+		return e.errorf(path, "values of kind %s can't be encoded", t.Kind)
+	}
+
+	return nil
+}
+
+func (e *encoder) invalidData(v *Value, path string) error {
+	return e.errorf(path, "invalid data of type %T for a value of kind %s", v.Data, v.Type.Kind)
+}
+
+func (e *encoder) encodeBasic(v *Value, path string) error {
+	var ok bool
+	switch v.Type.Kind {
+	case schema.Bool:
+		var b bool
+		if b, ok = v.Data.(bool); ok {
+			if b {
+				e.buf = append(e.buf, 1)
+			} else {
+				e.buf = append(e.buf, 0)
+			}
+		}
+	case schema.Int8, schema.Int16, schema.Int32, schema.Int64, schema.Int:
+		var x int64
+		if x, ok = v.Data.(int64); ok {
+			size := basicSizes[v.Type.Kind]
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			e.buf = append(e.buf, binary.LittleEndian.AppendUint64(nil, ux)[:size]...)
+		}
+	case schema.Duration:
+		var d time.Duration
+		if d, ok = v.Data.(time.Duration); ok {
+			e.int64(int64(d))
+		}
+	case schema.Uint8, schema.Uint16, schema.Uint32, schema.Uint64, schema.Uint, schema.Uintptr:
+		var x uint64
+		if x, ok = v.Data.(uint64); ok {
+			size := basicSizes[v.Type.Kind]
+			e.buf = append(e.buf, binary.LittleEndian.AppendUint64(nil, x)[:size]...)
+		}
+	case schema.Float32:
+		var f float64
+		if f, ok = v.Data.(float64); ok {
+			e.uint32(math.Float32bits(float32(f)))
+		}
+	case schema.Float64:
+		var f float64
+		if f, ok = v.Data.(float64); ok {
+			e.uint64(math.Float64bits(f))
+		}
+	case schema.Complex64:
+		var c complex128
+		if c, ok = v.Data.(complex128); ok {
+			e.uint32(math.Float32bits(float32(real(c))))
+			e.uint32(math.Float32bits(float32(imag(c))))
+		}
+	case schema.Complex128:
+		var c complex128
+		if c, ok = v.Data.(complex128); ok {
+			e.uint64(math.Float64bits(real(c)))
+			e.uint64(math.Float64bits(imag(c)))
+		}
+	}
+
+	if !ok {
+		return e.invalidData(v, path)
+	}
+	return nil
+}
+
+// encodeTime encodes a time like the generated code does.
+func (e *encoder) encodeTime(tm time.Time) {
+	e.int64(tm.Unix())
+	e.uint32(uint32(tm.Nanosecond()))
+
+	switch tm.Location() {
+	case time.UTC:
+		e.buf = append(e.buf, 0)
+	case time.Local:
+		e.buf = append(e.buf, 1)
+	default:
+		e.buf = append(e.buf, 2)
+		_, offset := tm.Zone()
+		uoffset := uint32(int32(offset)) << 1
+		if offset < 0 {
+			uoffset = ^uoffset
+		}
+		e.uint32(uoffset)
+		e.bytes([]byte(tm.Location().String()))
+	}
+}
@@ -0,0 +1,183 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// WriteText writes the value to the given writer as text, one line for each
+// value it contains with its offset and size in bytes, indented by depth.
+// The value itself is labeled with the given name.
+func WriteText(w io.Writer, name string, v *Value) error {
+	if _, err := fmt.Fprintf(w, "%8s %8s  %s\n", "OFFSET", "SIZE", "VALUE"); err != nil {
+		return err
+	}
+	return writeText(w, name, v, 0)
+}
+
+func writeText(w io.Writer, label string, v *Value, depth int) error {
+	_, err := fmt.Fprintf(
+		w, "%8d %8d  %s%s: %s\n",
+		v.Offset, v.Size, strings.Repeat("  ", depth), label, describe(v),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range v.Fields {
+		if err := writeText(w, f.Name, f.Value, depth+1); err != nil {
+			return err
+		}
+	}
+
+	for i, elem := range v.Elems {
+		if i < len(v.Keys) {
+			if err := writeText(w, fmt.Sprintf("[key %d]", i), v.Keys[i], depth+1); err != nil {
+				return err
+			}
+		}
+
+		label := fmt.Sprintf("[%d]", i)
+		switch v.Type.Kind {
+		case schema.Maybe:
+			label = "*"
+		case schema.Union:
+			label = "(" + v.Variant + ")"
+		case schema.Delegate:
+			label = "(" + v.Type.Name + ")"
+		}
+
+		if err := writeText(w, label, elem, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// describe returns a description of the value without the values it
+// contains.
+func describe(v *Value) string {
+	kind := v.Type.Kind
+	switch kind {
+	case schema.Struct:
+		return "struct"
+	case schema.Array, schema.Slice:
+		return fmt.Sprintf("%s, %d elements", kind, len(v.Elems))
+	case schema.Map:
+		return fmt.Sprintf("map, %d entries", len(v.Elems))
+	case schema.Maybe:
+		switch {
+		case v.Ref:
+			return fmt.Sprintf("maybe = reference to %d", v.ID)
+		case v.Null():
+			return "maybe = null"
+		case v.Type.Refs:
+			return fmt.Sprintf("maybe, id %d", v.ID)
+		default:
+			return "maybe"
+		}
+	case schema.Union:
+		if v.Null() {
+			return "union = null"
+		}
+		return fmt.Sprintf("union, tag %d", v.Tag)
+	case schema.Delegate:
+		return "delegate " + v.Type.Name
+	}
+
+	switch data := v.Data.(type) {
+	case nil:
+		return fmt.Sprintf("%s = <invalid>", kind)
+	case string:
+		return fmt.Sprintf("%s = %q", kind, data)
+	case []byte:
+		return fmt.Sprintf("%s = %x", kind, data)
+	case time.Time:
+		return fmt.Sprintf("%s = %s", kind, data.Format(time.RFC3339Nano))
+	default:
+		return fmt.Sprintf("%s = %v", kind, data)
+	}
+}
+
+// annotated is the JSON representation of a value along with its position
+// in the encoded data.
+type annotated struct {
+	Name    string      `json:"name,omitempty"`
+	Kind    schema.Kind `json:"kind"`
+	Offset  int         `json:"offset"`
+	Size    int         `json:"size"`
+	Value   interface{} `json:"value,omitempty"`
+	Null    bool        `json:"null,omitempty"`
+	Tag     int         `json:"tag,omitempty"`
+	Variant string      `json:"variant,omitempty"`
+	ID      *uint64     `json:"id,omitempty"`
+	Ref     *uint64     `json:"ref,omitempty"`
+	Fields  []annotated `json:"fields,omitempty"`
+	Keys    []annotated `json:"keys,omitempty"`
+	Elems   []annotated `json:"elems,omitempty"`
+}
+
+// MarshalJSON encodes the value as JSON annotated with the kind, offset and
+// size of every value it contains. Floats that are not numbers or are
+// infinite, complex numbers and times are encoded as strings.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(annotate("", v))
+}
+
+func annotate(name string, v *Value) annotated {
+	a := annotated{
+		Name:    name,
+		Kind:    v.Type.Kind,
+		Offset:  v.Offset,
+		Size:    v.Size,
+		Value:   jsonData(v.Data),
+		Null:    v.Null(),
+		Tag:     v.Tag,
+		Variant: v.Variant,
+	}
+
+	if v.Ref {
+		id := v.ID
+		a.Ref = &id
+	} else if v.Type.Kind == schema.Maybe && v.Type.Refs && !a.Null {
+		id := v.ID
+		a.ID = &id
+	}
+
+	for _, f := range v.Fields {
+		a.Fields = append(a.Fields, annotate(f.Name, f.Value))
+	}
+
+	for _, k := range v.Keys {
+		a.Keys = append(a.Keys, annotate("", k))
+	}
+
+	for _, e := range v.Elems {
+		a.Elems = append(a.Elems, annotate("", e))
+	}
+
+	return a
+}
+
+// jsonData returns the given data of a value in a form that can be encoded
+// as JSON.
+func jsonData(data interface{}) interface{} {
+	switch data := data.(type) {
+	case float64:
+		if math.IsNaN(data) || math.IsInf(data, 0) {
+			return fmt.Sprint(data)
+		}
+	case complex128:
+		return fmt.Sprint(data)
+	case time.Time:
+		return data.Format(time.RFC3339Nano)
+	}
+	return data
+}
@@ -0,0 +1,978 @@
+package dynamic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// JSONError is an error converting a JSON document to a value because the
+// document does not have the shape of the type.
+type JSONError struct {
+	// Line and Column of the JSON value, starting at 1.
+	Line, Column int
+	// Path of the value from the converted type, such as "Items[2].Name",
+	// or empty if it's the type itself.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *JSONError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// JSONToBinary converts a JSON document to the data the type with the given
+// name of the schema encodes it as. The document must have the shape
+// described by ParseJSON, and the constraints of the type are checked
+// before encoding it.
+func JSONToBinary(s *schema.Schema, typ string, doc []byte) ([]byte, error) {
+	named := Lookup(s, typ)
+	if named == nil {
+		return nil, fmt.Errorf("type %s not found in the schema of package %s", typ, s.Package)
+	}
+
+	v, err := ParseJSON(named, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Check(v); err != nil {
+		return nil, err
+	}
+
+	return Encode(v)
+}
+
+// BinaryToJSON converts the data encoded by the type with the given name of
+// the schema to a JSON document, as described by FormatJSON. The
+// constraints of the type are checked after decoding the data, and bytes
+// left after the value are an error.
+func BinaryToJSON(s *schema.Schema, typ string, data []byte) ([]byte, error) {
+	v, err := Decode(s, typ, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Size < len(data) {
+		return nil, &Error{v.Size, "", fmt.Sprintf("%d bytes left after the value", len(data)-v.Size)}
+	}
+
+	if err := Check(v); err != nil {
+		return nil, err
+	}
+
+	return FormatJSON(v)
+}
+
+// ParseJSON converts a JSON document to a value of the given type, which
+// can be encoded with Encode. Values are represented in the document as
+// follows:
+//
+//   - Booleans, integers and strings are JSON booleans, numbers and
+//     strings. Integers must fit in their type.
+//   - Floats are numbers, or the strings "NaN", "+Inf" and "-Inf".
+//   - Complex numbers are arrays with the real and imaginary parts.
+//   - Bytes and marshalers are base64 strings.
+//   - Durations are strings such as "1h30m", or numbers of nanoseconds.
+//   - Times are RFC 3339 strings. Times in zones other than UTC and the
+//     local one are objects with the string in "time" and the name of the
+//     zone in "zone", since RFC 3339 only has its offset.
+//   - Arrays and slices are arrays.
+//   - Maps with string and integer keys are objects. Any other map is an
+//     array of [key, value] pairs.
+//   - Structs are objects with their fields.
+//   - Maybes are null or their value.
+//   - Unions are null or objects with the name of the type of the value in
+//     "type" and the value in "value".
+//   - Delegates are the value of their layout.
+//
+// Fields and values of unions missing in the document are the zero value
+// of their type, and so are nulls of types other than maybes and unions.
+// Unknown fields are an error. References to values of maybes with
+// reference tracking can't be represented, so every value is encoded as a
+// new one, and neither can maybes of maybes whose value is null, which are
+// null themselves. If the document does not have the shape of the type, a
+// *JSONError with its position is returned.
+func ParseJSON(named *schema.Named, doc []byte) (*Value, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	p := &jsonParser{doc: doc, dec: dec}
+	n, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := p.start(dec.InputOffset())
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, p.errorf(offset, "", "unexpected data after the JSON document")
+	}
+
+	c := &jsonConverter{parser: p, named: named}
+	return c.convert(named.Type, n, "")
+}
+
+// jsonNode is a JSON value along with its position in the document. Its
+// value is nil, a bool, a json.Number, a string, a []*jsonNode or a
+// *jsonObject.
+type jsonNode struct {
+	offset int64
+	value  interface{}
+}
+
+// jsonObject is a JSON object with its members in the order they appear in
+// the document.
+type jsonObject struct {
+	keys   []string
+	values []*jsonNode
+}
+
+func (o *jsonObject) get(key string) *jsonNode {
+	for i, k := range o.keys {
+		if k == key {
+			return o.values[i]
+		}
+	}
+	return nil
+}
+
+type jsonParser struct {
+	doc []byte
+	dec *json.Decoder
+}
+
+func (p *jsonParser) errorf(offset int64, path string, format string, args ...interface{}) error {
+	line, col := 1, 1
+	for _, b := range p.doc[:offset] {
+		if b == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &JSONError{line, col, path, fmt.Sprintf(format, args...)}
+}
+
+// start returns the offset of the first token after the given offset,
+// skipping whitespace and separators.
+func (p *jsonParser) start(offset int64) int64 {
+	for offset < int64(len(p.doc)) && strings.IndexByte(" \t\r\n,:", p.doc[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func (p *jsonParser) parse() (*jsonNode, error) {
+	offset := p.start(p.dec.InputOffset())
+	tok, err := p.dec.Token()
+	if err == io.EOF {
+		return nil, p.errorf(offset, "", "unexpected end of JSON document")
+	} else if err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) && syntax.Offset > 0 {
+			// The offset is the number of bytes read, including the
+			// invalid one.
+			offset = syntax.Offset - 1
+		}
+		return nil, p.errorf(offset, "", "invalid JSON: %s", err)
+	}
+
+	n := &jsonNode{offset: offset}
+	switch tok {
+	case json.Delim('['):
+		elems := []*jsonNode{}
+		for p.dec.More() {
+			elem, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		n.value = elems
+	case json.Delim('{'):
+		obj := new(jsonObject)
+		for p.dec.More() {
+			key, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+
+			name := key.value.(string)
+			if obj.get(name) != nil {
+				return nil, p.errorf(key.offset, "", "duplicate key %q", name)
+			}
+
+			obj.keys = append(obj.keys, name)
+			obj.values = append(obj.values, value)
+		}
+		n.value = obj
+	default:
+		n.value = tok
+		return n, nil
+	}
+
+	// Consume the closing delimiter.
+	if _, err := p.dec.Token(); err != nil {
+		return nil, p.errorf(p.dec.InputOffset(), "", "invalid JSON: %s", err)
+	}
+	return n, nil
+}
+
+type jsonConverter struct {
+	parser *jsonParser
+	named  *schema.Named
+	// ids is the number of values of maybes with reference tracking
+	// converted so far.
+	ids   uint64
+	depth int
+}
+
+// describeJSON returns the name of the kind of the given JSON value.
+func describeJSON(n *jsonNode) string {
+	switch n.value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []*jsonNode:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func (c *jsonConverter) errorf(n *jsonNode, path string, format string, args ...interface{}) error {
+	return c.parser.errorf(n.offset, path, format, args...)
+}
+
+func (c *jsonConverter) mismatch(n *jsonNode, path string, expected string) error {
+	return c.errorf(n, path, "expecting %s, found %s", expected, describeJSON(n))
+}
+
+// convert converts the JSON value to a value of the given type. Null is
+// the zero value of every type, and so are values missing from the
+// document, which are converted as a null at the position of the value
+// that should contain them.
+func (c *jsonConverter) convert(t *schema.Type, n *jsonNode, path string) (*Value, error) {
+	v := &Value{Type: t}
+	node := n
+	if n.value == nil {
+		n = nil
+	}
+
+	switch t.Kind {
+	case schema.Bool:
+		v.Data = false
+		if n != nil {
+			b, ok := n.value.(bool)
+			if !ok {
+				return nil, c.mismatch(n, path, "a boolean")
+			}
+			v.Data = b
+		}
+	case schema.Int8, schema.Int16, schema.Int32, schema.Int64, schema.Int:
+		v.Data = int64(0)
+		if n != nil {
+			num, ok := n.value.(json.Number)
+			if !ok {
+				return nil, c.mismatch(n, path, "an integer")
+			}
+
+			x, err := strconv.ParseInt(num.String(), 10, basicSizes[t.Kind]*8)
+			if err != nil {
+				return nil, c.numberError(n, path, t.Kind, err)
+			}
+			v.Data = x
+		}
+	case schema.Uint8, schema.Uint16, schema.Uint32, schema.Uint64, schema.Uint, schema.Uintptr:
+		v.Data = uint64(0)
+		if n != nil {
+			num, ok := n.value.(json.Number)
+			if !ok {
+				return nil, c.mismatch(n, path, "an unsigned integer")
+			}
+
+			x, err := strconv.ParseUint(num.String(), 10, basicSizes[t.Kind]*8)
+			if err != nil {
+				return nil, c.numberError(n, path, t.Kind, err)
+			}
+			v.Data = x
+		}
+	case schema.Float32, schema.Float64:
+		v.Data = float64(0)
+		if n != nil {
+			f, err := c.float(t.Kind, n, path)
+			if err != nil {
+				return nil, err
+			}
+			v.Data = f
+		}
+	case schema.Complex64, schema.Complex128:
+		v.Data = complex128(0)
+		if n != nil {
+			parts, ok := n.value.([]*jsonNode)
+			if !ok || len(parts) != 2 {
+				return nil, c.mismatch(n, path, "an array with the real and imaginary parts")
+			}
+
+			kind := schema.Float64
+			if t.Kind == schema.Complex64 {
+				kind = schema.Float32
+			}
+
+			re, err := c.float(kind, parts[0], path)
+			if err != nil {
+				return nil, err
+			}
+
+			im, err := c.float(kind, parts[1], path)
+			if err != nil {
+				return nil, err
+			}
+			v.Data = complex(re, im)
+		}
+	case schema.String:
+		v.Data = ""
+		if n != nil {
+			s, ok := n.value.(string)
+			if !ok {
+				return nil, c.mismatch(n, path, "a string")
+			}
+			v.Data = s
+		}
+	case schema.Bytes, schema.Marshaler:
+		v.Data = []byte{}
+		if n != nil {
+			s, ok := n.value.(string)
+			if !ok {
+				return nil, c.mismatch(n, path, "a base64 string")
+			}
+
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, c.errorf(n, path, "invalid base64 string: %s", err)
+			}
+			v.Data = b
+		}
+	case schema.Duration:
+		v.Data = time.Duration(0)
+		if n != nil {
+			switch x := n.value.(type) {
+			case string:
+				d, err := time.ParseDuration(x)
+				if err != nil {
+					return nil, c.errorf(n, path, "invalid duration %q", x)
+				}
+				v.Data = d
+			case json.Number:
+				d, err := strconv.ParseInt(x.String(), 10, 64)
+				if err != nil {
+					return nil, c.numberError(n, path, t.Kind, err)
+				}
+				v.Data = time.Duration(d)
+			default:
+				return nil, c.mismatch(n, path, "a duration string or a number of nanoseconds")
+			}
+		}
+	case schema.Time:
+		v.Data = time.Time{}
+		if n != nil {
+			tm, err := c.convertTime(n, path)
+			if err != nil {
+				return nil, err
+			}
+			v.Data = tm
+		}
+	case schema.Array, schema.Slice:
+		var elems []*jsonNode
+		if n != nil {
+			var ok bool
+			if elems, ok = n.value.([]*jsonNode); !ok {
+				return nil, c.mismatch(n, path, "an array")
+			}
+
+			if t.Kind == schema.Array && int64(len(elems)) > t.Len {
+				return nil, c.errorf(n, path, "expecting an array of %d elements, found %d", t.Len, len(elems))
+			}
+		}
+
+		size := int64(len(elems))
+		if t.Kind == schema.Array {
+			size = t.Len
+		}
+
+		v.Elems = make([]*Value, 0, int(min64(size, maxEmptyElems)))
+		for i := int64(0); i < size; i++ {
+			elem := &jsonNode{offset: node.offset}
+			if i < int64(len(elems)) {
+				elem = elems[i]
+			}
+
+			ev, err := c.convert(t.Elem, elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v.Elems = append(v.Elems, ev)
+		}
+	case schema.Map:
+		if n != nil {
+			if err := c.convertMap(t, n, v, path); err != nil {
+				return nil, err
+			}
+		}
+	case schema.Struct:
+		var obj *jsonObject
+		if n != nil {
+			var ok bool
+			if obj, ok = n.value.(*jsonObject); !ok {
+				return nil, c.mismatch(n, path, "an object")
+			}
+
+			for i, key := range obj.keys {
+				if !hasField(t, key) {
+					return nil, c.errorf(obj.values[i], fieldPath(path, key), "unknown field")
+				}
+			}
+		}
+
+		for _, f := range t.Fields {
+			fn := &jsonNode{offset: node.offset}
+			if obj != nil {
+				if n := obj.get(f.Name); n != nil {
+					fn = n
+				}
+			}
+
+			fv, err := c.convert(f.Type, fn, fieldPath(path, f.Name))
+			if err != nil {
+				return nil, err
+			}
+			v.Fields = append(v.Fields, Field{f.Name, fv})
+		}
+	case schema.Maybe:
+		if n != nil {
+			if t.Refs {
+				v.ID = c.ids
+				c.ids++
+			}
+
+			elem, err := c.convert(t.Elem, n, path)
+			if err != nil {
+				return nil, err
+			}
+			v.Elems = []*Value{elem}
+		}
+	case schema.Union:
+		if n != nil {
+			if err := c.convertUnion(t, n, v, path); err != nil {
+				return nil, err
+			}
+		}
+	case schema.Ref:
+		def, ok := c.named.Definitions[t.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: definition of type %s not found", path, t.Name)
+		}
+
+		if c.depth >= c.named.MaxDepth {
+			return nil, c.errorf(node, path, "maximum depth of %d exceeded", c.named.MaxDepth)
+		}
+
+		c.depth++
+		defer func() { c.depth-- }()
+		return c.convert(def, node, path)
+	case schema.Delegate:
+		if t.Layout == nil {
+			return nil, fmt.Errorf("%s: type %s has its own methods and its layout is unknown", path, t.Name)
+		}
+
+		// The layout has its own definitions, references and depth.
+		named, ids, depth := c.named, c.ids, c.depth
+		c.named, c.ids, c.depth = t.Layout, 0, 0
+		defer func() { c.named, c.ids, c.depth = named, ids, depth }()
+
+		elem, err := c.convert(t.Layout.Type, node, path)
+		if err != nil {
+			return nil, err
+		}
+		v.Elems = []*Value{elem}
+	case schema.TypeParam:
+		return nil, fmt.Errorf("%s: type parameter %s can't be converted without its type argument", path, t.Name)
+	default:
+		return nil, fmt.Errorf("%s: unknown kind of type %q", path, t.Kind)
+	}
+
+	return v, nil
+}
+
+func (c *jsonConverter) numberError(n *jsonNode, path string, kind schema.Kind, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return c.errorf(n, path, "number %s overflows %s", n.value, kind)
+	}
+	return c.errorf(n, path, "invalid %s %s", kind, n.value)
+}
+
+// float converts a JSON number, or one of the strings "NaN", "+Inf", "Inf"
+// and "-Inf", to a float of the given kind.
+func (c *jsonConverter) float(kind schema.Kind, n *jsonNode, path string) (float64, error) {
+	switch x := n.value.(type) {
+	case json.Number:
+		bits := 64
+		if kind == schema.Float32 {
+			bits = 32
+		}
+
+		f, err := strconv.ParseFloat(x.String(), bits)
+		if err != nil {
+			return 0, c.numberError(n, path, kind, err)
+		}
+		return f, nil
+	case string:
+		switch x {
+		case "NaN":
+			return math.NaN(), nil
+		case "+Inf", "Inf":
+			return math.Inf(1), nil
+		case "-Inf":
+			return math.Inf(-1), nil
+		}
+		return 0, c.errorf(n, path, "invalid %s %q", kind, x)
+	default:
+		return 0, c.mismatch(n, path, "a number")
+	}
+}
+
+func (c *jsonConverter) convertMap(t *schema.Type, n *jsonNode, v *Value, path string) error {
+	if objectKey(t.Key) {
+		obj, ok := n.value.(*jsonObject)
+		if !ok {
+			return c.mismatch(n, path, "an object")
+		}
+
+		for i, key := range obj.keys {
+			keyNode := &jsonNode{obj.values[i].offset, key}
+			if t.Key.Kind != schema.String {
+				keyNode.value = json.Number(key)
+			}
+
+			k, err := c.convert(t.Key, keyNode, fmt.Sprintf("%s[key %d]", path, i))
+			if err != nil {
+				return err
+			}
+
+			if err := c.checkDuplicateKey(v, k, keyNode, path); err != nil {
+				return err
+			}
+
+			elem, err := c.convert(t.Elem, obj.values[i], fmt.Sprintf("%s[%q]", path, key))
+			if err != nil {
+				return err
+			}
+
+			v.Keys = append(v.Keys, k)
+			v.Elems = append(v.Elems, elem)
+		}
+		return nil
+	}
+
+	pairs, ok := n.value.([]*jsonNode)
+	if !ok {
+		return c.mismatch(n, path, "an array of [key, value] pairs")
+	}
+
+	for i, pair := range pairs {
+		kv, ok := pair.value.([]*jsonNode)
+		if !ok || len(kv) != 2 {
+			return c.mismatch(pair, fmt.Sprintf("%s[%d]", path, i), "a [key, value] pair")
+		}
+
+		k, err := c.convert(t.Key, kv[0], fmt.Sprintf("%s[key %d]", path, i))
+		if err != nil {
+			return err
+		}
+
+		if err := c.checkDuplicateKey(v, k, kv[0], path); err != nil {
+			return err
+		}
+
+		elem, err := c.convert(t.Elem, kv[1], fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return err
+		}
+
+		v.Keys = append(v.Keys, k)
+		v.Elems = append(v.Elems, elem)
+	}
+	return nil
+}
+
+// checkDuplicateKey returns an error if a key with the same value as the
+// given one was already converted. Only keys with data are compared.
+func (c *jsonConverter) checkDuplicateKey(m *Value, k *Value, n *jsonNode, path string) error {
+	if k.Data == nil {
+		return nil
+	}
+
+	for _, key := range m.Keys {
+		if dataEqual(key.Data, k.Data) {
+			return c.errorf(n, path, "duplicate map key %v", describe(k))
+		}
+	}
+	return nil
+}
+
+func dataEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	case time.Time:
+		b, ok := b.(time.Time)
+		return ok && a.Equal(b)
+	default:
+		return a == b
+	}
+}
+
+func (c *jsonConverter) convertUnion(t *schema.Type, n *jsonNode, v *Value, path string) error {
+	obj, ok := n.value.(*jsonObject)
+	if !ok {
+		return c.mismatch(n, path, `an object with "type" and "value"`)
+	}
+
+	for i, key := range obj.keys {
+		if key != "type" && key != "value" {
+			return c.errorf(obj.values[i], path, "unknown key %q, expecting \"type\" and \"value\"", key)
+		}
+	}
+
+	typ := obj.get("type")
+	if typ == nil {
+		return c.errorf(n, path, `missing "type" of the value`)
+	}
+
+	name, ok := typ.value.(string)
+	if !ok {
+		return c.mismatch(typ, path, "the name of a type")
+	}
+
+	var names []string
+	for _, variant := range t.Variants {
+		names = append(names, variant.Name)
+		if variant.Name != name {
+			continue
+		}
+
+		value := obj.get("value")
+		if value == nil {
+			value = &jsonNode{offset: n.offset}
+		}
+
+		elem, err := c.convert(variant.Type, value, path)
+		if err != nil {
+			return err
+		}
+
+		v.Tag = variant.Tag
+		v.Variant = variant.Name
+		v.Elems = []*Value{elem}
+		return nil
+	}
+
+	return c.errorf(typ, path, "unknown type %q, expecting one of: %s", name, strings.Join(names, ", "))
+}
+
+// convertTime converts an RFC 3339 string, or an object with one in "time"
+// and the name of its zone in "zone".
+func (c *jsonConverter) convertTime(n *jsonNode, path string) (time.Time, error) {
+	if obj, ok := n.value.(*jsonObject); ok {
+		for i, key := range obj.keys {
+			if key != "time" && key != "zone" {
+				return time.Time{}, c.errorf(obj.values[i], path, "unknown key %q, expecting \"time\" and \"zone\"", key)
+			}
+		}
+
+		tn, zn := obj.get("time"), obj.get("zone")
+		if tn == nil {
+			return time.Time{}, c.errorf(n, path, `missing "time" of the value`)
+		}
+
+		if _, ok := tn.value.(string); !ok {
+			return time.Time{}, c.mismatch(tn, path, "an RFC 3339 time string")
+		}
+
+		tm, err := c.convertTime(tn, path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		var zone string
+		if zn != nil {
+			if zone, ok = zn.value.(string); !ok {
+				return time.Time{}, c.mismatch(zn, path, "the name of a time zone")
+			}
+		}
+
+		_, offset := tm.Zone()
+		return inZone(tm, zone, offset), nil
+	}
+
+	s, ok := n.value.(string)
+	if !ok {
+		return time.Time{}, c.mismatch(n, path, "an RFC 3339 time string")
+	}
+
+	tm, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, c.errorf(n, path, "invalid RFC 3339 time %q", s)
+	}
+	return tm, nil
+}
+
+// objectKey reports whether maps with keys of the given type are JSON
+// objects.
+func objectKey(t *schema.Type) bool {
+	switch t.Kind {
+	case schema.String,
+		schema.Int8, schema.Int16, schema.Int32, schema.Int64, schema.Int,
+		schema.Uint8, schema.Uint16, schema.Uint32, schema.Uint64, schema.Uint, schema.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasField(t *schema.Type, name string) bool {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatJSON converts the value to a JSON document with the shape described
+// by ParseJSON. References to values of maybes with reference tracking are
+// replaced by a copy of the value they reference, so values that reference
+// themselves can't be converted.
+func FormatJSON(v *Value) ([]byte, error) {
+	f := &jsonFormatter{refs: make(map[uint64]*Value), visiting: make(map[*Value]bool)}
+	if err := f.format(v, ""); err != nil {
+		return nil, err
+	}
+	return f.buf.Bytes(), nil
+}
+
+type jsonFormatter struct {
+	buf bytes.Buffer
+	// refs are the values of maybes with reference tracking by ID.
+	refs map[uint64]*Value
+	// visiting are the values being formatted, which can't be referenced.
+	visiting map[*Value]bool
+}
+
+func (f *jsonFormatter) marshal(x interface{}) error {
+	b, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	f.buf.Write(b)
+	return nil
+}
+
+func (f *jsonFormatter) format(v *Value, path string) error {
+	f.visiting[v] = true
+	defer delete(f.visiting, v)
+
+	switch v.Type.Kind {
+	case schema.Array, schema.Slice:
+		f.buf.WriteByte('[')
+		for i, elem := range v.Elems {
+			if i > 0 {
+				f.buf.WriteByte(',')
+			}
+
+			if err := f.format(elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		f.buf.WriteByte(']')
+		return nil
+	case schema.Map:
+		return f.formatMap(v, path)
+	case schema.Struct:
+		f.buf.WriteByte('{')
+		for i, field := range v.Fields {
+			if i > 0 {
+				f.buf.WriteByte(',')
+			}
+
+			if err := f.marshal(field.Name); err != nil {
+				return err
+			}
+			f.buf.WriteByte(':')
+			if err := f.format(field.Value, fieldPath(path, field.Name)); err != nil {
+				return err
+			}
+		}
+		f.buf.WriteByte('}')
+		return nil
+	case schema.Maybe:
+		if v.Ref {
+			ref, ok := f.refs[v.ID]
+			if !ok {
+				return fmt.Errorf("%s: invalid reference %d", path, v.ID)
+			}
+
+			if f.visiting[ref] {
+				return fmt.Errorf("%s: reference %d to a value that contains it can't be represented in JSON", path, v.ID)
+			}
+			return f.format(ref, path)
+		}
+
+		if len(v.Elems) == 0 {
+			f.buf.WriteString("null")
+			return nil
+		}
+
+		if v.Type.Refs {
+			f.refs[v.ID] = v.Elems[0]
+		}
+		return f.format(v.Elems[0], path)
+	case schema.Union:
+		if len(v.Elems) == 0 {
+			f.buf.WriteString("null")
+			return nil
+		}
+
+		f.buf.WriteString(`{"type":`)
+		if err := f.marshal(v.Variant); err != nil {
+			return err
+		}
+		f.buf.WriteString(`,"value":`)
+		if err := f.format(v.Elems[0], path); err != nil {
+			return err
+		}
+		f.buf.WriteByte('}')
+		return nil
+	case schema.Delegate:
+		if len(v.Elems) != 1 {
+			return fmt.Errorf("%s: delegate has %d values", path, len(v.Elems))
+		}
+
+		// References of the layout are to its own values.
+		refs := f.refs
+		f.refs = make(map[uint64]*Value)
+		defer func() { f.refs = refs }()
+		return f.format(v.Elems[0], path)
+	}
+
+	switch data := v.Data.(type) {
+	case float64:
+		return f.marshal(floatJSON(data))
+	case complex128:
+		return f.marshal([]interface{}{floatJSON(real(data)), floatJSON(imag(data))})
+	case time.Duration:
+		return f.marshal(data.String())
+	case time.Time:
+		if loc := data.Location(); loc == time.UTC || loc == time.Local {
+			return f.marshal(data.Format(time.RFC3339Nano))
+		}
+
+		// RFC 3339 only has the offset of the zone, so its name is kept
+		// apart.
+		f.buf.WriteString(`{"time":`)
+		if err := f.marshal(data.Format(time.RFC3339Nano)); err != nil {
+			return err
+		}
+		f.buf.WriteString(`,"zone":`)
+		if err := f.marshal(data.Location().String()); err != nil {
+			return err
+		}
+		f.buf.WriteByte('}')
+		return nil
+	case nil:
+		return fmt.Errorf("%s: value of kind %s has no data", path, v.Type.Kind)
+	default:
+		// Bytes are encoded as base64 by encoding/json.
+		return f.marshal(data)
+	}
+}
+
+func (f *jsonFormatter) formatMap(v *Value, path string) error {
+	if objectKey(v.Type.Key) {
+		f.buf.WriteByte('{')
+		for i, elem := range v.Elems {
+			if i > 0 {
+				f.buf.WriteByte(',')
+			}
+
+			if err := f.marshal(fmt.Sprint(v.Keys[i].Data)); err != nil {
+				return err
+			}
+			f.buf.WriteByte(':')
+			if err := f.format(elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		f.buf.WriteByte('}')
+		return nil
+	}
+
+	f.buf.WriteByte('[')
+	for i, elem := range v.Elems {
+		if i > 0 {
+			f.buf.WriteByte(',')
+		}
+
+		f.buf.WriteByte('[')
+		if err := f.format(v.Keys[i], fmt.Sprintf("%s[key %d]", path, i)); err != nil {
+			return err
+		}
+		f.buf.WriteByte(',')
+		if err := f.format(elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+		f.buf.WriteString("]")
+	}
+	f.buf.WriteByte(']')
+	return nil
+}
+
+// floatJSON returns the float, or a string if it's not a number or it's
+// infinite, which can't be represented as JSON numbers.
+func floatJSON(x float64) interface{} {
+	switch {
+	case math.IsNaN(x):
+		return "NaN"
+	case math.IsInf(x, 1):
+		return "+Inf"
+	case math.IsInf(x, -1):
+		return "-Inf"
+	default:
+		return x
+	}
+}
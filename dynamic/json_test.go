@@ -0,0 +1,187 @@
+package dynamic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/bindec/schema"
+)
+
+const fooJSON = `{"N":-3,"S":"hi","L":[1,2],"U":{"type":"Bar","value":"x"}}`
+
+func TestBinaryToJSON(t *testing.T) {
+	doc, err := BinaryToJSON(testSchema, "Foo", fooData)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(doc) != fooJSON {
+		t.Errorf("expected %s, got: %s", fooJSON, doc)
+	}
+
+	_, err = BinaryToJSON(testSchema, "Foo", append(append([]byte{}, fooData...), 0))
+	if err == nil || err.Error() != "offset 34: 1 bytes left after the value" {
+		t.Errorf("expected error for bytes left, got: %v", err)
+	}
+}
+
+func TestJSONToBinary(t *testing.T) {
+	data, err := JSONToBinary(testSchema, "Foo", []byte(fooJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(data, fooData) {
+		t.Errorf("expected %v, got: %v", fooData, data)
+	}
+
+	// Missing values are zero values.
+	data, err = JSONToBinary(testSchema, "Foo", []byte(`{"S": "hi", "L": null}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := append(append([]byte{0, 0}, fooData[2:12]...), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	if !bytes.Equal(data, expected) {
+		t.Errorf("expected %v, got: %v", expected, data)
+	}
+}
+
+func TestJSONTimeZone(t *testing.T) {
+	typ := Lookup(testSchema, "Time").Type
+	testCases := []struct {
+		name string
+		time time.Time
+		doc  string
+	}{
+		{"utc", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), `"2020-01-02T03:04:05Z"`},
+		{"named zone", time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("X", 3600)), `{"time":"2020-01-02T03:04:05+01:00","zone":"X"}`},
+		{"unnamed zone", time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("", -3600)), `{"time":"2020-01-02T03:04:05-01:00","zone":""}`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(&Value{Type: typ, Data: tt.time})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			doc, err := BinaryToJSON(testSchema, "Time", data)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(doc) != tt.doc {
+				t.Errorf("expected %s, got: %s", tt.doc, doc)
+			}
+
+			result, err := JSONToBinary(testSchema, "Time", doc)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !bytes.Equal(result, data) {
+				t.Errorf("expected %v, got: %v", data, result)
+			}
+		})
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		typ  string
+		doc  string
+		err  string
+	}{
+		{"invalid JSON", "Foo", `{"N": }`, "1:7: invalid JSON: "},
+		{"not an object", "Foo", `[1]`, "1:1: expecting an object, found array"},
+		{"mismatch", "Foo", "{\n  \"N\": \"x\"\n}", `2:8: N: expecting an integer, found string`},
+		{"overflow", "Foo", `{"N": 40000}`, "1:7: N: number 40000 overflows int16"},
+		{"not an integer", "Foo", `{"N": 1.5}`, "1:7: N: invalid int16 1.5"},
+		{"element", "Foo", `{"L": [1, true]}`, "1:11: L[1]: expecting an integer, found boolean"},
+		{"unknown field", "Foo", `{"X": 1}`, "1:7: X: unknown field"},
+		{"duplicate key", "Foo", `{"N": 1, "N": 2}`, `1:10: duplicate key "N"`},
+		{"unknown variant", "Foo", `{"U": {"type": "Baz"}}`, `1:16: U: unknown type "Baz", expecting one of: Bar`},
+		{"missing variant", "Foo", `{"U": {"value": "x"}}`, `1:7: U: missing "type" of the value`},
+		{"trailing data", "Foo", `{} {}`, "1:4: unexpected data after the JSON document"},
+		{"max depth", "List", `1`, "1:1: maximum depth of 2 exceeded"},
+		{"unknown time key", "Time", `{"time": "2020-01-02T03:04:05Z", "offset": 1}`, `1:44: unknown key "offset", expecting "time" and "zone"`},
+		{"missing time", "Time", `{"zone": "X"}`, `1:1: missing "time" of the value`},
+		{"invalid zone", "Time", `{"time": "2020-01-02T03:04:05Z", "zone": 1}`, "1:42: expecting the name of a time zone, found number"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// The messages of encoding/json depend on the Go version, so
+			// only the start of the error is compared.
+			_, err := ParseJSON(Lookup(testSchema, tt.typ), []byte(tt.doc))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	s := &schema.Schema{
+		Version: schema.Version,
+		Types: []schema.Named{{
+			Name: "Foo",
+			Type: &schema.Type{Kind: schema.Struct, Fields: []schema.Field{
+				{Name: "L", Type: &schema.Type{Kind: schema.Slice, Elem: &schema.Type{Kind: schema.Struct, Fields: []schema.Field{
+					{Name: "S", Type: stringType, Constraints: []schema.Constraint{{Name: "maxlen", Args: "2"}}},
+					{Name: "N", Type: &schema.Type{Kind: schema.Maybe, Elem: int16Type}, Constraints: []schema.Constraint{{Name: "oneof", Args: "1 2"}}},
+				}}}},
+			}},
+		}},
+	}
+
+	testCases := []struct {
+		doc string
+		err string
+	}{
+		{`{"L": [{"S": "ab", "N": 2}, {"N": null}]}`, ""},
+		{`{"L": [{"S": "ab"}, {"S": "abc"}]}`, "L[1].S: field 'S' has a maximum length of 2"},
+		{`{"L": [{"N": 3}]}`, "L[0].N: field 'N' should have one of these values: 1, 2"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.doc, func(t *testing.T) {
+			data, err := JSONToBinary(s, "Foo", []byte(tt.doc))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				_, err = BinaryToJSON(s, "Foo", data)
+				if err != nil {
+					t.Errorf("unexpected error converting back: %s", err)
+				}
+				return
+			}
+
+			if _, ok := err.(*ConstraintError); !ok || err.Error() != tt.err {
+				t.Errorf("expected constraint error %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	v, err := Decode(testSchema, "Foo", fooData)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := Encode(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(data, fooData) {
+		t.Errorf("expected %v, got: %v", fooData, data)
+	}
+}
@@ -0,0 +1,56 @@
+// Package dynamic decodes and encodes data encoded by bindec without the
+// code generated for its types, using their schema, and converts it from
+// and to JSON. It's meant for tools that inspect or write encoded data,
+// such as bindec dump, rather than for encoding data in programs, which is
+// what the generated code is for.
+package dynamic
+
+import (
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// Value is a decoded value along with its position in the encoded data.
+type Value struct {
+	// Type of the value. References to definitions are replaced by the
+	// definition.
+	Type *schema.Type
+	// Offset is the position of the first byte of the value in the data.
+	Offset int
+	// Size is the number of bytes of the encoded value.
+	Size int
+	// Data is the value of basic types, strings, bytes, durations, times
+	// and marshalers. It's a bool, int64, uint64, float64, complex128,
+	// string, []byte, time.Duration or time.Time, depending on the kind.
+	// Marshalers are the bytes they were encoded to.
+	Data interface{}
+	// Fields are the values of the fields of structs.
+	Fields []Field
+	// Keys are the keys of maps, in the order they were encoded. Their
+	// values are the elements with the same index.
+	Keys []*Value
+	// Elems are the elements of arrays, slices and maps, or the value of
+	// maybes and unions, which is empty if they are null, and the value
+	// of delegates, with the type of their layout.
+	Elems []*Value
+	// Variant is the name of the type of the value of unions, and Tag its
+	// tag. If the union is null, Tag is 0.
+	Variant string
+	Tag     int
+	// ID is the ID given to the value of maybes with reference tracking.
+	// If Ref is true, the maybe is a reference to a value decoded before,
+	// which was given that ID, and has no element.
+	ID  uint64
+	Ref bool
+}
+
+// Field is the value of a field of a struct.
+type Field struct {
+	Name  string
+	Value *Value
+}
+
+// Null reports whether the value is a null maybe or union.
+func (v *Value) Null() bool {
+	kind := v.Type.Kind
+	return (kind == schema.Maybe || kind == schema.Union) && len(v.Elems) == 0 && !v.Ref
+}
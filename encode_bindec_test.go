@@ -0,0 +1,78 @@
+// WARNING! This is code generated by bindec, do not modify manually.
+
+package bindec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+var _ = binary.LittleEndian
+var _ = math.Abs
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t EncodeOnlyTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t EncodeOnlyTestType) WriteBinary(writer io.Writer) error {
+	{
+
+		{
+			v := t.Name
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			_, err := writer.Write([]byte(v))
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			n := len(t.Items)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				x := t.Items[i]
+				ux := uint64(x) << 1
+				if x < 0 {
+					ux = ^ux
+				}
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint64(bs, ux)
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,10 +1,21 @@
 package bindec
 
 import (
+	"bytes"
+	"io"
 	"math"
+	"math/big"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/erizocosmico/bindec/bench"
+	"github.com/erizocosmico/bindec/dynamic"
+	"github.com/erizocosmico/bindec/internal/testpkg"
+	"github.com/erizocosmico/bindec/internal/testpkg/data"
+	"github.com/erizocosmico/bindec/internal/testpkg/v1/model"
+	modelv2 "github.com/erizocosmico/bindec/internal/testpkg/v2/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -231,3 +242,543 @@ func TestEncodeDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeEncodeDecode(t *testing.T) {
+	now := time.Now()
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		madrid = time.FixedZone("CET", 3600)
+	}
+
+	testCases := []struct {
+		name string
+		time time.Time
+	}{
+		{"zero", time.Time{}},
+		{"utc", now.UTC()},
+		{"local", now.Local()},
+		{"fixed zone", now.In(time.FixedZone("FOO", -7200))},
+		{"location", now.In(madrid)},
+		{"before epoch", time.Date(1800, 1, 2, 3, 4, 5, 6, time.UTC)},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			ptr := tt.time
+			input := TimeTestType{
+				Time:        tt.time,
+				TimePointer: &ptr,
+				Duration:    -5 * time.Minute,
+			}
+
+			output, err := input.EncodeBinary()
+			require.NoError(err)
+
+			var result TimeTestType
+			require.NoError(result.DecodeBinaryFromBytes(output))
+
+			require.True(input.Time.Equal(result.Time))
+			require.Equal(input.Time.Location().String(), result.Time.Location().String())
+			_, expectedOffset := input.Time.Zone()
+			_, offset := result.Time.Zone()
+			require.Equal(expectedOffset, offset)
+			require.True(input.TimePointer.Equal(*result.TimePointer))
+			require.Nil(result.NilTime)
+			require.Equal(input.Duration, result.Duration)
+		})
+	}
+}
+
+func TestDelegateEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	u, err := url.Parse("https://user@example.com/foo?bar=baz#qux")
+	require.NoError(err)
+
+	input := DelegateTestType{
+		URL:       *u,
+		Int:       big.NewInt(-1234567890),
+		Foo:       bench.Foo{A: 1, B: "foo", C: []byte("bar"), E: []int{1, 2}},
+		Marshaler: MarshalerTestType{42},
+	}
+
+	output, err := input.EncodeBinary()
+	require.NoError(err)
+
+	var result DelegateTestType
+	require.NoError(result.DecodeBinaryFromBytes(output))
+
+	require.Equal(input.URL, result.URL)
+	require.Equal(0, input.Int.Cmp(result.Int))
+	require.Equal(input.Foo, result.Foo)
+	require.Equal(input.Marshaler, result.Marshaler)
+}
+
+func TestRecursiveEncodeDecode(t *testing.T) {
+	t.Run("linked list", func(t *testing.T) {
+		require := require.New(t)
+
+		input := StructCyclic{1, &StructCyclic{2, &StructCyclic{3, nil}}}
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result StructCyclic
+		require.NoError(result.DecodeBinaryFromBytes(output))
+		require.Equal(input, result)
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		require := require.New(t)
+
+		// Empty slices and maps are decoded as empty and not nil, so they
+		// need to be initialized.
+		leaf := func(v int) TreeTestType {
+			return TreeTestType{v, []TreeTestType{}, map[string]*TreeTestType{}}
+		}
+
+		l := leaf(4)
+		input := TreeTestType{
+			Value: 1,
+			Children: []TreeTestType{
+				leaf(2),
+				{
+					Value:    3,
+					Children: []TreeTestType{leaf(5)},
+					Index:    map[string]*TreeTestType{"leaf": &l},
+				},
+			},
+			Index: map[string]*TreeTestType{"leaf": &l, "nil": nil},
+		}
+
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result TreeTestType
+		require.NoError(result.DecodeBinaryFromBytes(output))
+		require.Equal(input, result)
+	})
+
+	t.Run("mutual recursion", func(t *testing.T) {
+		require := require.New(t)
+
+		input := MutualATestType{
+			Name: "a",
+			B: &MutualBTestType{
+				As: []MutualATestType{
+					{Name: "b"},
+					{Name: "c", B: &MutualBTestType{As: []MutualATestType{}}},
+				},
+			},
+		}
+
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result MutualATestType
+		require.NoError(result.DecodeBinaryFromBytes(output))
+		require.Equal(input, result)
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		require := require.New(t)
+
+		input := StructCyclic{}
+		for i := 0; i < DefaultMaxDepth; i++ {
+			next := input
+			input = StructCyclic{i, &next}
+		}
+
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result StructCyclic
+		require.Error(result.DecodeBinaryFromBytes(output))
+	})
+}
+
+func TestReferencesEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	shared := &GraphNodeTestType{Name: "shared"}
+	cycle := &GraphNodeTestType{Name: "cycle"}
+	cycle.Next = cycle
+
+	input := GraphTestType{
+		A:     shared,
+		B:     shared,
+		Nodes: []*GraphNodeTestType{cycle, shared, nil},
+	}
+
+	output, err := input.EncodeBinary()
+	require.NoError(err)
+
+	var result GraphTestType
+	require.NoError(result.DecodeBinaryFromBytes(output))
+
+	require.Equal("shared", result.A.Name)
+	require.True(result.A == result.B, "A and B should point to the same node")
+	require.True(result.Nodes[1] == result.A, "shared node should be the same in the slice")
+	require.Equal("cycle", result.Nodes[0].Name)
+	require.True(result.Nodes[0].Next == result.Nodes[0], "cycle should be preserved")
+	require.Nil(result.Nodes[2])
+}
+
+func TestTrailingMaybesEncodeDecode(t *testing.T) {
+	name := "foo"
+	full, err := (&TrailingMaybeTestType{ID: 1, Name: &name, Tags: &[]string{"bar"}}).EncodeBinary()
+	require.NoError(t, err)
+
+	s, err := Schema(Options{Types: []string{"TrailingMaybeTestType"}})
+	require.NoError(t, err)
+
+	// Data encoded before the pointers were appended ends before them.
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected TrailingMaybeTestType
+	}{
+		{"full", full, TrailingMaybeTestType{ID: 1, Name: &name, Tags: &[]string{"bar"}}},
+		{"without tags", full[:len(full)-20], TrailingMaybeTestType{ID: 1, Name: &name}},
+		{"without pointers", full[:8], TrailingMaybeTestType{ID: 1}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var result TrailingMaybeTestType
+			require.NoError(t, result.DecodeBinaryFromBytes(tt.data))
+			require.Equal(t, tt.expected, result)
+
+			var refResult TrailingMaybeTestType
+			require.NoError(t, Unmarshal(tt.data, &refResult))
+			require.Equal(t, tt.expected, refResult)
+
+			// The transcoder decodes the same values, which are encoded
+			// with all their fields.
+			v, err := dynamic.Decode(s, "TrailingMaybeTestType", tt.data)
+			require.NoError(t, err)
+			data, err := dynamic.Encode(v)
+			require.NoError(t, err)
+			expected, err := tt.expected.EncodeBinary()
+			require.NoError(t, err)
+			require.Equal(t, expected, data)
+		})
+	}
+
+	// Data ending in the middle of a pointer is still invalid.
+	var result TrailingMaybeTestType
+	require.Error(t, result.DecodeBinaryFromBytes(full[:10]))
+	require.Error(t, Unmarshal(full[:10], new(TrailingMaybeTestType)))
+	_, err = dynamic.Decode(s, "TrailingMaybeTestType", full[:10])
+	require.Error(t, err)
+
+	// Empty data has none of the fields, even if all of them are pointers,
+	// so it fails with io.EOF.
+	require.Equal(t, io.EOF, new(PointerRefsConstraintTestType).DecodeBinaryFromBytes(nil))
+	require.Equal(t, io.EOF, new(PointerRefsConstraintTestType).DecodeBinary(bytes.NewReader(nil)))
+	require.Equal(t, io.EOF, Unmarshal(nil, new(PointerRefsConstraintTestType)))
+	s, err = Schema(Options{Types: []string{"PointerRefsConstraintTestType"}, TrackReferences: true})
+	require.NoError(t, err)
+	_, err = dynamic.Decode(s, "PointerRefsConstraintTestType", nil)
+	require.Error(t, err)
+
+	// Pointers with reference tracking can be missing as well.
+	var refs PointerRefsConstraintTestType
+	require.NoError(t, refs.DecodeBinaryFromBytes([]byte{0}))
+	require.Equal(t, PointerRefsConstraintTestType{}, refs)
+
+	// Streams of values end at io.EOF.
+	stream := bytes.NewReader(append(append([]byte{}, full...), full...))
+	var n int
+	for {
+		var v TrailingMaybeTestType
+		err := v.DecodeBinary(stream)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		n++
+	}
+	require.Equal(t, 2, n)
+}
+
+func TestReferencesInvalid(t *testing.T) {
+	require := require.New(t)
+
+	input := GraphTestType{A: &GraphNodeTestType{Name: "a"}}
+	output, err := input.EncodeBinary()
+	require.NoError(err)
+
+	// Make B a reference to a value that was never encoded.
+	bs := []byte{2, 5, 0, 0, 0, 0, 0, 0, 0}
+	data := append(output[:len(output)-9], bs...)
+	data = append(data, output[len(output)-8:]...)
+
+	var result GraphTestType
+	require.Error(result.DecodeBinaryFromBytes(data))
+}
+
+func TestUnionEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	input := UnionTestType{
+		Event: &DeletedTestType{1, "spam"},
+		Events: []EventTestType{
+			CreatedTestType{2},
+			nil,
+			(*DeletedTestType)(nil),
+			&DeletedTestType{3, "duplicate"},
+		},
+		Value: IntTestType(42),
+		Expr: BinaryTestType{
+			Op:   "+",
+			Left: LiteralTestType(1),
+			Right: BinaryTestType{
+				Op:    "*",
+				Left:  LiteralTestType(2),
+				Right: LiteralTestType(3),
+			},
+		},
+	}
+
+	output, err := input.EncodeBinary()
+	require.NoError(err)
+
+	var result UnionTestType
+	require.NoError(result.DecodeBinaryFromBytes(output))
+	require.Equal(input, result)
+}
+
+func TestUnionInvalidType(t *testing.T) {
+	require := require.New(t)
+
+	_, err := UnionTestType{Value: "not in the union"}.EncodeBinary()
+	require.Error(err)
+
+	var result UnionTestType
+	require.Error(result.DecodeBinaryFromBytes([]byte{3}))
+}
+
+func TestGenericEncodeDecode(t *testing.T) {
+	t.Run("union constraints", func(t *testing.T) {
+		require := require.New(t)
+
+		input := PairTestType[string, int]{"answer", 42, []int{1, 2}}
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result PairTestType[string, int]
+		require.NoError(result.DecodeBinaryFromBytes(output))
+		require.Equal(input, result)
+	})
+
+	t.Run("method constraints", func(t *testing.T) {
+		require := require.New(t)
+
+		input := PageTestType[Int8TestType]{[]Int8TestType{1, -2, 3}, 4}
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result PageTestType[*Int8TestType]
+		require.Error(result.DecodeBinaryFromBytes(output))
+
+		var result2 PageTestType[Int8TestType]
+		require.NoError(result2.DecodeBinaryFromBytes(output))
+		require.Equal(input, result2)
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		require := require.New(t)
+
+		input := ListTestType[string]{"a", &ListTestType[string]{"b", nil}}
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result ListTestType[string]
+		require.NoError(result.DecodeBinaryFromBytes(output))
+		require.Equal(input, result)
+	})
+}
+
+func TestComplexEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	c := complex(-1.5, 2.25)
+	input := ComplexTestType{
+		C64:      complex(1, -1),
+		C128:     complex(math.Pi, math.E),
+		Samples:  []complex64{0, complex(1, 2), complex(-3, 4)},
+		Wide:     []complex128{complex(math.Inf(1), -0.5)},
+		Named:    []IQSampleTestType{complex(0.5, 0.25)},
+		Optional: &c,
+		Fixed:    [2]complex64{1i, -1i},
+		Bounded:  []complex64{},
+	}
+
+	output, err := input.EncodeBinary()
+	require.NoError(err)
+
+	var result ComplexTestType
+	require.NoError(result.DecodeBinaryFromBytes(output))
+	require.Equal(input, result)
+
+	input.Bounded = make([]complex64, 5)
+	output, err = input.EncodeBinary()
+	require.NoError(err)
+	require.Error(result.DecodeBinaryFromBytes(output))
+}
+
+func TestEmbeddedEncodeDecode(t *testing.T) {
+	t.Run("flattened", func(t *testing.T) {
+		require := require.New(t)
+
+		str := StringTestType("foo")
+		input := EmbeddedTestType{
+			BaseTestType:   BaseTestType{1, "bar"},
+			AuditTestType:  &AuditTestType{LevelTestType{2}, "baz"},
+			StringTestType: &str,
+			EventTestType:  CreatedTestType{3},
+			Value:          "qux",
+		}
+
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result EmbeddedTestType
+		require.NoError(result.DecodeBinaryFromBytes(output))
+		require.Equal(input, result)
+	})
+
+	t.Run("nil flattened pointer", func(t *testing.T) {
+		require := require.New(t)
+
+		input := EmbeddedTestType{BaseTestType: BaseTestType{Name: "a"}}
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result EmbeddedTestType
+		require.NoError(result.DecodeBinaryFromBytes(output))
+		input.AuditTestType = new(AuditTestType)
+		require.Equal(input, result)
+	})
+
+	t.Run("inherited constraints", func(t *testing.T) {
+		require := require.New(t)
+
+		input := EmbeddedTestType{BaseTestType: BaseTestType{Name: "too long"}}
+		output, err := input.EncodeBinary()
+		require.NoError(err)
+
+		var result EmbeddedTestType
+		require.Error(result.DecodeBinaryFromBytes(output))
+	})
+}
+
+func TestAccessorEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	input := AccessorTestType{
+		Account: testpkg.NewAccount(1, "foo", -100),
+		Accounts: []testpkg.Account{
+			testpkg.NewAccount(2, "bar", 200),
+			testpkg.NewAccount(3, "baz", 0),
+		},
+		Token: testpkg.NewToken("secret"),
+	}
+
+	output, err := input.EncodeBinary()
+	require.NoError(err)
+
+	var result AccessorTestType
+	require.NoError(result.DecodeBinaryFromBytes(output))
+	require.Equal(input, result)
+}
+
+func TestFunctionsEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	notes := "paid"
+	input := testpkg.Invoice{
+		Number:  1,
+		Account: testpkg.NewAccount(2, "foo", -100),
+		Lines: []testpkg.Line{
+			{Concept: "foo", Amount: 10},
+			{Concept: "bar", Amount: -5},
+		},
+		Notes: &notes,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(EncodeInvoice(&buf, &input))
+
+	var result testpkg.Invoice
+	require.NoError(DecodeInvoice(&buf, &result))
+	require.Equal(input, result)
+
+	// Functions encode types the same way methods do.
+	account := AccessorTestType{Account: input.Account}
+	expected, err := account.EncodeBinary()
+	require.NoError(err)
+
+	buf.Reset()
+	require.NoError(EncodeAccount(&buf, &input.Account))
+	require.Equal(expected[:buf.Len()], buf.Bytes())
+
+	// Types of unions are the ones of the package declaring them.
+	receipt := testpkg.Receipt{
+		Payment: &testpkg.Card{Number: "1234"},
+		Refunds: []testpkg.Payment{testpkg.Cash{Amount: 5}},
+	}
+
+	buf.Reset()
+	require.NoError(EncodeReceipt(&buf, &receipt))
+
+	var receiptResult testpkg.Receipt
+	require.NoError(DecodeReceipt(&buf, &receiptResult))
+	require.Equal(receipt, receiptResult)
+}
+
+func TestSelectedMethodsEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	input := RenamedTestType{Name: "foo", Items: []int{1, -2, 3}}
+	var buf bytes.Buffer
+	require.NoError(input.Encode(&buf))
+
+	// The bytes methods contain the same code as the stream methods.
+	output, err := EncodeOnlyTestType(input).EncodeBinary()
+	require.NoError(err)
+	require.Equal(buf.Bytes(), output)
+
+	output, err = BytesOnlyTestType(input).EncodeBinary()
+	require.NoError(err)
+	require.Equal(buf.Bytes(), output)
+
+	var bytesResult BytesOnlyTestType
+	require.NoError(bytesResult.DecodeBinaryFromBytes(output))
+	require.Equal(BytesOnlyTestType(input), bytesResult)
+	require.Error(bytesResult.DecodeBinaryFromBytes(output[:len(output)-1]))
+
+	var result RenamedTestType
+	require.NoError(result.Decode(&buf))
+	require.Equal(input, result)
+	require.Equal("foo", result.EncodeBinary())
+}
+
+func TestAliasEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	input := AliasTestType{
+		V1:     []model.User{{Name: "foo"}},
+		V2:     []modelv2.User{{Name: "bar", Email: "bar@example.com"}},
+		Points: []data.Point{{X: 1, Y: -1}},
+	}
+
+	output, err := input.EncodeBinary()
+	require.NoError(err)
+
+	var result AliasTestType
+	require.NoError(result.DecodeBinaryFromBytes(output))
+	require.Equal(input, result)
+}
@@ -0,0 +1,770 @@
+// WARNING! This is code generated by bindec, do not modify manually.
+
+package bindec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/erizocosmico/bindec/internal/testpkg"
+)
+
+var _ = binary.LittleEndian
+var _ = math.Abs
+
+// EncodeInvoice writes the binary-encoded representation of the given value
+// to the writer.
+func EncodeInvoice(writer io.Writer, v *testpkg.Invoice) error {
+	t := *v
+	{
+
+		{
+			x := t.Number
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+		{
+
+			{
+				x := t.Account.ID
+				ux := uint64(x) << 1
+				if x < 0 {
+					ux = ^ux
+				}
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint64(bs, ux)
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+			}
+
+			{
+				v := t.Account.Owner()
+				n := len(v)
+				ux := uint64(n) << 1
+				if n < 0 {
+					ux = ^ux
+				}
+				sz := make([]byte, 8)
+				binary.LittleEndian.PutUint64(sz, ux)
+				if _, err := writer.Write(sz); err != nil {
+					return err
+				}
+
+				_, err := writer.Write([]byte(v))
+				if err != nil {
+					return err
+				}
+			}
+
+			{
+				x := t.Account.Balance()
+				ux := uint64(x) << 1
+				if x < 0 {
+					ux = ^ux
+				}
+				bs := make([]byte, 8)
+				binary.LittleEndian.PutUint64(bs, ux)
+				_, err := writer.Write(bs)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		{
+			n := len(t.Lines)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+
+				{
+					v := t.Lines[i].Concept
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+				{
+					x := t.Lines[i].Amount
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		{
+			if x := t.Notes; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else {
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := (*t.Notes)
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeInvoice reads the binary representation of the type from the given
+// reader and fills the given value with it.
+func DecodeInvoice(reader io.Reader, v *testpkg.Invoice) error {
+	t := v
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.Number = int(x)
+
+		}
+		{
+
+			{
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint64(bs)
+				x := int64(ux >> 1)
+				if ux&1 != 0 {
+					x = ^x
+				}
+				t.Account.ID = int(x)
+
+			}
+			{
+				var tmp_t_Account_owner string
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_Account_owner = string(b)
+
+				}
+
+				t.Account.SetOwner(tmp_t_Account_owner)
+			}
+			{
+				var tmp_t_Account_balance int64
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					tmp_t_Account_balance = int64(x)
+
+				}
+
+				t.Account.SetBalance(tmp_t_Account_balance)
+			}
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Lines = make([]testpkg.Line, sz)
+
+			for i := 0; i < sz; i++ {
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					(t.Lines)[i].Concept = string(b)
+
+				}
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					(t.Lines)[i].Amount = int64(x)
+
+				}
+			}
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			if v[0] == 0 {
+				t.Notes = nil
+			} else {
+				var tmp_t_Notes string
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					tmp_t_Notes = string(b)
+
+				}
+
+				t.Notes = &tmp_t_Notes
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// EncodeAccount writes the binary-encoded representation of the given value
+// to the writer.
+func EncodeAccount(writer io.Writer, v *testpkg.Account) error {
+	t := *v
+	{
+
+		{
+			x := t.ID
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			v := t.Owner()
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			_, err := writer.Write([]byte(v))
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			x := t.Balance()
+			ux := uint64(x) << 1
+			if x < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeAccount reads the binary representation of the type from the given
+// reader and fills the given value with it.
+func DecodeAccount(reader io.Reader, v *testpkg.Account) error {
+	t := v
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+			t.ID = int(x)
+
+		}
+		{
+			var tmp_t_owner string
+
+			{
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint64(bs)
+				x := int64(ux >> 1)
+				if ux&1 != 0 {
+					x = ^x
+				}
+
+				sz := int(x)
+
+				b := make([]byte, sz)
+				if _, err := io.ReadFull(reader, b); err != nil {
+					return err
+				}
+
+				tmp_t_owner = string(b)
+
+			}
+
+			t.SetOwner(tmp_t_owner)
+		}
+		{
+			var tmp_t_balance int64
+
+			{
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				ux := binary.LittleEndian.Uint64(bs)
+				x := int64(ux >> 1)
+				if ux&1 != 0 {
+					x = ^x
+				}
+				tmp_t_balance = int64(x)
+
+			}
+
+			t.SetBalance(tmp_t_balance)
+		}
+	}
+
+	return nil
+}
+
+// EncodeReceipt writes the binary-encoded representation of the given value
+// to the writer.
+func EncodeReceipt(writer io.Writer, v *testpkg.Receipt) error {
+	t := *v
+	{
+
+		{
+			switch u := t.Payment.(type) {
+			case nil:
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+
+			case testpkg.Cash:
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+
+					{
+						x := u.Amount
+						ux := uint64(x) << 1
+						if x < 0 {
+							ux = ^ux
+						}
+						bs := make([]byte, 8)
+						binary.LittleEndian.PutUint64(bs, ux)
+						_, err := writer.Write(bs)
+						if err != nil {
+							return err
+						}
+					}
+				}
+
+			case *testpkg.Card:
+				if _, err := writer.Write([]byte{2}); err != nil {
+					return err
+				}
+
+				{
+					if x := u; x == nil {
+						if _, err := writer.Write([]byte{0}); err != nil {
+							return err
+						}
+					} else {
+						if _, err := writer.Write([]byte{1}); err != nil {
+							return err
+						}
+
+						{
+
+							{
+								v := (*u).Number
+								n := len(v)
+								ux := uint64(n) << 1
+								if n < 0 {
+									ux = ^ux
+								}
+								sz := make([]byte, 8)
+								binary.LittleEndian.PutUint64(sz, ux)
+								if _, err := writer.Write(sz); err != nil {
+									return err
+								}
+
+								_, err := writer.Write([]byte(v))
+								if err != nil {
+									return err
+								}
+							}
+						}
+
+					}
+				}
+
+			default:
+				return fmt.Errorf("type %T is not part of the union", u)
+			}
+		}
+
+		{
+			n := len(t.Refunds)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				switch u := t.Refunds[i].(type) {
+				case nil:
+					if _, err := writer.Write([]byte{0}); err != nil {
+						return err
+					}
+
+				case testpkg.Cash:
+					if _, err := writer.Write([]byte{1}); err != nil {
+						return err
+					}
+
+					{
+
+						{
+							x := u.Amount
+							ux := uint64(x) << 1
+							if x < 0 {
+								ux = ^ux
+							}
+							bs := make([]byte, 8)
+							binary.LittleEndian.PutUint64(bs, ux)
+							_, err := writer.Write(bs)
+							if err != nil {
+								return err
+							}
+						}
+					}
+
+				default:
+					return fmt.Errorf("type %T is not part of the union", u)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeReceipt reads the binary representation of the type from the given
+// reader and fills the given value with it.
+func DecodeReceipt(reader io.Reader, v *testpkg.Receipt) error {
+	t := v
+	{
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.Payment = nil
+
+			case 1:
+				var tmp_t_Payment testpkg.Cash
+				{
+
+					{
+						var bs = make([]byte, 8)
+						if _, err := io.ReadFull(reader, bs); err != nil {
+							return err
+						}
+
+						ux := binary.LittleEndian.Uint64(bs)
+						x := int64(ux >> 1)
+						if ux&1 != 0 {
+							x = ^x
+						}
+						tmp_t_Payment.Amount = int64(x)
+
+					}
+				}
+
+				t.Payment = tmp_t_Payment
+
+			case 2:
+				var tmp_t_Payment *testpkg.Card
+
+				{
+					var v = make([]byte, 1)
+					if _, err := io.ReadFull(reader, v); err != nil {
+						return err
+					}
+
+					if v[0] == 0 {
+						tmp_t_Payment = nil
+					} else {
+						var tmp_tmp_t_Payment testpkg.Card
+						{
+
+							{
+								var bs = make([]byte, 8)
+								if _, err := io.ReadFull(reader, bs); err != nil {
+									return err
+								}
+
+								ux := binary.LittleEndian.Uint64(bs)
+								x := int64(ux >> 1)
+								if ux&1 != 0 {
+									x = ^x
+								}
+
+								sz := int(x)
+
+								b := make([]byte, sz)
+								if _, err := io.ReadFull(reader, b); err != nil {
+									return err
+								}
+
+								tmp_tmp_t_Payment.Number = string(b)
+
+							}
+						}
+
+						tmp_t_Payment = &tmp_tmp_t_Payment
+
+					}
+				}
+
+				t.Payment = tmp_t_Payment
+
+			default:
+				return fmt.Errorf("invalid type for union testpkg.Payment: %d", v[0])
+			}
+
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Refunds = make([]testpkg.Payment, sz)
+
+			for i := 0; i < sz; i++ {
+				var v = make([]byte, 1)
+				if _, err := io.ReadFull(reader, v); err != nil {
+					return err
+				}
+
+				switch v[0] {
+				case 0:
+					(t.Refunds)[i] = nil
+
+				case 1:
+					var tmp__t_Refunds__i_ testpkg.Cash
+					{
+
+						{
+							var bs = make([]byte, 8)
+							if _, err := io.ReadFull(reader, bs); err != nil {
+								return err
+							}
+
+							ux := binary.LittleEndian.Uint64(bs)
+							x := int64(ux >> 1)
+							if ux&1 != 0 {
+								x = ^x
+							}
+							tmp__t_Refunds__i_.Amount = int64(x)
+
+						}
+					}
+
+					(t.Refunds)[i] = tmp__t_Refunds__i_
+
+				default:
+					return fmt.Errorf("invalid type for union testpkg.Payment: %d", v[0])
+				}
+
+			}
+
+		}
+	}
+
+	return nil
+}
@@ -1,55 +1,332 @@
 package bindec
 
 import (
+	"bytes"
 	"fmt"
+	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
 )
 
 // Options to configure generation.
 type Options struct {
-	// Path of the package in which the type is located.
+	// Path of the package in which the type is located, or the package in
+	// which the functions are generated if Package is given.
 	Path string
-	// Types to generate encoder and decoder for.
+	// Package is the import path of the package in which the types are
+	// declared, if it's not the one at Path. It must be importable from the
+	// package at Path, and it requires Functions, as methods can't be
+	// declared on types of other packages.
+	Package string
+	// Functions generates EncodeTYPE and DecodeTYPE functions instead of
+	// methods of the types. Receiver names are not used.
+	Functions bool
+	// Methods are the methods to generate, by their default names:
+	// EncodeBinary, WriteBinary, DecodeBinaryFromBytes and DecodeBinary.
+	// The values are the names given to them, or empty to keep the default
+	// ones. If there are none, all of them are generated. When generating
+	// functions, WriteBinary and DecodeBinary are the EncodeTYPE and
+	// DecodeTYPE functions, and the rest are ignored. See ParseMethods.
+	Methods map[string]string
+	// Types to generate encoder and decoder for. If there are none, all
+	// types with a //bindec:generate directive in the package are
+	// generated.
 	Types []string
-	// Recvs are the receiver names for the generated methods.
+	// Recvs are the receiver names for the generated methods, in the same
+	// order as Types. If there are none, t is used for all of them.
 	Recvs []string
+	// MaxDepth is the maximum depth of recursive types that will be
+	// decoded before failing, to avoid exhausting the stack with malicious
+	// input. If it's 0, DefaultMaxDepth is used.
+	MaxDepth int
+	// TrackReferences enables reference tracking for pointers. Values
+	// pointed to more than once are only encoded the first time, and
+	// decoded values share them the same way the encoded ones did.
+	TrackReferences bool
+	// Tags are the build tags used to load the package.
+	Tags []string
+	// TypeOptions are the options of specific types, by type name. They
+	// take precedence over Recvs and the other options given for all
+	// types, as well as over the options of //bindec:generate directives.
+	TypeOptions map[string]TypeOptions
+}
+
+// TypeOptions are the options to generate a specific type.
+type TypeOptions struct {
+	// Recv is the receiver name for the generated methods. If it's empty,
+	// the receiver name is not changed.
+	Recv string
+	// MaxDepth is the maximum depth of recursive types that will be
+	// decoded. If it's 0, the maximum depth is not changed.
+	MaxDepth int
+	// TrackReferences enables reference tracking for the type.
+	TrackReferences bool
+	// Methods are the methods to generate for the type. If there are none,
+	// the methods are not changed.
+	Methods map[string]string
 }
 
+// DefaultMaxDepth is the default maximum depth of recursive types decoded.
+const DefaultMaxDepth = 10000
+
 // Generate a file of source code containing an encoder and a decoder to
 // encode and decode a given type to and from a binary representation of
 // itself.
 func Generate(opts Options) ([]byte, error) {
-	pkg, err := getPackage(opts.Path)
+	pkg, err := optionsPackage(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := newParseContext()
+	return generate(pkg, opts)
+}
+
+// GenerateFiles is like Generate, but the code of the types declared in
+// test files is returned apart from the rest, so it can be written to a test
+// file while the rest of the types keep their methods in non-test builds.
+// Either of them is nil if there are no types to generate in it.
+func GenerateFiles(opts Options) (code, testCode []byte, err error) {
+	pkg, err := optionsPackage(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return generateFiles(pkg, opts, true)
+}
+
+// optionsPackage loads the package at the path of the options, along with
+// the package declaring the types if it's not that one.
+func optionsPackage(opts Options) (*packageInfo, error) {
+	if opts.Package != "" {
+		return getPackageWithSource(opts.Path, opts.Tags, opts.Package)
+	}
+	return getPackage(opts.Path, opts.Tags)
+}
+
+// PackageResult is the result of generating the code of one of the packages
+// given to GeneratePackages.
+type PackageResult struct {
+	// Path is the import path of the package.
+	Path string
+	// Dir is the directory of the package.
+	Dir string
+	// Types are the names of the generated types. If there are none, the
+	// package was skipped because it had no types to generate.
+	Types []string
+	// Code is the code generated for the types declared in non-test files,
+	// or nil if there are none.
+	Code []byte
+	// TestCode is the code generated for the types declared in test files,
+	// which must be written to a test file, or nil if there are none.
+	TestCode []byte
+	// Err is the error generating the package, if any.
+	Err error
+}
+
+// GeneratePackages generates the code of all the types with a
+// //bindec:generate directive in the packages matching the given patterns,
+// such as "./...", which are relative to the path in the options. Types,
+// Recvs, TypeOptions and Package of the options are ignored. Packages are
+// generated in parallel and the results are returned in the same order the
+// packages were loaded.
+func GeneratePackages(opts Options, patterns ...string) ([]PackageResult, error) {
+	pkgs, err := loadPackages(opts.Path, opts.Tags, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Types = nil
+	opts.Recvs = nil
+	opts.TypeOptions = nil
+	opts.Package = ""
+
+	var results = make([]PackageResult, len(pkgs))
+	var wg sync.WaitGroup
+	var next = make(chan int)
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = generatePackage(pkgs[i], opts)
+			}
+		}()
+	}
+
+	for i := range pkgs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results, nil
+}
+
+func generatePackage(p *packages.Package, opts Options) PackageResult {
+	result := PackageResult{Path: p.PkgPath}
+	pkg, err := newPackageInfo(p)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Dir = pkg.dir
+	objs := pkg.typesWithDirective("generate")
+	if len(objs) == 0 {
+		return result
+	}
+
+	for _, obj := range objs {
+		result.Types = append(result.Types, obj.Name())
+	}
+
+	result.Code, result.TestCode, result.Err = generateFiles(pkg, opts, true)
+	return result
+}
+
+func generate(pkg *packageInfo, opts Options) ([]byte, error) {
+	code, _, err := generateFiles(pkg, opts, false)
+	return code, err
+}
+
+// generateFiles returns the code generated for the types of the package. If
+// splitTests is true, the code of the types declared in test files is
+// returned apart from the rest as testCode. Either of them is nil if there
+// are no types to generate in it.
+func generateFiles(pkg *packageInfo, opts Options, splitTests bool) (code, testCode []byte, err error) {
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	if pkg.source != nil && !opts.Functions {
+		return nil, nil, fmt.Errorf(
+			"types of package %s can only be generated as functions, methods can't be declared on types of other packages",
+			pkg.source.Path(),
+		)
+	}
+
+	targets, typs, err := findTargets(pkg, opts, maxDepth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkConflicts(pkg, targets, typs, opts.Functions); err != nil {
+		return nil, nil, err
+	}
+
+	ctx := newParseContext(pkg)
+	for _, t := range targets {
+		ctx.reserved[t.recv] = true
+	}
 	ctx.addImport("encoding/binary")
 	ctx.addImport("bytes")
 	ctx.addImport("io")
 	ctx.addImport("math")
 
-	var methods = make([]string, len(opts.Types))
-	for i, tName := range opts.Types {
-		recv := opts.Recvs[i]
+	parsed, err := parseTargets(ctx, targets, typs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkHelperConflicts(pkg, parsed); err != nil {
+		return nil, nil, err
+	}
 
-		typ, err := findType(pkg, tName)
-		if err != nil {
-			return nil, err
+	source := pkg
+	if pkg.source != nil {
+		source = pkg.source
+	}
+
+	var methods, testMethods []string
+	var decls, testDecls = make(map[string]struct{}), make(map[string]struct{})
+	for i, p := range parsed {
+		var m string
+		if opts.Functions {
+			m = generateFunctions(p.ctx, p.target, typeName(p.ctx, typs[i]), p.typ)
+		} else {
+			m = generateMethods(p.ctx, p.target, typeName(p.ctx, typs[i]), p.typ)
 		}
 
-		t, err := parseType(ctx, typ)
-		if err != nil {
-			return nil, err
+		named, ok := typs[i].(*types.Named)
+		if splitTests && ok && source.inTestFile(named.Obj().Pos()) {
+			testMethods = append(testMethods, m)
+			addDecls(testDecls, p.ctx.decls)
+		} else {
+			methods = append(methods, m)
+			addDecls(decls, p.ctx.decls)
 		}
+	}
+
+	// Test files are compiled along with the rest of the package, so they
+	// can't declare the same variables.
+	for d := range decls {
+		delete(testDecls, d)
+	}
+
+	if len(methods) > 0 {
+		if code, err = generateSource(ctx, methods, decls); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(testMethods) > 0 {
+		if testCode, err = generateSource(ctx, testMethods, testDecls); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return code, testCode, nil
+}
 
-		methods[i] = generateMethods(recv, tName, t)
+func addDecls(dst, src map[string]struct{}) {
+	for d := range src {
+		dst[d] = struct{}{}
+	}
+}
+
+// generateSource returns the formatted source code of a file with the given
+// methods and declarations, importing the packages of the context used by
+// them.
+func generateSource(ctx *parseContext, methods []string, decls map[string]struct{}) ([]byte, error) {
+	imports := ctx.imports
+	ctx = ctx.clone()
+	ctx.decls = decls
+	ctx.imports = make(map[string]string, len(imports))
+	for path, name := range imports {
+		ctx.imports[path] = name
 	}
 
 	src := []byte(generateFile(
-		pkg.Name(),
+		ctx.pkg.Name(),
+		strings.Join(methods, "\n"),
+		ctx.getImports(),
+		ctx.getDecls(),
+	))
+
+	// Imported named types, for example, are not always referenced by the
+	// code generated for them, so unused imports are removed.
+	used, err := usedNames(src)
+	if err != nil {
+		return nil, fmt.Errorf("error formatting code: %s\n\n%s", err, prettySource(src))
+	}
+
+	for path, name := range ctx.imports {
+		if !used[name] {
+			delete(ctx.imports, path)
+		}
+	}
+
+	src = []byte(generateFile(
+		ctx.pkg.Name(),
 		strings.Join(methods, "\n"),
 		ctx.getImports(),
 		ctx.getDecls(),
@@ -63,14 +340,313 @@ func Generate(opts Options) ([]byte, error) {
 	return formatted, nil
 }
 
-func generateMethods(recv, typeName string, typ Type) string {
-	return fmt.Sprintf(
-		methodsTpl,
-		recv,
-		typeName,
-		typ.Encoder(recv),
-		typ.Decoder(recv, true),
-	)
+// findTargets returns the types to generate methods for, with their
+// methods validated, along with their types, which are looked up in the
+// package declaring them.
+func findTargets(pkg *packageInfo, opts Options, maxDepth int) ([]target, []types.Type, error) {
+	source := pkg
+	if pkg.source != nil {
+		source = pkg.source
+	}
+
+	targets, err := generationTargets(source, opts, maxDepth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range targets {
+		if opts.Functions {
+			targets[i].recv = functionsRecv
+		}
+
+		targets[i].methods, err = validateMethods(targets[i].methods, opts.Functions)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid methods of type %s: %s", targets[i].name, err)
+		}
+	}
+
+	var typs = make([]types.Type, len(targets))
+	for i, target := range targets {
+		typs[i], err = findType(source, target.name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if named, ok := typs[i].(*types.Named); ok && opts.Functions && named.TypeParams().Len() > 0 {
+			return nil, nil, fmt.Errorf("can't generate functions for generic type %s, generate methods for it instead", target.name)
+		}
+	}
+
+	return targets, typs, nil
+}
+
+// parsedTarget is a target whose type has been parsed.
+type parsedTarget struct {
+	target
+	// ctx is the context the type was parsed with, which contains the
+	// helpers of its recursive types.
+	ctx *parseContext
+	typ Type
+}
+
+// parseTargets parses the types of the given targets with the given
+// context. All the types are parsed before failing, so the problems of all
+// of them are reported at once as Diagnostics.
+func parseTargets(ctx *parseContext, targets []target, typs []types.Type) ([]parsedTarget, error) {
+	var parsed = make([]parsedTarget, len(targets))
+	var diags Diagnostics
+	for i, target := range targets {
+		typ := typs[i]
+		rootCtx := ctx.forRoot(target.name, typ)
+		rootCtx.refs = target.refs
+		t, err := parseType(rootCtx, typ)
+		if err != nil {
+			var pos token.Pos
+			if named, ok := typ.(*types.Named); ok {
+				pos = named.Obj().Pos()
+			}
+
+			diags = append(diags, typeDiagnostics(ctx.pkg, target.name, pos, err)...)
+			continue
+		}
+
+		parsed[i] = parsedTarget{target, rootCtx, t}
+	}
+
+	if len(diags) > 0 {
+		return nil, diags
+	}
+	return parsed, nil
+}
+
+// target is a type to generate methods for.
+type target struct {
+	name     string
+	recv     string
+	refs     bool
+	maxDepth int
+	// methods are the methods to generate, as in Options.Methods.
+	methods map[string]string
+}
+
+// generationTargets returns the types to generate methods for. If no types
+// are given in the options, all types with a generate directive in the
+// package are generated.
+func generationTargets(pkg *packageInfo, opts Options, maxDepth int) ([]target, error) {
+	if len(opts.Types) > 0 {
+		if len(opts.Recvs) > 0 && len(opts.Recvs) != len(opts.Types) {
+			return nil, fmt.Errorf("got %d receivers, but %d types to generate", len(opts.Recvs), len(opts.Types))
+		}
+
+		var targets = make([]target, len(opts.Types))
+		for i, name := range opts.Types {
+			targets[i] = target{name, "t", opts.TrackReferences, maxDepth, opts.Methods}
+			if len(opts.Recvs) > 0 {
+				targets[i].recv = opts.Recvs[i]
+			}
+			applyTypeOptions(&targets[i], opts.TypeOptions[name])
+		}
+		return targets, nil
+	}
+
+	var targets []target
+	for _, obj := range pkg.typesWithDirective("generate") {
+		t := target{obj.Name(), "t", opts.TrackReferences, maxDepth, opts.Methods}
+		for _, args := range pkg.directives(obj, "generate") {
+			if err := parseGenerateDirective(&t, args); err != nil {
+				return nil, fmt.Errorf("invalid generate directive of type %s: %s", obj.Name(), err)
+			}
+		}
+		applyTypeOptions(&t, opts.TypeOptions[obj.Name()])
+		targets = append(targets, t)
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no types to generate in %s, add a //bindec:generate directive to them", pkg.Path())
+	}
+
+	return targets, nil
+}
+
+// parseGenerateDirective parses the options of a generate directive, which
+// can be recv=NAME, refs, maxdepth=N and methods=METHODS, in the format
+// accepted by ParseMethods.
+func parseGenerateDirective(t *target, args string) error {
+	for _, opt := range strings.Fields(args) {
+		parts := strings.SplitN(opt, "=", 2)
+		switch {
+		case parts[0] == "recv" && len(parts) == 2 && token.IsIdentifier(parts[1]):
+			t.recv = parts[1]
+		case opt == "refs":
+			t.refs = true
+		case parts[0] == "maxdepth" && len(parts) == 2:
+			n, err := strconv.Atoi(parts[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("maxdepth must be a positive number, got %q", parts[1])
+			}
+			t.maxDepth = n
+		case parts[0] == "methods" && len(parts) == 2:
+			methods, err := ParseMethods(parts[1])
+			if err != nil {
+				return err
+			}
+			t.methods = methods
+		default:
+			return fmt.Errorf("unknown option %q", opt)
+		}
+	}
+	return nil
+}
+
+// applyTypeOptions overrides the options of the target with the ones given
+// for its type.
+func applyTypeOptions(t *target, opts TypeOptions) {
+	if opts.Recv != "" {
+		t.recv = opts.Recv
+	}
+	if opts.MaxDepth > 0 {
+		t.maxDepth = opts.MaxDepth
+	}
+	if opts.TrackReferences {
+		t.refs = true
+	}
+	if len(opts.Methods) > 0 {
+		t.methods = opts.Methods
+	}
+}
+
+// usedNames returns the identifiers that are used as the operand of a
+// selector in the source code, such as the names of the imported packages.
+func usedNames(src []byte) (map[string]bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return used, nil
+}
+
+// functionsRecv is the name given to the value being encoded or decoded by
+// the generated functions, which have no receiver.
+const functionsRecv = "t"
+
+func generateFunctions(
+	ctx *parseContext,
+	target target,
+	typeName string,
+	typ Type,
+) string {
+	encoder, decoder := generateCode(ctx, functionsRecv, typ)
+
+	var buf bytes.Buffer
+	_, encode := target.methods["WriteBinary"]
+	if encode {
+		fmt.Fprintf(&buf, encodeFunctionTpl, functionsRecv, typeName, encoder, target.methodName("WriteBinary", true))
+	}
+
+	_, decode := target.methods["DecodeBinary"]
+	if decode {
+		fmt.Fprintf(&buf, decodeFunctionTpl, functionsRecv, typeName, decoder, target.methodName("DecodeBinary", true))
+	}
+
+	buf.WriteString(generateHelpers(ctx, functionsRecv, target.maxDepth, encode, decode))
+	return buf.String()
+}
+
+func generateMethods(
+	ctx *parseContext,
+	target target,
+	typeName string,
+	typ Type,
+) string {
+	encoder, decoder := generateCode(ctx, target.recv, typ)
+	name := func(method string) string {
+		return target.methodName(method, false)
+	}
+
+	// Methods returning and taking bytes call the ones using streams, or
+	// contain their code if they are not generated.
+	var buf bytes.Buffer
+	_, stream := target.methods["WriteBinary"]
+	_, bytesMethod := target.methods["EncodeBinary"]
+	if bytesMethod && stream {
+		fmt.Fprintf(&buf, encodeBytesTpl, target.recv, typeName, name("EncodeBinary"), name("WriteBinary"))
+	} else if bytesMethod {
+		fmt.Fprintf(&buf, encodeBytesInlineTpl, target.recv, typeName, name("EncodeBinary"), encoder)
+	}
+
+	if stream {
+		fmt.Fprintf(&buf, writeTpl, target.recv, typeName, name("WriteBinary"), encoder)
+	}
+	encode := bytesMethod || stream
+
+	_, stream = target.methods["DecodeBinary"]
+	_, bytesMethod = target.methods["DecodeBinaryFromBytes"]
+	if bytesMethod && stream {
+		fmt.Fprintf(&buf, decodeBytesTpl, target.recv, typeName, name("DecodeBinaryFromBytes"), name("DecodeBinary"))
+	} else if bytesMethod {
+		fmt.Fprintf(&buf, decodeBytesInlineTpl, target.recv, typeName, name("DecodeBinaryFromBytes"), decoder)
+	}
+
+	if stream {
+		fmt.Fprintf(&buf, decodeTpl, target.recv, typeName, name("DecodeBinary"), decoder)
+	}
+	decode := bytesMethod || stream
+
+	buf.WriteString(generateHelpers(ctx, target.recv, target.maxDepth, encode, decode))
+	return buf.String()
+}
+
+// generateCode returns the code to encode and decode the type, which is
+// referred to by the given receiver name.
+func generateCode(ctx *parseContext, recv string, typ Type) (encoder, decoder string) {
+	// Helpers of types whose references are tracked take them even if
+	// they don't contain pointers.
+	if *ctx.refsUsed || (ctx.refs && len(ctx.helpers) > 0) {
+		encoder = "refs := make(map[interface{}]uint64)\n"
+		decoder = "refs := new([]interface{})\n"
+	}
+
+	if len(ctx.helpers) > 0 {
+		decoder += "var depth int\n"
+	}
+
+	if s, ok := typ.(Struct); ok {
+		return encoder + typ.Encoder(recv), decoder + s.rootDecoder(recv)
+	}
+	return encoder + typ.Encoder(recv), decoder + typ.Decoder(recv, true)
+}
+
+// generateHelpers returns the code declaring the helpers to encode and
+// decode the recursive types of the given context, if encode and decode
+// are true, respectively. They are package-level functions shared by all
+// the generated methods, which call themselves and each other.
+func generateHelpers(ctx *parseContext, recv string, maxDepth int, encode, decode bool) string {
+	var buf bytes.Buffer
+	for _, h := range ctx.getHelpers() {
+		var refs, refsPtr string
+		if h.Refs {
+			refs, refsPtr = "refs map[interface{}]uint64, ", "refs *[]interface{}, "
+		}
+
+		if encode {
+			fmt.Fprintf(&buf, encodeHelperTpl, h.Name, recv, h.TypeName, h.Type.Encoder(recv), h.TypeParams, refs)
+		}
+
+		if decode {
+			fmt.Fprintf(&buf, decodeHelperTpl, h.Name, recv, h.TypeName, h.Type.Decoder(recv, true), maxDepth, h.TypeParams, refsPtr)
+		}
+	}
+	return buf.String()
 }
 
 func generateFile(
@@ -79,16 +655,11 @@ func generateFile(
 	imports []string,
 	decls []string,
 ) string {
-	var deps = make([]string, len(imports))
-	for i, x := range imports {
-		deps[i] = fmt.Sprintf("%q", x)
-	}
-
 	return fmt.Sprintf(
 		fileTpl,
 		pkgName,
 		methods,
-		strings.Join(deps, "\n"),
+		strings.Join(imports, "\n"),
 		strings.Join(decls, "\n"),
 	)
 }
@@ -1,12 +1,15 @@
 package bindec
 
 import (
+	"errors"
 	"go/ast"
 	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -54,7 +57,508 @@ func TestGenerateCyclic(t *testing.T) {
 		Types: []string{"StructCyclic"},
 		Recvs: []string{"t"},
 	})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestGenerateRecursiveHelpers(t *testing.T) {
+	path, err := filepath.Abs(".")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	data, err := Generate(Options{
+		Path:  path,
+		Types: []string{"TreeTestType", "ListTestType"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Helpers are declared once and called by the methods, instead of being
+	// declared in each of them.
+	code := string(data)
+	for decl, count := range map[string]int{
+		"func encodeTreeTestType(":                  1,
+		"func decodeTreeTestType(":                  1,
+		"func encodeListTestType[T int | string](":  1,
+		"func decodeListTestType[T int | string](":  1,
+		"encodeTreeTestType(writer, t)":             1,
+		"decodeTreeTestType(reader, t, depth+1)":    1,
+		"encodeListTestType[T](writer, t)":          1,
+		"decodeListTestType[T](reader, t, depth+1)": 1,
+	} {
+		if n := strings.Count(code, decl); n != count {
+			t.Errorf("expected %q %d times, got %d", decl, count, n)
+		}
+	}
+}
+
+func TestGenerateInstantiated(t *testing.T) {
+	path, err := filepath.Abs(".")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	_, err = Generate(Options{
+		Path:  path,
+		Types: []string{"PairTestType[int, string]"},
+		Recvs: []string{"t"},
+	})
 	if err == nil {
-		t.Errorf("expected error")
+		t.Errorf("expected an error generating an instantiated type")
+	}
+}
+
+func TestGenerateEmbeddedErrors(t *testing.T) {
+	path, err := filepath.Abs(".")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		typ string
+		err string
+	}{
+		{"ConflictTestType", "field ID conflicts with field BaseTestType.ID after flattening embedded fields"},
+		{"FlattenInterfaceTestType", "embedded field EventTestType can not be flattened because it's not a struct or a pointer to a struct"},
+		{"FlattenFieldTestType", "field Base can not be flattened because it's not embedded"},
+		{"FlattenRecursiveTestType", "embedded field FlattenRecursiveTestType can not be flattened because its type is recursive"},
+		{"ForeignUnexportedTestType", "unexported field Builder.addr of type strings.Builder can not be encoded"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.typ, func(t *testing.T) {
+			_, err := Generate(Options{
+				Path:  path,
+				Types: []string{tt.typ},
+				Recvs: []string{"t"},
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestGenerateDiagnostics(t *testing.T) {
+	_, err := Generate(Options{
+		Types: []string{"DiagnosticsTestType", "FlattenFieldTestType"},
+	})
+
+	var diags Diagnostics
+	if !errors.As(err, &diags) {
+		t.Fatalf("expected diagnostics, got: %v", err)
+	}
+
+	expected := []struct {
+		typ, field, message string
+		tag                 bool
+	}{
+		{"DiagnosticsTestType", "Ch", "channel type", false},
+		{"DiagnosticsTestType", "Name", "invalid struct tag", true},
+		{"DiagnosticsTestType", "Fn", "function type", false},
+		{"DiagnosticsTestType", "Inner.Ch", "channel type", false},
+		{"DiagnosticsTestType", "Count", `on constraint "max"`, true},
+		{"FlattenFieldTestType", "Base", "not embedded", true},
+	}
+
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d:\n%s", len(expected), len(diags), diags)
+	}
+
+	for i, e := range expected {
+		d := diags[i]
+		if d.Type != e.typ || d.Field != e.field || !strings.Contains(d.Message, e.message) {
+			t.Errorf("unexpected diagnostic %d: %+v", i, d)
+		}
+
+		if filepath.Base(d.Pos.Filename) != "types_test.go" || d.Pos.Line == 0 {
+			t.Errorf("unexpected position of diagnostic %d: %s", i, d.Pos)
+		}
+
+		// Struct tags are after the field name and type.
+		if tag := d.Pos.Column > 10; tag != e.tag {
+			t.Errorf("expected position of diagnostic %d to be of the tag: %v, got: %s", i, e.tag, d.Pos)
+		}
+	}
+}
+
+func TestGenerateForeignUnexported(t *testing.T) {
+	path, err := filepath.Abs(".")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	_, err = Generate(Options{
+		Path:  path,
+		Types: []string{"ForeignSecretTestType"},
+		Recvs: []string{"t"},
+	})
+
+	expected := "unexported field Secrets.value of type testpkg.Secret can not be encoded, the type needs the methods Value() and SetValue(string)"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected error %q, got: %v", expected, err)
+	}
+}
+
+func TestGenerateRelativePath(t *testing.T) {
+	data, err := Generate(Options{
+		Path:  "./internal/testpkg/tagged",
+		Types: []string{"Always"},
+		Recvs: []string{"a"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	src := string(data)
+	if !strings.Contains(src, "func (a Always) WriteBinary(writer io.Writer) error") {
+		t.Errorf("expected methods of Always not to be qualified, got:\n%s", src)
+	}
+
+	if strings.Contains(src, "tagged\"") || strings.Contains(src, "tagged.") {
+		t.Errorf("expected current package not to be imported, got:\n%s", src)
+	}
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	data, err := Generate(Options{Path: "./internal/testpkg/tagged"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	src := string(data)
+	for _, method := range []string{
+		"func (a Always) WriteBinary(writer io.Writer) error",
+		"func (t Default) WriteBinary(writer io.Writer) error",
+		"func (t Node) WriteBinary(writer io.Writer) error",
+		"refs[x] = uint64(len(refs))",
+	} {
+		if !strings.Contains(src, method) {
+			t.Errorf("expected generated code to contain %q, got:\n%s", method, src)
+		}
+	}
+
+	if strings.Contains(src, "func (t *Node) DecodeBinary(") {
+		t.Errorf("expected decoding methods of Node not to be generated, got:\n%s", src)
+	}
+
+	_, err = Generate(Options{Path: "./internal/testpkg/v1/model"})
+	if err == nil || !strings.Contains(err.Error(), "no types to generate") {
+		t.Errorf("expected no types to generate error, got: %v", err)
+	}
+}
+
+func TestGenerateTypeOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{
+			"directives",
+			Options{
+				Path: "./internal/testpkg/tagged",
+				TypeOptions: map[string]TypeOptions{
+					"Always": {Recv: "x"},
+					"Node":   {Methods: map[string]string{"DecodeBinary": ""}},
+				},
+			},
+			[]string{
+				"func (x Always) WriteBinary(writer io.Writer) error",
+				"func (t Default) WriteBinary(writer io.Writer) error",
+				"func (t *Node) DecodeBinary(reader io.Reader) error",
+			},
+		},
+		{
+			"types",
+			Options{
+				Path:        "./internal/testpkg/tagged",
+				Types:       []string{"Always", "Default"},
+				TypeOptions: map[string]TypeOptions{"Default": {Recv: "d"}},
+			},
+			[]string{
+				"func (t Always) WriteBinary(writer io.Writer) error",
+				"func (d Default) WriteBinary(writer io.Writer) error",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Generate(tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, method := range tt.expected {
+				if !strings.Contains(string(data), method) {
+					t.Errorf("expected generated code to contain %q, got:\n%s", method, data)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFunctions(t *testing.T) {
+	data, err := Generate(Options{
+		Path:      "./internal/testpkg/data",
+		Package:   "github.com/erizocosmico/bindec/internal/testpkg/tagged",
+		Functions: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	src := string(data)
+	for _, fn := range []string{
+		"package data",
+		"func EncodeDefault(writer io.Writer, v *tagged.Default) error",
+		"func DecodeDefault(reader io.Reader, v *tagged.Default) error",
+		"func EncodeAlways(writer io.Writer, v *tagged.Always) error",
+		"func DecodeAlways(reader io.Reader, v *tagged.Always) error",
+	} {
+		if !strings.Contains(src, fn) {
+			t.Errorf("expected generated code to contain %q, got:\n%s", fn, src)
+		}
+	}
+
+	// Types of unions are looked up in the package declaring them.
+	data, err = Generate(Options{
+		Path:      "./internal/testpkg/data",
+		Package:   "github.com/erizocosmico/bindec/internal/testpkg",
+		Types:     []string{"Receipt"},
+		Functions: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if src := string(data); !strings.Contains(src, "case *testpkg.Card:") {
+		t.Errorf("expected generated code to contain the types of the union, got:\n%s", src)
+	}
+
+	testCases := []struct {
+		name string
+		opts Options
+		err  string
+	}{
+		{
+			"methods of other package",
+			Options{
+				Path:    "./internal/testpkg/data",
+				Package: "github.com/erizocosmico/bindec/internal/testpkg/tagged",
+			},
+			"can only be generated as functions",
+		},
+		{
+			"generic type",
+			Options{
+				Path:      ".",
+				Types:     []string{"PairTestType"},
+				Functions: true,
+			},
+			"can't generate functions for generic type PairTestType",
+		},
+		{
+			"unexported fields",
+			Options{
+				Path:      "./internal/testpkg/data",
+				Package:   "github.com/erizocosmico/bindec/internal/testpkg",
+				Types:     []string{"Secret"},
+				Functions: true,
+			},
+			"unexported field value of type testpkg.Secret can not be encoded",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestGenerateMethodConflicts(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts Options
+		err  string
+	}{
+		{
+			"field",
+			Options{Types: []string{"MethodConflictTestType"}},
+			"method WriteBinary of type MethodConflictTestType conflicts with the field WriteBinary declared at",
+		},
+		{
+			"method",
+			Options{
+				Types:   []string{"RenamedTestType"},
+				Methods: map[string]string{"EncodeBinary": ""},
+			},
+			"method EncodeBinary of type RenamedTestType conflicts with the method EncodeBinary declared at",
+		},
+		{
+			"function",
+			Options{Types: []string{"FunctionConflictTestType"}, Functions: true},
+			"function EncodeFunctionConflictTestType of type FunctionConflictTestType conflicts with EncodeFunctionConflictTestType declared at",
+		},
+		{
+			"same name",
+			Options{
+				Types:   []string{"FunctionConflictTestType"},
+				Methods: map[string]string{"WriteBinary": "Code", "DecodeBinary": "Code"},
+			},
+			"can't have the same name Code",
+		},
+		{
+			"same function",
+			Options{
+				Types:     []string{"FunctionConflictTestType", "StructTestType"},
+				Functions: true,
+				Methods:   map[string]string{"WriteBinary": "Encode"},
+			},
+			"function Encode of type StructTestType conflicts with the one generated for type FunctionConflictTestType",
+		},
+		{
+			"helper",
+			Options{Types: []string{"HelperConflictTestType"}},
+			"helper decodeHelperConflictTestType of type HelperConflictTestType conflicts with decodeHelperConflictTestType declared at",
+		},
+		{
+			"no functions",
+			Options{
+				Types:     []string{"FunctionConflictTestType"},
+				Functions: true,
+				Methods:   map[string]string{"EncodeBinary": ""},
+			},
+			"no functions to generate",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got: %v", tt.err, err)
+			}
+		})
+	}
+
+	// Methods declared in files generated by bindec are replaced.
+	_, err := Generate(Options{Types: []string{"StructTestType"}})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestApplyTypeOptions(t *testing.T) {
+	result := target{"T", "t", false, 10, nil}
+	applyTypeOptions(&result, TypeOptions{})
+	if expected := (target{"T", "t", false, 10, nil}); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	applyTypeOptions(&result, TypeOptions{
+		Recv:            "x",
+		MaxDepth:        5,
+		TrackReferences: true,
+		Methods:         map[string]string{"WriteBinary": "Encode"},
+	})
+	expected := target{"T", "x", true, 5, map[string]string{"WriteBinary": "Encode"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestParseGenerateDirective(t *testing.T) {
+	testCases := []struct {
+		args     string
+		expected target
+		err      bool
+	}{
+		{"", target{"T", "t", false, 10, nil}, false},
+		{"recv=x refs maxdepth=5", target{"T", "x", true, 5, nil}, false},
+		{"methods=decode,WriteBinary:Encode", target{"T", "t", false, 10, map[string]string{
+			"DecodeBinaryFromBytes": "",
+			"DecodeBinary":          "",
+			"WriteBinary":           "Encode",
+		}}, false},
+		{"methods=Foo", target{}, true},
+		{"recv=", target{}, true},
+		{"recv=1x", target{}, true},
+		{"maxdepth=0", target{}, true},
+		{"maxdepth=foo", target{}, true},
+		{"unknown", target{}, true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.args, func(t *testing.T) {
+			result := target{"T", "t", false, 10, nil}
+			err := parseGenerateDirective(&result, tt.args)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGeneratePackages(t *testing.T) {
+	results, err := GeneratePackages(Options{Path: "./internal/testpkg"}, "./...")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, strings.TrimPrefix(r.Path, "github.com/erizocosmico/bindec/internal/testpkg"))
+
+		switch r.Path {
+		case "github.com/erizocosmico/bindec/internal/testpkg/tagged":
+			if r.Err != nil {
+				t.Errorf("unexpected error generating %s: %s", r.Path, r.Err)
+			}
+
+			if strings.Join(r.Types, ",") != "Default,Always,Node" {
+				t.Errorf("unexpected types generated: %v", r.Types)
+			}
+
+			if !strings.Contains(string(r.Code), "func (a Always) WriteBinary") {
+				t.Errorf("unexpected code generated:\n%s", r.Code)
+			}
+
+			if !strings.HasSuffix(r.Dir, filepath.Join("internal", "testpkg", "tagged")) {
+				t.Errorf("unexpected directory: %s", r.Dir)
+			}
+		case "github.com/erizocosmico/bindec/internal/testpkg/invalid":
+			if r.Err == nil || !strings.Contains(r.Err.Error(), "channel") {
+				t.Errorf("expected channel error generating %s, got: %v", r.Path, r.Err)
+			}
+		default:
+			if r.Err != nil || len(r.Types) > 0 || r.Code != nil {
+				t.Errorf("expected %s to be skipped, got: %+v", r.Path, r)
+			}
+		}
+	}
+
+	expected := ",/data,/invalid,/tagged,/v1/model,/v2/model"
+	if strings.Join(paths, ",") != expected {
+		t.Errorf("expected packages %s, got %s", expected, strings.Join(paths, ","))
 	}
 }
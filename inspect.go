@@ -0,0 +1,138 @@
+package bindec
+
+import (
+	"go/token"
+	"go/types"
+)
+
+// NamedType is a type parsed by Inspect.
+type NamedType struct {
+	// Name of the type.
+	Name string
+	// Pos is the position of the type declaration.
+	Pos token.Position
+	// Type is the parsed type. If the type contains itself, it's a
+	// Recursive type defined in Recursive.
+	Type Type
+	// Recursive are the definitions of the types that contain themselves,
+	// directly or through other types, by the type name of the Recursive
+	// types referring to them.
+	Recursive map[string]Type
+	// MaxDepth is the maximum depth of recursive types that is decoded.
+	MaxDepth int
+}
+
+// Inspect parses the types that Generate would generate with the given
+// options and returns them instead of their code, so other tools can work
+// on the same model as the generator. Type names are qualified as they would
+// be in the generated code, and options only affecting the generated methods,
+// such as their receivers or names, are ignored. Problems in the
+// declarations of the types are returned as Diagnostics.
+func Inspect(opts Options) ([]NamedType, error) {
+	pkg, err := optionsPackage(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return inspect(pkg, opts)
+}
+
+func inspect(pkg *packageInfo, opts Options) ([]NamedType, error) {
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	targets, typs, err := findTargets(pkg, opts, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := parseTargets(newParseContext(pkg), targets, typs)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make([]NamedType, len(parsed))
+	for i, p := range parsed {
+		result[i] = namedType(pkg, p, typs[i])
+	}
+
+	return result, nil
+}
+
+// namedType returns the named type of a parsed target of the given package,
+// whose type is typ.
+func namedType(pkg *packageInfo, p parsedTarget, typ types.Type) NamedType {
+	result := NamedType{
+		Name:     p.name,
+		Type:     p.typ,
+		MaxDepth: p.maxDepth,
+	}
+
+	if named, ok := typ.(*types.Named); ok {
+		result.Pos = pkg.fset.Position(named.Obj().Pos())
+	}
+
+	if helpers := p.ctx.getHelpers(); len(helpers) > 0 {
+		result.Recursive = make(map[string]Type, len(helpers))
+		for _, h := range helpers {
+			result.Recursive[h.TypeName] = h.Type
+		}
+	}
+	return result
+}
+
+// FixedSize returns the size in bytes of the encoded values of the given
+// type and true if all of them are encoded with the same size, or false
+// otherwise, as is the case of strings, slices, maps or pointers.
+func FixedSize(t Type) (int, bool) {
+	switch t := t.(type) {
+	case Basic:
+		size, ok := basicSizes[t.Kind]
+		return size, ok
+	case Duration:
+		return 8, true
+	case Array:
+		if t.Len == 0 {
+			return 0, true
+		}
+		size, ok := FixedSize(t.Elem)
+		return size * int(t.Len), ok
+	case Struct:
+		var size int
+		for _, f := range t.Fields {
+			n, ok := FixedSize(f.Type)
+			if !ok {
+				return 0, false
+			}
+			size += n
+		}
+		return size, true
+	case Embedded:
+		return FixedSize(t.Struct)
+	default:
+		return 0, false
+	}
+}
+
+// basicSizes are the sizes of the encoded values of the basic types, which
+// don't depend on the platform.
+var basicSizes = map[BasicKind]int{
+	Bool:       1,
+	Int:        8,
+	Int8:       1,
+	Int16:      2,
+	Int32:      4,
+	Int64:      8,
+	Uint:       8,
+	Uint8:      1,
+	Uint16:     2,
+	Uint32:     4,
+	Uint64:     8,
+	Uintptr:    8,
+	Float32:    4,
+	Float64:    8,
+	Complex64:  8,
+	Complex128: 16,
+}
@@ -0,0 +1,119 @@
+package bindec
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	typs, err := Inspect(Options{
+		Types:    []string{"DurationTestType", "StructCyclic", "EmbeddedTestType"},
+		MaxDepth: 5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(typs) != 3 {
+		t.Fatalf("expected 3 types, got %d", len(typs))
+	}
+
+	for _, typ := range typs {
+		if filepath.Base(typ.Pos.Filename) != "types_test.go" || typ.Pos.Line == 0 {
+			t.Errorf("unexpected position of type %s: %s", typ.Name, typ.Pos)
+		}
+
+		if typ.MaxDepth != 5 {
+			t.Errorf("expected max depth of type %s to be 5, got %d", typ.Name, typ.MaxDepth)
+		}
+	}
+
+	duration, ok := typs[0].Type.(Struct)
+	if typs[0].Name != "DurationTestType" || !ok || len(duration.Fields) != 1 {
+		t.Fatalf("unexpected type: %#v", typs[0])
+	}
+
+	field := duration.Fields[0]
+	expectedSpecs := []ConstraintSpec{{"max", "1h"}, {"min", "1s"}}
+	if field.Name != "Duration" || !reflect.DeepEqual(field.Specs, expectedSpecs) || len(field.Constraints) != 2 {
+		t.Errorf("unexpected field: %#v", field)
+	}
+
+	if field.Pos.Line != typs[0].Pos.Line+1 {
+		t.Errorf("expected field to be declared after the type, got %s", field.Pos)
+	}
+
+	if size, ok := FixedSize(duration); !ok || size != 8 {
+		t.Errorf("expected fixed size 8, got %d, %v", size, ok)
+	}
+
+	cyclic, ok := typs[1].Type.(Recursive)
+	if !ok || cyclic.TypeName != "StructCyclic" {
+		t.Fatalf("expected recursive type, got %#v", typs[1].Type)
+	}
+
+	st, ok := typs[1].Recursive[cyclic.TypeName].(Struct)
+	if !ok || len(st.Fields) != 2 || st.Fields[1].Type != (Maybe{"StructCyclic", cyclic, false}) {
+		t.Errorf("unexpected definition of recursive type: %#v", typs[1].Recursive)
+	}
+
+	embedded := typs[2].Type.(Struct)
+	var names []string
+	for _, f := range embedded.Fields {
+		names = append(names, f.Name)
+	}
+
+	expectedNames := []string{"BaseTestType.ID", "BaseTestType.Name", "AuditTestType", "StringTestType", "EventTestType", "Value"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected fields %v, got %v", expectedNames, names)
+	}
+
+	if typs[2].Recursive != nil {
+		t.Errorf("expected no recursive types, got %v", typs[2].Recursive)
+	}
+}
+
+func TestInspectDiagnostics(t *testing.T) {
+	_, err := Inspect(Options{Types: []string{"DiagnosticsTestType"}})
+
+	var diags Diagnostics
+	if !errors.As(err, &diags) || len(diags) == 0 {
+		t.Fatalf("expected diagnostics, got: %v", err)
+	}
+}
+
+func TestFixedSize(t *testing.T) {
+	testCases := []struct {
+		typ  Type
+		size int
+		ok   bool
+	}{
+		{Basic{"int", Int}, 8, true},
+		{Basic{"int8", Int8}, 1, true},
+		{Basic{"float32", Float32}, 4, true},
+		{Basic{"complex128", Complex128}, 16, true},
+		{Basic{"string", String}, 0, false},
+		{Array{4, Basic{"uint16", Uint16}}, 8, true},
+		{Array{4, Basic{"string", String}}, 0, false},
+		{Struct{[]StructField{
+			{Name: "A", Type: Basic{"bool", Bool}},
+			{Name: "B", Type: Array{2, Basic{"int32", Int32}}},
+		}}, 9, true},
+		{Struct{[]StructField{
+			{Name: "A", Type: Basic{"bool", Bool}},
+			{Name: "B", Type: Slice{"[]int", Basic{"int", Int}}},
+		}}, 0, false},
+		{Maybe{"int", Basic{"int", Int}, false}, 0, false},
+		{Time{"time.Time"}, 0, false},
+		{Recursive{TypeName: "Foo"}, 0, false},
+	}
+
+	for _, tt := range testCases {
+		size, ok := FixedSize(tt.typ)
+		if size != tt.size || ok != tt.ok {
+			t.Errorf("%#v: expected %d, %v, got %d, %v", tt.typ, tt.size, tt.ok, size, ok)
+		}
+	}
+}
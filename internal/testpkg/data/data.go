@@ -0,0 +1,8 @@
+// Package data has the same name as an identifier of the generated code to
+// test import aliasing.
+package data
+
+// Point in a plane.
+type Point struct {
+	X, Y int
+}
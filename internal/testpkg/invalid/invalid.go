@@ -0,0 +1,10 @@
+// Package invalid contains a type that can't be generated to test the
+// errors of packages.
+package invalid
+
+// Invalid contains a channel, which can't be encoded.
+//
+//bindec:generate
+type Invalid struct {
+	C chan int
+}
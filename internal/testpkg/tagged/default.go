@@ -0,0 +1,10 @@
+//go:build !extra
+
+package tagged
+
+// Default is declared only if the extra build tag is not set.
+//
+//bindec:generate
+type Default struct {
+	Value string
+}
@@ -0,0 +1,8 @@
+//go:build extra
+
+package tagged
+
+// Extra is declared only if the extra build tag is set.
+type Extra struct {
+	Value string
+}
@@ -0,0 +1,7 @@
+//go:build ignore
+
+// This file is a program in a different package, which must be ignored
+// when the package is loaded.
+package main
+
+func main() {}
@@ -0,0 +1,18 @@
+// Package tagged contains types declared in files with build constraints
+// to test the loading of packages with build tags.
+package tagged
+
+// Always is declared regardless of the build tags.
+//
+//bindec:generate recv=a
+type Always struct {
+	ID int
+}
+
+// Node is generated with references tracked and only its encoding methods.
+//
+//bindec:generate refs methods=encode
+type Node struct {
+	Name string
+	Next *Node
+}
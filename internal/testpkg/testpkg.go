@@ -0,0 +1,96 @@
+// Package testpkg contains types declared in a package other than bindec to
+// test the generation of types from other packages.
+package testpkg
+
+// Account has unexported fields with accessors.
+type Account struct {
+	ID      int
+	owner   string
+	balance int64
+}
+
+// NewAccount creates a new account.
+func NewAccount(id int, owner string, balance int64) Account {
+	return Account{id, owner, balance}
+}
+
+// Owner returns the owner of the account.
+func (a Account) Owner() string { return a.owner }
+
+// SetOwner sets the owner of the account.
+func (a *Account) SetOwner(owner string) { a.owner = owner }
+
+// Balance returns the balance of the account.
+func (a Account) Balance() int64 { return a.balance }
+
+// SetBalance sets the balance of the account.
+func (a *Account) SetBalance(balance int64) { a.balance = balance }
+
+// Token has an unexported field, but it implements encoding.BinaryMarshaler
+// and encoding.BinaryUnmarshaler.
+type Token struct {
+	value string
+}
+
+// NewToken creates a new token.
+func NewToken(value string) Token {
+	return Token{value}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (t Token) MarshalBinary() ([]byte, error) {
+	return []byte(t.value), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (t *Token) UnmarshalBinary(data []byte) error {
+	t.value = string(data)
+	return nil
+}
+
+// Secret has an unexported field that can't be accessed from other
+// packages.
+type Secret struct {
+	value string
+}
+
+// Invoice has no methods to encode and decode it, so it can only be
+// generated as functions from other packages.
+type Invoice struct {
+	Number  int
+	Account Account
+	Lines   []Line
+	Notes   *string
+}
+
+// Line is a line of an invoice.
+type Line struct {
+	Concept string
+	Amount  int64
+}
+
+// Payment is the way a receipt is paid.
+//
+//bindec:union Cash *Card
+type Payment interface{ isPayment() }
+
+// Cash is a payment in cash.
+type Cash struct {
+	Amount int64
+}
+
+func (Cash) isPayment() {}
+
+// Card is a payment by card.
+type Card struct {
+	Number string
+}
+
+func (*Card) isPayment() {}
+
+// Receipt has unions declared in this package, so their types must be
+// found here when it's generated from other packages.
+type Receipt struct {
+	Payment Payment
+	Refunds []Payment `bindec:"union=Cash"`
+}
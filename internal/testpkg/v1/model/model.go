@@ -0,0 +1,8 @@
+// Package model contains a type with the same package name and type name
+// as the one in the v2 package to test import aliasing.
+package model
+
+// User of the first version of the model.
+type User struct {
+	Name string
+}
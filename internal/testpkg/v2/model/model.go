@@ -0,0 +1,9 @@
+// Package model contains a type with the same package name and type name
+// as the one in the v1 package to test import aliasing.
+package model
+
+// User of the second version of the model.
+type User struct {
+	Name  string
+	Email string
+}
@@ -0,0 +1,436 @@
+// Package validate checks the constraints of struct fields on decoded
+// values, following the same rules and returning the same errors as the code
+// generated by bindec, so the packages decoding values without the generated
+// code behave just like it.
+package validate
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kind is the kind of the values of a field, which determines the
+// constraints it can have.
+type Kind int
+
+// Kinds of values. Signed and unsigned integers of any size are checked as
+// int64 and uint64, and floats as float64.
+const (
+	Invalid Kind = iota
+	Bool
+	Int
+	Uint
+	Float32
+	Float64
+	String
+	// Slice are slices and bytes, whose length can be constrained.
+	Slice
+	Time
+	Duration
+)
+
+// Patterns are the regular expressions used by the constraints that need
+// one, by constraint name.
+var Patterns = map[string]string{
+	"numeric":     "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"hexadecimal": "^[0-9a-fA-F]+$",
+	"email":       "^(?:(?:(?:(?:[a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+(?:\\.([a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+)*)|(?:(?:\\x22)(?:(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(?:\\x20|\\x09)+)?(?:(?:[\\x01-\\x08\\x0b\\x0c\\x0e-\\x1f\\x7f]|\\x21|[\\x23-\\x5b]|[\\x5d-\\x7e]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[\\x01-\\x09\\x0b\\x0c\\x0d-\\x7f]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}]))))*(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(\\x20|\\x09)+)?(?:\\x22))))@(?:(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.)+(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.?$",
+	"base64":      "^(?:[A-Za-z0-9+\\/]{4})*(?:[A-Za-z0-9+\\/]{2}==|[A-Za-z0-9+\\/]{3}=|[A-Za-z0-9+\\/]{4})$",
+	"uuid":        "^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$",
+}
+
+var regexps = func() map[string]*regexp.Regexp {
+	var result = make(map[string]*regexp.Regexp, len(Patterns))
+	for name, pattern := range Patterns {
+		result[name] = regexp.MustCompile(pattern)
+	}
+	return result
+}()
+
+// Constraint is a constraint of a field compiled for the kind of its
+// values.
+type Constraint struct {
+	// Name of the constraint, such as "maxlen".
+	Name string
+	// Len reports whether the constraint is checked with CheckLen on the
+	// length of the value before reading its content, instead of with
+	// Check on the value.
+	Len bool
+
+	check    func(v interface{}) error
+	checkLen func(n int) error
+}
+
+// Check returns an error if the value does not satisfy the constraint. The
+// value must be a bool, int64, uint64, float64, string, time.Time or
+// time.Duration, depending on the kind the constraint was compiled for.
+func (c *Constraint) Check(v interface{}) error {
+	if c.check == nil {
+		return nil
+	}
+	return c.check(v)
+}
+
+// CheckLen returns an error if the length of the value does not satisfy the
+// constraint.
+func (c *Constraint) CheckLen(n int) error {
+	if c.checkLen == nil {
+		return nil
+	}
+	return c.checkLen(n)
+}
+
+// Compile returns the constraint with the given name and arguments of a
+// field whose values have the given kind. It returns an error if the
+// constraint can't be used on the field, just like the generator does.
+func Compile(name, args, field string, kind Kind) (*Constraint, error) {
+	switch kind {
+	case Time:
+		return compileTime(name, args, field)
+	case Duration:
+		return compileDuration(name, args, field)
+	}
+
+	c := &Constraint{Name: name}
+	switch name {
+	case "alpha",
+		"alphanum",
+		"numeric",
+		"hexadecimal",
+		"email",
+		"url",
+		"base64",
+		"uuid",
+		"ip",
+		"ipv4",
+		"ipv6",
+		"contains",
+		"startswith",
+		"endswith":
+		if kind != String {
+			return nil, fmt.Errorf("constraint %q can only be used on string or *string fields", name)
+		}
+
+		c.check = func(v interface{}) error {
+			return checkString(name, args, field, v.(string))
+		}
+	case "oneof":
+		if !isBasic(kind) {
+			return nil, fmt.Errorf("oneof can only be used with basic types")
+		}
+
+		var options []interface{}
+		var printable []string
+		for _, a := range strings.Split(args, " ") {
+			a = strings.TrimSpace(a)
+			if a != "" {
+				if !isValueOfKind(a, kind) {
+					return nil, fmt.Errorf("oneof value %q is not a valid value for the field type", a)
+				}
+				options = append(options, convertedValue(a, kind))
+				printable = append(printable, printableValue(a, kind))
+			}
+		}
+
+		c.check = func(v interface{}) error {
+			for _, o := range options {
+				if _, eq, _ := compare(v, o); eq {
+					return nil
+				}
+			}
+			return fmt.Errorf("field '%s' should have one of these values: %s", field, strings.Join(printable, ", "))
+		}
+	case "max", "min", "eq", "neq":
+		if (name == "max" || name == "min") && !isNumber(kind) {
+			return nil, fmt.Errorf("constraint %q can only be used on numeric fields", name)
+		} else if !isBasic(kind) {
+			return nil, fmt.Errorf("constraint %s can only be used with basic types", name)
+		}
+
+		if !isValueOfKind(args, kind) {
+			return nil, fmt.Errorf("%s value %q is not a valid value for the field type", name, args)
+		}
+
+		arg, printed := convertedValue(args, kind), constantValue(args, kind)
+		c.check = func(v interface{}) error {
+			lt, eq, gt := compare(v, arg)
+			return checkComparison(name, field, printed, lt, eq, gt)
+		}
+	case "maxlen", "minlen":
+		if kind != String && kind != Slice {
+			return nil, fmt.Errorf("constraint %q can only be used on string and slice fields", name)
+		}
+
+		n, err := strconv.Atoi(args)
+		if err != nil {
+			return nil, fmt.Errorf("constraint %q value %q is not a valid number", name, args)
+		}
+
+		c.Len = true
+		c.checkLen = func(sz int) error {
+			if name == "maxlen" && sz > n {
+				return fmt.Errorf("field '%v' has a maximum length of %v", field, n)
+			} else if name == "minlen" && sz < n {
+				return fmt.Errorf("field '%v' has a minimum length of %v", field, n)
+			}
+			return nil
+		}
+	case "before", "after", "notzero":
+		return nil, fmt.Errorf("constraint %q can only be used on time.Time and time.Duration fields", name)
+	default:
+		return nil, fmt.Errorf("constraint not found: %s", name)
+	}
+
+	return c, nil
+}
+
+func compileTime(name, args, field string) (*Constraint, error) {
+	c := &Constraint{Name: name}
+	switch name {
+	case "before", "after":
+		t, err := time.Parse(time.RFC3339Nano, args)
+		if err != nil {
+			return nil, fmt.Errorf("%s value %q is not a valid RFC 3339 time", name, args)
+		}
+
+		arg := time.Unix(t.Unix(), int64(t.Nanosecond())).UTC()
+		c.check = func(v interface{}) error {
+			x := v.(time.Time)
+			if name == "before" && !x.Before(arg) {
+				return fmt.Errorf("field '%v' should be before %v", field, arg)
+			} else if name == "after" && !x.After(arg) {
+				return fmt.Errorf("field '%v' should be after %v", field, arg)
+			}
+			return nil
+		}
+	case "notzero":
+		c.check = func(v interface{}) error {
+			if v.(time.Time).IsZero() {
+				return fmt.Errorf("field '%v' should not be zero", field)
+			}
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("constraint %q can not be used on time.Time fields", name)
+	}
+	return c, nil
+}
+
+func compileDuration(name, args, field string) (*Constraint, error) {
+	c := &Constraint{Name: name}
+	switch name {
+	case "max", "min", "eq", "neq":
+		d, err := ParseDuration(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s value %q is not a valid duration", name, args)
+		}
+
+		c.check = func(v interface{}) error {
+			x := v.(time.Duration)
+			return checkComparison(name, field, d, x < d, x == d, x > d)
+		}
+	case "oneof":
+		var options []time.Duration
+		var printable []string
+		for _, a := range strings.Fields(args) {
+			d, err := ParseDuration(a)
+			if err != nil {
+				return nil, fmt.Errorf("oneof value %q is not a valid duration", a)
+			}
+			options = append(options, d)
+			printable = append(printable, strconv.FormatInt(int64(d), 10))
+		}
+
+		c.check = func(v interface{}) error {
+			for _, o := range options {
+				if v.(time.Duration) == o {
+					return nil
+				}
+			}
+			return fmt.Errorf("field '%s' should have one of these values: %s", field, strings.Join(printable, ", "))
+		}
+	case "notzero":
+		c.check = func(v interface{}) error {
+			if v.(time.Duration) == 0 {
+				return fmt.Errorf("field '%v' should not be zero", field)
+			}
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("constraint %q can not be used on time.Duration fields", name)
+	}
+	return c, nil
+}
+
+// ParseDuration parses the argument of a constraint on a time.Duration field.
+// It's either a duration in the format of time.ParseDuration or an integer
+// number of nanoseconds, because durations were checked as any other int64
+// before they were supported.
+func ParseDuration(s string) (time.Duration, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(n), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// checkString returns an error if the value does not satisfy the string
+// constraint with the given name.
+func checkString(name, arg, field, s string) error {
+	var ok bool
+	var message string
+	switch name {
+	case "alpha":
+		ok, message = strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }) < 0, "contains non alpha characters"
+	case "alphanum":
+		ok, message = strings.IndexFunc(s, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) < 0, "contains non alphanumeric characters"
+	case "numeric":
+		ok, message = strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) < 0, "contains non numeric characters"
+	case "hexadecimal":
+		ok, message = regexps[name].MatchString(s), "is not a valid hexadecimal string"
+	case "email":
+		ok, message = regexps[name].MatchString(s), "is not a valid email"
+	case "url":
+		u, err := url.ParseRequestURI(s)
+		ok, message = err == nil && u.Scheme != "", "is not a valid URL"
+	case "base64":
+		ok, message = regexps[name].MatchString(s), "is not a valid base64 string"
+	case "uuid":
+		ok, message = regexps[name].MatchString(s), "is not a valid UUID"
+	case "ip":
+		ok, message = net.ParseIP(s) != nil, "is not a valid IP address"
+	case "ipv4":
+		ip := net.ParseIP(s)
+		ok, message = ip != nil && ip.To4() != nil, "is not a valid IPv4"
+	case "ipv6":
+		ip := net.ParseIP(s)
+		ok, message = ip != nil && ip.To4() == nil, "is not a valid IPv6"
+	case "contains":
+		ok, message = strings.Contains(s, arg), fmt.Sprintf("does not contain '%v'", arg)
+	case "startswith":
+		ok, message = strings.HasPrefix(s, arg), fmt.Sprintf("does not start with '%v'", arg)
+	case "endswith":
+		ok, message = strings.HasSuffix(s, arg), fmt.Sprintf("does not end with '%v'", arg)
+	}
+
+	if !ok {
+		return fmt.Errorf("field '%v' %s", field, message)
+	}
+	return nil
+}
+
+// checkComparison returns an error if the result of comparing a value with
+// the argument of the constraint with the given name does not satisfy it.
+func checkComparison(name, field string, arg interface{}, lt, eq, gt bool) error {
+	switch {
+	case name == "eq" && !eq:
+		return fmt.Errorf("field '%v' does not equal %v", field, arg)
+	case name == "neq" && eq:
+		return fmt.Errorf("field '%v' should not be equal to %v", field, arg)
+	case name == "min" && lt:
+		return fmt.Errorf("field '%v' has a minimum value of %v", field, arg)
+	case name == "max" && gt:
+		return fmt.Errorf("field '%v' has a maximum value of %v", field, arg)
+	}
+	return nil
+}
+
+// compare compares a value with the argument of a constraint, which has
+// the same type. NaN is not less than, equal to or greater than anything.
+func compare(v, arg interface{}) (lt, eq, gt bool) {
+	switch x := v.(type) {
+	case int64:
+		a := arg.(int64)
+		return x < a, x == a, x > a
+	case uint64:
+		a := arg.(uint64)
+		return x < a, x == a, x > a
+	case float64:
+		a := arg.(float64)
+		return x < a, x == a, x > a
+	case string:
+		a := arg.(string)
+		return x < a, x == a, x > a
+	case bool:
+		return false, x == arg.(bool), false
+	default:
+		return false, false, false
+	}
+}
+
+func isBasic(kind Kind) bool {
+	return kind >= Bool && kind <= String
+}
+
+func isNumber(kind Kind) bool {
+	return kind >= Int && kind <= Float64
+}
+
+// isValueOfKind reports whether the argument of a constraint is a valid
+// value of the given kind.
+func isValueOfKind(v string, kind Kind) bool {
+	switch kind {
+	case String:
+		return true
+	case Bool:
+		return v == "true" || v == "false"
+	case Int:
+		_, err := strconv.ParseInt(v, 10, 64)
+		return err == nil
+	case Uint:
+		_, err := strconv.ParseUint(v, 10, 64)
+		return err == nil
+	case Float32, Float64:
+		_, err := strconv.ParseFloat(v, 64)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// constantValue returns the argument of a constraint as the generated code
+// prints it in its errors, which is the default type of the constant.
+func constantValue(v string, kind Kind) interface{} {
+	switch kind {
+	case Bool:
+		return v == "true"
+	case Int:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	case Uint:
+		n, _ := strconv.ParseUint(v, 10, 64)
+		return n
+	case Float32, Float64:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	default:
+		return v
+	}
+}
+
+// convertedValue returns the argument of a constraint converted to the
+// type of the values of the given kind, like constants are when they are
+// compared with them in the generated code.
+func convertedValue(v string, kind Kind) interface{} {
+	arg := constantValue(v, kind)
+	if kind == Float32 {
+		return float64(float32(arg.(float64)))
+	}
+	return arg
+}
+
+// printableValue returns the argument of a constraint as it's written in
+// the generated code.
+func printableValue(v string, kind Kind) string {
+	if kind == String {
+		return fmt.Sprintf(`"%s"`, v)
+	}
+	return v
+}
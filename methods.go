@@ -0,0 +1,196 @@
+package bindec
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// methodNames are the default names of the methods that can be generated,
+// in the order they are generated.
+var methodNames = []string{
+	"EncodeBinary",
+	"WriteBinary",
+	"DecodeBinaryFromBytes",
+	"DecodeBinary",
+}
+
+// methodGroups are the names of the groups of methods that can be selected
+// at once.
+var methodGroups = map[string][]string{
+	"all":    methodNames,
+	"encode": {"EncodeBinary", "WriteBinary"},
+	"decode": {"DecodeBinaryFromBytes", "DecodeBinary"},
+	"bytes":  {"EncodeBinary", "DecodeBinaryFromBytes"},
+	"stream": {"WriteBinary", "DecodeBinary"},
+}
+
+// ParseMethods parses a comma-separated list of methods to generate, as
+// accepted by Options.Methods. Every element is either a group of methods,
+// which can be all, encode, decode, bytes or stream, or the default name of
+// a method, optionally followed by a colon and the name to give it, such as
+// "WriteBinary:Encode".
+func ParseMethods(spec string) (map[string]string, error) {
+	var result = make(map[string]string)
+	for _, m := range strings.Split(spec, ",") {
+		m = strings.TrimSpace(m)
+		if group, ok := methodGroups[m]; ok {
+			for _, name := range group {
+				if _, ok := result[name]; !ok {
+					result[name] = ""
+				}
+			}
+			continue
+		}
+
+		parts := strings.SplitN(m, ":", 2)
+		if !stringContains(methodNames, parts[0]) {
+			return nil, fmt.Errorf("unknown method %q, expecting one of %s or a group of methods", parts[0], strings.Join(methodNames, ", "))
+		}
+
+		result[parts[0]] = ""
+		if len(parts) == 2 {
+			if !token.IsIdentifier(parts[1]) {
+				return nil, fmt.Errorf("invalid name %q for method %s", parts[1], parts[0])
+			}
+			result[parts[0]] = parts[1]
+		}
+	}
+	return result, nil
+}
+
+// validateMethods returns the given methods to generate, or the default
+// ones if there are none, or an error if they're not valid. Only WriteBinary
+// and DecodeBinary can be generated as functions, so the rest are ignored.
+func validateMethods(methods map[string]string, functions bool) (map[string]string, error) {
+	if len(methods) == 0 {
+		return defaultMethods(functions), nil
+	}
+
+	var result = make(map[string]string)
+	for m, name := range methods {
+		if !stringContains(methodNames, m) {
+			return nil, fmt.Errorf("unknown method %q", m)
+		}
+
+		if name != "" && !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("invalid name %q for method %s", name, m)
+		}
+
+		if !functions || m == "WriteBinary" || m == "DecodeBinary" {
+			result[m] = name
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no functions to generate, only WriteBinary and DecodeBinary can be generated as functions")
+	}
+
+	return result, nil
+}
+
+// defaultMethods returns the methods generated by default.
+func defaultMethods(functions bool) map[string]string {
+	if functions {
+		return map[string]string{"WriteBinary": "", "DecodeBinary": ""}
+	}
+
+	var result = make(map[string]string)
+	for _, m := range methodNames {
+		result[m] = ""
+	}
+	return result
+}
+
+// methodName returns the name of the given method of the target. Methods
+// generated as functions are named after the type by default.
+func (t target) methodName(method string, functions bool) string {
+	if name := t.methods[method]; name != "" {
+		return name
+	}
+
+	if functions && method == "WriteBinary" {
+		return "Encode" + t.name
+	} else if functions {
+		return "Decode" + t.name
+	}
+	return method
+}
+
+// checkConflicts returns an error if any of the methods or functions that
+// are going to be generated has the same name as another declaration of the
+// package. Declarations of files generated by bindec are ignored, as they
+// are going to be replaced.
+func checkConflicts(pkg *packageInfo, targets []target, typs []types.Type, functions bool) error {
+	var declared = make(map[string]string)
+	for i, t := range targets {
+		var methods []string
+		for m := range t.methods {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+
+		var names = make(map[string]string)
+		for _, m := range methods {
+			name := t.methodName(m, functions)
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("methods %s and %s of type %s can't have the same name %s", other, m, t.name, name)
+			}
+			names[name] = m
+
+			if functions {
+				if other, ok := declared[name]; ok {
+					return fmt.Errorf("function %s of type %s conflicts with the one generated for type %s", name, t.name, other)
+				}
+				declared[name] = t.name
+
+				obj := pkg.Scope().Lookup(name)
+				if obj != nil && !pkg.isGenerated(obj.Pos()) {
+					return fmt.Errorf(
+						"function %s of type %s conflicts with %s declared at %s",
+						name, t.name, name, pkg.fset.Position(obj.Pos()),
+					)
+				}
+				continue
+			}
+
+			obj, index, _ := types.LookupFieldOrMethod(typs[i], true, pkg.Package, name)
+			if obj == nil || len(index) > 1 || pkg.isGenerated(obj.Pos()) {
+				continue
+			}
+
+			kind := "method"
+			if _, ok := obj.(*types.Var); ok {
+				kind = "field"
+			}
+
+			return fmt.Errorf(
+				"method %s of type %s conflicts with the %s %s declared at %s",
+				name, t.name, kind, name, pkg.fset.Position(obj.Pos()),
+			)
+		}
+	}
+	return nil
+}
+
+// checkHelperConflicts returns an error if the helpers of the recursive
+// types of any of the parsed targets conflict with a declaration of the
+// package.
+func checkHelperConflicts(pkg *packageInfo, parsed []parsedTarget) error {
+	for _, p := range parsed {
+		for _, h := range p.ctx.getHelpers() {
+			for _, name := range []string{"encode" + h.Name, "decode" + h.Name} {
+				obj := pkg.Scope().Lookup(name)
+				if obj != nil && !pkg.isGenerated(obj.Pos()) {
+					return fmt.Errorf(
+						"helper %s of type %s conflicts with %s declared at %s",
+						name, p.name, name, pkg.fset.Position(obj.Pos()),
+					)
+				}
+			}
+		}
+	}
+	return nil
+}
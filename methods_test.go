@@ -0,0 +1,50 @@
+package bindec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMethods(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected map[string]string
+		err      bool
+	}{
+		{"all", map[string]string{
+			"EncodeBinary":          "",
+			"WriteBinary":           "",
+			"DecodeBinaryFromBytes": "",
+			"DecodeBinary":          "",
+		}, false},
+		{"encode", map[string]string{"EncodeBinary": "", "WriteBinary": ""}, false},
+		{"decode", map[string]string{"DecodeBinaryFromBytes": "", "DecodeBinary": ""}, false},
+		{"bytes", map[string]string{"EncodeBinary": "", "DecodeBinaryFromBytes": ""}, false},
+		{"stream", map[string]string{"WriteBinary": "", "DecodeBinary": ""}, false},
+		{"WriteBinary:Encode, stream", map[string]string{"WriteBinary": "Encode", "DecodeBinary": ""}, false},
+		{"DecodeBinary", map[string]string{"DecodeBinary": ""}, false},
+		{"", nil, true},
+		{"Foo", nil, true},
+		{"WriteBinary:1x", nil, true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.spec, func(t *testing.T) {
+			result, err := ParseMethods(tt.spec)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package tags defines an Analyzer that checks the bindec struct tags.
+package tags
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+
+	"github.com/erizocosmico/bindec"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `check bindec struct tags
+
+The bindectags analyzer reports bindec struct tags with unknown options or
+constraints, constraints with invalid arguments and constraints that can't
+be used with the type of their field, which would otherwise only be reported
+when the code is generated.`
+
+// Analyzer checks the bindec struct tags of all structs.
+var Analyzer = &analysis.Analyzer{
+	Name:     "bindectags",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.StructType)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		for _, field := range n.(*ast.StructType).Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue
+			}
+
+			if _, ok := reflect.StructTag(tag).Lookup("bindec"); !ok {
+				continue
+			}
+
+			f := fieldVar(pass, field)
+			if f == nil {
+				continue
+			}
+
+			if err := bindec.CheckTag(pass.Fset, pass.Files, pass.Pkg, f, tag); err != nil {
+				pass.Reportf(field.Tag.Pos(), "invalid bindec struct tag of field %s: %s", f.Name(), err)
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+// fieldVar returns the variable of the first field declared by the given
+// field, as fields declared together share their type and tag.
+func fieldVar(pass *analysis.Pass, field *ast.Field) *types.Var {
+	var ident *ast.Ident
+	if len(field.Names) > 0 {
+		ident = field.Names[0]
+	} else {
+		// Embedded fields are defined by the name of their type.
+		typ := field.Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+
+		switch t := typ.(type) {
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		}
+
+		switch t := typ.(type) {
+		case *ast.Ident:
+			ident = t
+		case *ast.SelectorExpr:
+			ident = t.Sel
+		}
+	}
+
+	if ident == nil {
+		return nil
+	}
+
+	f, _ := pass.TypesInfo.Defs[ident].(*types.Var)
+	return f
+}
@@ -0,0 +1,12 @@
+package tags_test
+
+import (
+	"testing"
+
+	"github.com/erizocosmico/bindec/passes/tags"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), tags.Analyzer, "a")
+}
@@ -0,0 +1,53 @@
+package a
+
+import (
+	"math/big"
+	"time"
+)
+
+type Event interface{ isEvent() }
+
+type Created struct{}
+
+func (Created) isEvent() {}
+
+type Base struct {
+	ID int `bindec:"min=1"`
+}
+
+type Valid struct {
+	Base      `bindec:"flatten"`
+	Name      string        `bindec:"maxlen=10,alpha"`
+	Age       int           `bindec:"min=0,max=120"`
+	At        time.Time     `bindec:"before=2030-01-01T00:00:00Z"`
+	Timeout   time.Duration `bindec:"min=1s"`
+	Interval  time.Duration `bindec:"max=1000,oneof=10 1000"`
+	Event     Event         `bindec:"union=Created"`
+	Skip      chan int      `bindec:"-"`
+	JSON      string        `json:"json"`
+	Untagged  int
+	Tags      []string `bindec:"minlen=1"`
+	Optional  *string  `bindec:"email"`
+	Ratio     float64  `bindec:"max=1.5"`
+	Embedding struct {
+		Value int `bindec:"max=foo"` // want `invalid bindec struct tag of field Value: on constraint "max": max value "foo" is not a valid value for the field type`
+	}
+}
+
+type Invalid struct {
+	Unknown  int           `bindec:"maxlength=10"`           // want `invalid bindec struct tag of field Unknown: constraint not found: "maxlength"`
+	Email    int           `bindec:"email"`                  // want `on constraint "email": constraint "email" can only be used on string or \*string fields`
+	At       time.Time     `bindec:"before=yesterday"`       // want `before value "yesterday" is not a valid RFC 3339 time`
+	Event    Event         `bindec:"union=Created|*Deleted"` // want `type Deleted of union not found in a`
+	Base     Base          `bindec:"flatten"`                // want `field Base can not be flattened because it's not embedded`
+	Args     string        `bindec:"contains"`               // want `constraint "contains" requires arguments`
+	NoArgs   string        `bindec:"email=foo"`              // want `constraint "email" does not require arguments`
+	Format   string        `bindec:"maxlen=1=2"`             // want `invalid format for constraint in struct tag`
+	Length   int           `bindec:"maxlen=1"`               // want `constraint "maxlen" can only be used on string and slice fields`
+	NotZero  int           `bindec:"notzero"`                // want `constraint "notzero" can only be used on time.Time and time.Duration fields`
+	A, B     string        `bindec:"minlen=x"`               // want `invalid bindec struct tag of field A: on constraint "minlen": constraint "minlen" value "x" is not a valid number`
+	Duration time.Duration `bindec:"max=1y"`                 // want `max value "1y" is not a valid duration`
+	Channel  chan int      `bindec:"maxlen=1"`               // want `invalid bindec struct tag of field Channel: constraints can not be used on field Channel because its type can not be encoded: type contains a channel type which cannot be serialized`
+	Funcs    []func()      `bindec:"minlen=1"`               // want `type contains a function type which cannot be serialized`
+	Big      *big.Int      `bindec:"min=1"`                  // want `invalid bindec struct tag of field Big: on constraint "min": constraint "min" can only be used on numeric fields`
+}
@@ -0,0 +1,1879 @@
+package bindec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/erizocosmico/bindec/internal/validate"
+)
+
+// ReflectOptions are the options to encode and decode values with
+// MarshalWithOptions and UnmarshalWithOptions.
+type ReflectOptions struct {
+	// MaxDepth is the maximum depth of recursive types that will be
+	// decoded before failing. If it's 0, DefaultMaxDepth is used.
+	MaxDepth int
+	// TrackReferences enables reference tracking for pointers, just like
+	// the refs option of the generated code.
+	TrackReferences bool
+}
+
+// Marshal returns the binary-encoded representation of v, which is the same
+// one the generated WriteBinary method of its type writes. If v is a
+// pointer, the value it points to is encoded.
+//
+// Types are encoded following the same rules as the generated code, with
+// the package of the type of v as the package being generated. Since
+// //bindec:union directives are not available at runtime, the types of
+// unions must be registered with RegisterUnion, and the types named in
+// union struct tags with RegisterType.
+func Marshal(v interface{}) ([]byte, error) {
+	return MarshalWithOptions(v, ReflectOptions{})
+}
+
+// MarshalWithOptions is like Marshal, but with the given options.
+func MarshalWithOptions(v interface{}, opts ReflectOptions) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("bindec: can not marshal a nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return nil, errors.New("bindec: can not marshal a nil value")
+	}
+
+	c, err := codecOf(rv.Type(), opts.TrackReferences)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &reflectEncoder{refs: make(map[reflectRef]uint64)}
+	if err := c.encode(e, rv); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// Unmarshal fills the value v points to with the given binary-encoded
+// representation of its type, just like the generated
+// DecodeBinaryFromBytes method of the type does, including the checks of
+// the constraints of its fields. See Marshal for the rules used to decode
+// the type.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithOptions(data, v, ReflectOptions{})
+}
+
+// UnmarshalWithOptions is like Unmarshal, but with the given options.
+func UnmarshalWithOptions(data []byte, v interface{}, opts ReflectOptions) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("bindec: can not unmarshal into a non-pointer or nil value of type %T", v)
+	}
+
+	c, err := codecOf(rv.Type().Elem(), opts.TrackReferences)
+	if err != nil {
+		return err
+	}
+
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	d := &reflectDecoder{reader: bytes.NewReader(data), maxDepth: maxDepth}
+	if sc, ok := c.(*structCodec); ok {
+		return sc.decodeRoot(d, rv.Elem())
+	}
+	return c.decode(d, rv.Elem())
+}
+
+// RegisterType registers the named types of the given values, so they can
+// be used in the union struct tags of the types encoded and decoded with
+// Marshal and Unmarshal. Types are referred to by their name in the tags,
+// which must be in the same package as the type being encoded. Values can
+// also be pointers to the types to register.
+func RegisterType(values ...interface{}) {
+	reflectRegistry.Lock()
+	defer reflectRegistry.Unlock()
+
+	for _, v := range values {
+		registerType(reflect.TypeOf(v))
+	}
+	reflectRegistry.codecs = make(map[reflectKey]reflectCodec)
+}
+
+// RegisterUnion registers the types of the union of an interface, which
+// are declared with the //bindec:union directive of the interface for the
+// generated code. iface is a nil pointer to the interface, such as
+// (*Shape)(nil), and the types of the union are the ones of the given
+// variants, in the same order as in the directive. The types are also
+// registered with RegisterType. It panics if the types don't implement the
+// interface.
+func RegisterUnion(iface interface{}, variants ...interface{}) {
+	it := reflect.TypeOf(iface)
+	if it == nil || it.Kind() != reflect.Pointer || it.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("bindec: RegisterUnion needs a pointer to an interface, got %T", iface))
+	}
+	it = it.Elem()
+
+	var types = make([]reflect.Type, len(variants))
+	for i, v := range variants {
+		vt := reflect.TypeOf(v)
+		if vt == nil || !vt.Implements(it) {
+			panic(fmt.Sprintf("bindec: type %v of union does not implement %s", vt, it))
+		}
+		types[i] = vt
+	}
+
+	reflectRegistry.Lock()
+	defer reflectRegistry.Unlock()
+
+	for _, t := range types {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if t.Name() != "" {
+			registerType(t)
+		}
+	}
+	reflectRegistry.unions[it] = types
+	reflectRegistry.codecs = make(map[reflectKey]reflectCodec)
+}
+
+func registerType(t reflect.Type) {
+	if t != nil && t.Kind() == reflect.Pointer && t.Name() == "" {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Name() == "" || t.PkgPath() == "" {
+		panic(fmt.Sprintf("bindec: can not register type %v, only named types can be registered", t))
+	}
+	reflectRegistry.types[t.PkgPath()+"."+t.Name()] = t
+}
+
+var reflectRegistry = struct {
+	sync.Mutex
+	// types are the registered types by package path and name.
+	types map[string]reflect.Type
+	// unions are the types of the unions of the registered interfaces.
+	unions map[reflect.Type][]reflect.Type
+	// codecs are the codecs of the types already used.
+	codecs map[reflectKey]reflectCodec
+}{
+	types:  make(map[string]reflect.Type),
+	unions: make(map[reflect.Type][]reflect.Type),
+	codecs: make(map[reflectKey]reflectCodec),
+}
+
+type reflectKey struct {
+	typ  reflect.Type
+	refs bool
+}
+
+// codecOf returns the codec of the given root type, which is built the
+// first time it's needed.
+func codecOf(t reflect.Type, refs bool) (reflectCodec, error) {
+	reflectRegistry.Lock()
+	defer reflectRegistry.Unlock()
+
+	key := reflectKey{t, refs}
+	if c, ok := reflectRegistry.codecs[key]; ok {
+		return c, nil
+	}
+
+	ctx := &reflectContext{
+		pkg:       t.PkgPath(),
+		refs:      refs,
+		recursive: make(map[reflect.Type]bool),
+		helpers:   make(map[reflect.Type]*recursiveCodec),
+		done:      make(map[reflect.Type]bool),
+	}
+
+	c, err := compileType(ctx, t)
+	if err != nil {
+		var d Diagnostic
+		if !errors.As(err, &d) {
+			d = Diagnostic{Message: err.Error()}
+		}
+		d.Type = t.String()
+		return nil, Diagnostics{d}
+	}
+
+	reflectRegistry.codecs[key] = c
+	return c, nil
+}
+
+// reflectContext is the equivalent of parseContext to build the codec of a
+// type from its reflect.Type.
+type reflectContext struct {
+	// pkg is the path of the package of the root type.
+	pkg  string
+	refs bool
+	// recursive contains the named types that contain themselves.
+	recursive map[reflect.Type]bool
+	// helpers contains the codecs of the recursive types, and done whether
+	// they have been completely built.
+	helpers map[reflect.Type]*recursiveCodec
+	done    map[reflect.Type]bool
+	// union contains the names of the types of the union of a struct tag,
+	// and unionTypes the registered ones of an interface.
+	union      []string
+	unionTypes []reflect.Type
+	seen       []reflect.Type
+	// path is the path of the field being built from the root type.
+	path string
+}
+
+func (ctx *reflectContext) clone() *reflectContext {
+	c := *ctx
+	c.seen = make([]reflect.Type, len(ctx.seen))
+	copy(c.seen, ctx.seen)
+	return &c
+}
+
+func (ctx *reflectContext) isSeen(t reflect.Type) bool {
+	for _, s := range ctx.seen {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (ctx *reflectContext) markSeen(t reflect.Type) {
+	if isNamedReflectType(t) && !ctx.isSeen(t) {
+		ctx.seen = append(ctx.seen, t)
+	}
+}
+
+func (ctx *reflectContext) helper(t reflect.Type) *recursiveCodec {
+	h, ok := ctx.helpers[t]
+	if !ok {
+		h = new(recursiveCodec)
+		ctx.helpers[t] = h
+	}
+	return h
+}
+
+// isNamedReflectType reports whether t is a declared type, which are the
+// ones go/types represents as named types.
+func isNamedReflectType(t reflect.Type) bool {
+	return t.Name() != "" && t.PkgPath() != ""
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	bytesType    = reflect.TypeOf([]byte(nil))
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	writerType   = reflect.TypeOf((*io.Writer)(nil)).Elem()
+	readerType   = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// compileType builds the codec of a type following the same rules as
+// parseType.
+func compileType(ctx *reflectContext, t reflect.Type) (reflectCodec, error) {
+	if t == timeType {
+		return timeCodec{}, nil
+	}
+
+	if t == durationType {
+		return durationCodec{}, nil
+	}
+
+	if !isNamedReflectType(t) {
+		return compileUnderlying(ctx, t)
+	}
+
+	// The root type can not delegate to its own methods.
+	if len(ctx.seen) > 0 {
+		if c := compileDelegate(ctx, t); c != nil {
+			return c, nil
+		}
+	}
+
+	if ctx.done[t] || ctx.isSeen(t) {
+		ctx.recursive[t] = true
+		return ctx.helper(t), nil
+	}
+
+	if t.Kind() == reflect.Interface && len(ctx.union) == 0 && len(ctx.unionTypes) == 0 {
+		ctx.unionTypes = reflectRegistry.unions[t]
+	}
+
+	ctx.markSeen(t)
+	c, err := compileUnderlying(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.recursive[t] {
+		h := ctx.helper(t)
+		h.codec = c
+		ctx.done[t] = true
+		return h, nil
+	}
+
+	return c, nil
+}
+
+// compileDelegate returns the codec that delegates the encoding and
+// decoding of t to its own methods, or nil if it does not have the needed
+// methods.
+func compileDelegate(ctx *reflectContext, t reflect.Type) reflectCodec {
+	if t.PkgPath() != ctx.pkg &&
+		hasReflectMethod(t, "WriteBinary", []reflect.Type{writerType}, errorType) &&
+		hasReflectMethod(t, "DecodeBinary", []reflect.Type{readerType}, errorType) {
+		return delegateCodec{}
+	}
+
+	for _, m := range marshalers {
+		if hasReflectMethod(t, m[0], nil, bytesType, errorType) &&
+			hasReflectMethod(t, m[1], []reflect.Type{bytesType}, errorType) {
+			return marshalerCodec{m[0], m[1]}
+		}
+	}
+
+	return nil
+}
+
+// hasReflectMethod reports whether an addressable value of type t has a
+// method with the given name, parameters and results.
+func hasReflectMethod(t reflect.Type, name string, params []reflect.Type, results ...reflect.Type) bool {
+	m, ok := reflect.PointerTo(t).MethodByName(name)
+	if !ok || m.Type.IsVariadic() ||
+		m.Type.NumIn() != len(params)+1 || m.Type.NumOut() != len(results) {
+		return false
+	}
+
+	for i, p := range params {
+		if m.Type.In(i+1) != p {
+			return false
+		}
+	}
+
+	for i, r := range results {
+		if m.Type.Out(i) != r {
+			return false
+		}
+	}
+	return true
+}
+
+func compileUnderlying(ctx *reflectContext, t reflect.Type) (reflectCodec, error) {
+	switch t.Kind() {
+	case reflect.Struct:
+		return compileStruct(ctx, t)
+	case reflect.Pointer:
+		elem, err := compileType(ctx, t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return &maybeCodec{t, elem, ctx.refs, reflectTypeName(ctx.pkg, t.Elem())}, nil
+	case reflect.Array:
+		elem, err := compileType(ctx, t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return arrayCodec{t.Len(), elem}, nil
+	case reflect.Map:
+		key, err := compileType(ctx.clone(), t.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		elem, err := compileType(ctx.clone(), t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return mapCodec{t, key, elem}, nil
+	case reflect.Slice:
+		if t.Elem() == bytesType.Elem() {
+			return bytesCodec{}, nil
+		}
+
+		elem, err := compileType(ctx, t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return sliceCodec{t, elem}, nil
+	case reflect.String,
+		reflect.Bool,
+		reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr,
+		reflect.Float32,
+		reflect.Float64,
+		reflect.Complex64,
+		reflect.Complex128:
+		return basicCodec{t.Kind()}, nil
+	case reflect.UnsafePointer:
+		return nil, fmt.Errorf("type contains a basic type which cannot be serialized (unsafe pointer)")
+	case reflect.Chan:
+		return nil, fmt.Errorf("type contains a channel type which cannot be serialized")
+	case reflect.Func:
+		return nil, fmt.Errorf("type contains a function type which cannot be serialized")
+	case reflect.Interface:
+		if len(ctx.union) == 0 && len(ctx.unionTypes) == 0 {
+			return nil, fmt.Errorf("type contains an interface type which cannot be serialized without declaring its implementations as a union")
+		}
+
+		return compileUnion(ctx, t)
+	default:
+		return nil, fmt.Errorf("invalid type received: %s", t)
+	}
+}
+
+func compileUnion(ctx *reflectContext, t reflect.Type) (reflectCodec, error) {
+	types := ctx.unionTypes
+	if len(ctx.union) > 0 {
+		types = make([]reflect.Type, len(ctx.union))
+		for i, name := range ctx.union {
+			ptr := strings.HasPrefix(name, "*")
+			name = strings.TrimPrefix(name, "*")
+
+			typ, ok := reflectRegistry.types[ctx.pkg+"."+name]
+			if !ok {
+				return nil, fmt.Errorf("type %s of union not found in %s, it must be registered with RegisterType", name, ctx.pkg)
+			}
+
+			if ptr {
+				typ = reflect.PointerTo(typ)
+			}
+			types[i] = typ
+		}
+	}
+
+	if len(types) > math.MaxUint8 {
+		return nil, fmt.Errorf("unions can have at most %d types, got %d", math.MaxUint8, len(types))
+	}
+
+	u := &unionCodec{name: reflectTypeName(ctx.pkg, t)}
+	for _, typ := range types {
+		if !typ.Implements(t) {
+			return nil, fmt.Errorf("type %s of union does not implement the interface", typ)
+		}
+
+		vctx := ctx.clone()
+		vctx.union = nil
+		vctx.unionTypes = nil
+		c, err := compileType(vctx, typ)
+		if _, ok := err.(Diagnostic); ok {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("on type %s of union: %s", typ, err)
+		}
+
+		u.variants = append(u.variants, unionVariant{typ, c})
+	}
+
+	return u, nil
+}
+
+// compileStruct builds the codec of a struct type.
+func compileStruct(ctx *reflectContext, t reflect.Type) (reflectCodec, error) {
+	if err := checkPromotedReflectFields(ctx, t, "", false, make(map[string]string), nil); err != nil {
+		return nil, err
+	}
+
+	fields, err := compileFields(ctx, t, nil, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &structCodec{fields}, nil
+}
+
+// compileFields builds the codecs of the fields of a struct, which are
+// accessed from the struct being encoded through the given index. Fields
+// promoted from flattened embedded structs are named after their path from
+// the struct, which is given by prefix. If flatten is true, all embedded
+// fields are flattened.
+func compileFields(
+	ctx *reflectContext,
+	t reflect.Type,
+	index []int,
+	prefix string,
+	flatten bool,
+) ([]reflectField, error) {
+	var fields []reflectField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		cfg, err := parseTag(string(f.Tag))
+		if err != nil {
+			return nil, fieldError(ctx, f.Name, fmt.Errorf("invalid struct tag: %s", err))
+		}
+
+		// Blank fields can't be accessed.
+		if cfg.ignore || f.Name == "_" {
+			continue
+		}
+
+		var getter, setter string
+		if !f.IsExported() && f.PkgPath != ctx.pkg {
+			getter, setter = findReflectAccessors(t, f)
+			if getter == "" {
+				return nil, fieldError(ctx, f.Name, fmt.Errorf(
+					"unexported field %s of type %s can not be encoded, the type needs the methods %s() and %s(%s), or to implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler",
+					fieldPath(ctx.path, f.Name),
+					reflectTypeName(ctx.pkg, t),
+					exportedName(f.Name),
+					"Set"+exportedName(f.Name),
+					reflectTypeName(ctx.pkg, f.Type),
+				))
+			}
+		}
+
+		if cfg.flatten && !f.Anonymous {
+			return nil, fieldError(ctx, f.Name, fmt.Errorf("field %s can not be flattened because it's not embedded", f.Name))
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		if f.Anonymous && (cfg.flatten || flatten) {
+			if len(cfg.constraints) > 0 || len(cfg.union) > 0 {
+				return nil, fieldError(ctx, f.Name, fmt.Errorf("flattened field %s can not have constraints or unions", f.Name))
+			}
+
+			fs, err := compileEmbedded(ctx, f, fieldIndex, prefix)
+			if err != nil {
+				return nil, fieldError(ctx, f.Name, err)
+			}
+
+			fields = append(fields, fs...)
+			continue
+		}
+
+		fctx := ctx.clone()
+		fctx.union = cfg.union
+		fctx.unionTypes = nil
+		fctx.path = fieldPath(ctx.path, f.Name)
+		c, err := compileType(fctx, f.Type)
+		if err != nil {
+			return nil, fieldError(ctx, f.Name, err)
+		}
+
+		var names = make([]string, 0, len(cfg.constraints))
+		for name := range cfg.constraints {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var cs []*validate.Constraint
+		for _, name := range names {
+			rc, err := compileConstraint(name, cfg.constraints[name], f.Name, c)
+			if err != nil {
+				return nil, fieldError(ctx, f.Name, fmt.Errorf("on constraint %q: %s", name, err))
+			}
+			cs = append(cs, rc)
+		}
+
+		fields = append(fields, reflectField{
+			index:       fieldIndex,
+			codec:       c,
+			constraints: cs,
+			typ:         f.Type,
+			getter:      getter,
+			setter:      setter,
+		})
+	}
+
+	return fields, nil
+}
+
+// compileEmbedded builds the codecs of the fields of a flattened embedded
+// struct, like parseEmbedded.
+func compileEmbedded(ctx *reflectContext, f reflect.StructField, index []int, prefix string) ([]reflectField, error) {
+	typ := f.Type
+	isPtr := typ.Kind() == reflect.Pointer
+	if isPtr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("embedded field %s can not be flattened because it's not a struct or a pointer to a struct", f.Name)
+	}
+
+	if ctx.isSeen(typ) {
+		return nil, fmt.Errorf("embedded field %s can not be flattened because its type is recursive", f.Name)
+	}
+
+	ectx := ctx.clone()
+	ectx.markSeen(typ)
+	ectx.path = fieldPath(ctx.path, f.Name)
+	if !isPtr {
+		return compileFields(ectx, typ, index, prefix+f.Name+".", true)
+	}
+
+	fields, err := compileFields(ectx, typ, nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return []reflectField{{
+		index: index,
+		codec: embeddedCodec{typ, &structCodec{fields}},
+		typ:   f.Type,
+	}}, nil
+}
+
+// findReflectAccessors returns the names of the methods of the struct t to
+// get and set the given unexported field, or empty names if it doesn't have
+// them.
+func findReflectAccessors(t reflect.Type, f reflect.StructField) (getter, setter string) {
+	if !isNamedReflectType(t) {
+		return "", ""
+	}
+
+	getter = exportedName(f.Name)
+	setter = "Set" + getter
+	if !hasReflectMethod(t, getter, nil, f.Type) ||
+		!hasReflectMethod(t, setter, []reflect.Type{f.Type}) {
+		return "", ""
+	}
+	return getter, setter
+}
+
+// checkPromotedReflectFields returns an error if two fields of the struct
+// have the same name once the fields of flattened embedded structs are
+// promoted, like checkPromotedFields.
+func checkPromotedReflectFields(
+	ctx *reflectContext,
+	t reflect.Type,
+	prefix string,
+	flatten bool,
+	names map[string]string,
+	seen []reflect.Type,
+) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		cfg, err := parseTag(string(f.Tag))
+		if err != nil || cfg.ignore {
+			continue
+		}
+
+		if f.Anonymous && (cfg.flatten || flatten) {
+			typ := f.Type
+			if typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+
+			var recursive bool
+			for _, s := range seen {
+				recursive = recursive || s == typ
+			}
+
+			// Invalid and recursive embedded fields are reported when the
+			// fields are built.
+			if typ.Kind() == reflect.Struct && !recursive {
+				err := checkPromotedReflectFields(ctx, typ, prefix+f.Name+".", true, names, append(seen, typ))
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if other, ok := names[f.Name]; ok {
+			return fieldError(ctx, prefix+f.Name, fmt.Errorf(
+				"field %s conflicts with field %s after flattening embedded fields",
+				prefix+f.Name, other,
+			))
+		}
+		names[f.Name] = prefix + f.Name
+	}
+	return nil
+}
+
+// fieldError returns the error found building the field with the given
+// name as a Diagnostic, unless it already is one.
+func fieldError(ctx *reflectContext, name string, err error) error {
+	var d Diagnostic
+	if errors.As(err, &d) {
+		return err
+	}
+
+	return Diagnostic{Field: fieldPath(ctx.path, name), Message: err.Error()}
+}
+
+// reflectTypeName returns the name of the type as it's written in the
+// generated code of the given package.
+func reflectTypeName(pkg string, t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == pkg {
+			return t.Name()
+		}
+		return t.String()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + reflectTypeName(pkg, t.Elem())
+	case reflect.Slice:
+		return "[]" + reflectTypeName(pkg, t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), reflectTypeName(pkg, t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", reflectTypeName(pkg, t.Key()), reflectTypeName(pkg, t.Elem()))
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+		return t.String()
+	default:
+		return t.String()
+	}
+}
+
+// reflectCodec encodes and decodes the values of a type.
+type reflectCodec interface {
+	encode(e *reflectEncoder, v reflect.Value) error
+	// decode decodes a value into v, which is always addressable.
+	decode(d *reflectDecoder, v reflect.Value) error
+}
+
+// lengthCodec is a codec of values with a length, which is checked before
+// reading their content.
+type lengthCodec interface {
+	// decodeLen is like decode, but calls check, if any, with the length
+	// of the value before reading its content.
+	decodeLen(d *reflectDecoder, v reflect.Value, check func(int) error) error
+}
+
+type reflectEncoder struct {
+	buf  bytes.Buffer
+	refs map[reflectRef]uint64
+}
+
+// reflectRef identifies an encoded pointer, just like the pointers
+// themselves do in the refs of the generated code.
+type reflectRef struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+func (e *reflectEncoder) writeUint64(x uint64) {
+	var bs [8]byte
+	binary.LittleEndian.PutUint64(bs[:], x)
+	e.buf.Write(bs[:])
+}
+
+func (e *reflectEncoder) writeInt64(x int64) {
+	ux := uint64(x) << 1
+	if x < 0 {
+		ux = ^ux
+	}
+	e.writeUint64(ux)
+}
+
+func (e *reflectEncoder) writeUint32(x uint32) {
+	var bs [4]byte
+	binary.LittleEndian.PutUint32(bs[:], x)
+	e.buf.Write(bs[:])
+}
+
+func (e *reflectEncoder) writeUint16(x uint16) {
+	var bs [2]byte
+	binary.LittleEndian.PutUint16(bs[:], x)
+	e.buf.Write(bs[:])
+}
+
+func (e *reflectEncoder) writeLen(n int) {
+	e.writeUint64(uint64(n) << 1)
+}
+
+type reflectDecoder struct {
+	reader   *bytes.Reader
+	refs     []reflect.Value
+	depth    int
+	maxDepth int
+}
+
+// maxEmptyElems is the maximum number of elements of slices and maps whose
+// elements may be encoded with no bytes, so invalid data can't make
+// Unmarshal allocate an unbounded number of them.
+const maxEmptyElems = 1 << 20
+
+// checkElems returns an error if there are not enough bytes left for n
+// elements encoded with at least the given size each.
+func (d *reflectDecoder) checkElems(n, size int) error {
+	rem := d.reader.Len()
+	if (size > 0 && n > rem/size) || (size == 0 && n > maxEmptyElems) {
+		return fmt.Errorf("invalid length %d, there are only %d bytes left", n, rem)
+	}
+	return nil
+}
+
+// read reads the next n bytes, failing with the same errors io.ReadFull
+// does.
+func (d *reflectDecoder) read(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+
+	// Lengths are not trusted to allocate the bytes to read.
+	if rem := d.reader.Len(); n > rem {
+		_, _ = d.reader.Seek(0, io.SeekEnd)
+		if rem == 0 {
+			return nil, io.EOF
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (d *reflectDecoder) readUint64() (uint64, error) {
+	bs, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(bs), nil
+}
+
+func (d *reflectDecoder) readInt64() (int64, error) {
+	ux, err := d.readUint64()
+	if err != nil {
+		return 0, err
+	}
+
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+	return x, nil
+}
+
+func (d *reflectDecoder) readByte() (byte, error) {
+	bs, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return bs[0], nil
+}
+
+func (d *reflectDecoder) readLen() (int, error) {
+	x, err := d.readInt64()
+	return int(x), err
+}
+
+// addressable returns v if it's addressable or an addressable copy of it
+// otherwise, so its unexported fields and pointer methods can be used.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// minSize returns the minimum number of bytes a value of the codec is
+// encoded with. Recursive types and delegates are assumed to have no size.
+func minSize(c reflectCodec) int {
+	switch c := c.(type) {
+	case basicCodec:
+		switch c.kind {
+		case reflect.Bool, reflect.Int8, reflect.Uint8:
+			return 1
+		case reflect.Int16, reflect.Uint16:
+			return 2
+		case reflect.Int32, reflect.Uint32, reflect.Float32:
+			return 4
+		case reflect.Complex128:
+			return 16
+		default:
+			return 8
+		}
+	case durationCodec, bytesCodec, sliceCodec, mapCodec, marshalerCodec:
+		return 8
+	case timeCodec:
+		return 13
+	case *maybeCodec, *unionCodec:
+		return 1
+	case arrayCodec:
+		if c.len > maxEmptyElems {
+			return maxEmptyElems
+		}
+		return c.len * minSize(c.elem)
+	case *structCodec:
+		var size int
+		for _, f := range c.fields {
+			size += minSize(f.codec)
+		}
+		return size
+	case embeddedCodec:
+		return minSize(c.st)
+	default:
+		return 0
+	}
+}
+
+type basicCodec struct {
+	kind reflect.Kind
+}
+
+func (c basicCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	switch c.kind {
+	case reflect.String:
+		s := v.String()
+		e.writeLen(len(s))
+		e.buf.WriteString(s)
+	case reflect.Bool:
+		var b byte
+		if v.Bool() {
+			b = 1
+		}
+		e.buf.WriteByte(b)
+	case reflect.Int, reflect.Int64:
+		e.writeInt64(v.Int())
+	case reflect.Int32:
+		x := int32(v.Int())
+		ux := uint32(x) << 1
+		if x < 0 {
+			ux = ^ux
+		}
+		e.writeUint32(ux)
+	case reflect.Int16:
+		x := int16(v.Int())
+		ux := uint16(x) << 1
+		if x < 0 {
+			ux = ^ux
+		}
+		e.writeUint16(ux)
+	case reflect.Int8:
+		x := int8(v.Int())
+		ux := byte(x) << 1
+		if x < 0 {
+			ux = ^ux
+		}
+		e.buf.WriteByte(ux)
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		e.writeUint64(v.Uint())
+	case reflect.Uint32:
+		e.writeUint32(uint32(v.Uint()))
+	case reflect.Uint16:
+		e.writeUint16(uint16(v.Uint()))
+	case reflect.Uint8:
+		e.buf.WriteByte(byte(v.Uint()))
+	case reflect.Float32:
+		e.writeUint32(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.writeUint64(math.Float64bits(v.Float()))
+	case reflect.Complex64:
+		c := v.Complex()
+		e.writeUint32(math.Float32bits(float32(real(c))))
+		e.writeUint32(math.Float32bits(float32(imag(c))))
+	case reflect.Complex128:
+		c := v.Complex()
+		e.writeUint64(math.Float64bits(real(c)))
+		e.writeUint64(math.Float64bits(imag(c)))
+	}
+	return nil
+}
+
+func (c basicCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	return c.decodeLen(d, v, nil)
+}
+
+func (c basicCodec) decodeLen(d *reflectDecoder, v reflect.Value, check func(int) error) error {
+	switch c.kind {
+	case reflect.String:
+		sz, err := d.readLen()
+		if err != nil {
+			return err
+		}
+
+		if check != nil {
+			if err := check(sz); err != nil {
+				return err
+			}
+		}
+
+		b, err := d.read(sz)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(b))
+	case reflect.Bool:
+		b, err := d.readByte()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b == 1)
+	case reflect.Int, reflect.Int64:
+		x, err := d.readInt64()
+		if err != nil {
+			return err
+		}
+		v.SetInt(x)
+	case reflect.Int32:
+		bs, err := d.read(4)
+		if err != nil {
+			return err
+		}
+
+		ux := binary.LittleEndian.Uint32(bs)
+		x := int32(ux >> 1)
+		if ux&1 != 0 {
+			x = ^x
+		}
+		v.SetInt(int64(x))
+	case reflect.Int16:
+		bs, err := d.read(2)
+		if err != nil {
+			return err
+		}
+
+		ux := binary.LittleEndian.Uint16(bs)
+		x := int16(ux >> 1)
+		if ux&1 != 0 {
+			x = ^x
+		}
+		v.SetInt(int64(x))
+	case reflect.Int8:
+		ux, err := d.readByte()
+		if err != nil {
+			return err
+		}
+
+		x := int8(ux >> 1)
+		if ux&1 != 0 {
+			x = ^x
+		}
+		v.SetInt(int64(x))
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		x, err := d.readUint64()
+		if err != nil {
+			return err
+		}
+		v.SetUint(x)
+	case reflect.Uint32:
+		bs, err := d.read(4)
+		if err != nil {
+			return err
+		}
+		v.SetUint(uint64(binary.LittleEndian.Uint32(bs)))
+	case reflect.Uint16:
+		bs, err := d.read(2)
+		if err != nil {
+			return err
+		}
+		v.SetUint(uint64(binary.LittleEndian.Uint16(bs)))
+	case reflect.Uint8:
+		b, err := d.readByte()
+		if err != nil {
+			return err
+		}
+		v.SetUint(uint64(b))
+	case reflect.Float32:
+		bs, err := d.read(4)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(bs))))
+	case reflect.Float64:
+		x, err := d.readUint64()
+		if err != nil {
+			return err
+		}
+		v.SetFloat(math.Float64frombits(x))
+	case reflect.Complex64:
+		bs, err := d.read(8)
+		if err != nil {
+			return err
+		}
+		v.SetComplex(complex128(complex64Of(bs)))
+	case reflect.Complex128:
+		bs, err := d.read(16)
+		if err != nil {
+			return err
+		}
+		v.SetComplex(complex128Of(bs))
+	}
+	return nil
+}
+
+func complex64Of(bs []byte) complex64 {
+	re := math.Float32frombits(binary.LittleEndian.Uint32(bs))
+	im := math.Float32frombits(binary.LittleEndian.Uint32(bs[4:]))
+	return complex(re, im)
+}
+
+func complex128Of(bs []byte) complex128 {
+	re := math.Float64frombits(binary.LittleEndian.Uint64(bs))
+	im := math.Float64frombits(binary.LittleEndian.Uint64(bs[8:]))
+	return complex(re, im)
+}
+
+// durationCodec is the codec of time.Duration, which is encoded as an
+// int64, but constraints on it take durations as arguments.
+type durationCodec struct{}
+
+func (durationCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	e.writeInt64(v.Int())
+	return nil
+}
+
+func (durationCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	x, err := d.readInt64()
+	if err != nil {
+		return err
+	}
+	v.SetInt(x)
+	return nil
+}
+
+type bytesCodec struct{}
+
+func (bytesCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	e.writeLen(v.Len())
+	e.buf.Write(v.Bytes())
+	return nil
+}
+
+func (c bytesCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	return c.decodeLen(d, v, nil)
+}
+
+func (bytesCodec) decodeLen(d *reflectDecoder, v reflect.Value, check func(int) error) error {
+	sz, err := d.readLen()
+	if err != nil {
+		return err
+	}
+
+	if check != nil {
+		if err := check(sz); err != nil {
+			return err
+		}
+	}
+
+	b, err := d.read(sz)
+	if err != nil {
+		return err
+	}
+	v.SetBytes(b)
+	return nil
+}
+
+type sliceCodec struct {
+	typ  reflect.Type
+	elem reflectCodec
+}
+
+func (c sliceCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	n := v.Len()
+	e.writeLen(n)
+	for i := 0; i < n; i++ {
+		if err := c.elem.encode(e, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c sliceCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	return c.decodeLen(d, v, nil)
+}
+
+func (c sliceCodec) decodeLen(d *reflectDecoder, v reflect.Value, check func(int) error) error {
+	sz, err := d.readLen()
+	if err != nil {
+		return err
+	}
+
+	// Slices of complex numbers are read all at once, like the generated
+	// code does.
+	var size int
+	if b, ok := c.elem.(basicCodec); ok && b.kind == reflect.Complex64 {
+		size = 8
+	} else if ok && b.kind == reflect.Complex128 {
+		size = 16
+	}
+
+	if size > 0 && (sz < 0 || sz > math.MaxInt32) {
+		return fmt.Errorf("invalid slice length %d", sz)
+	} else if sz < 0 {
+		return fmt.Errorf("invalid length %d", sz)
+	}
+
+	if check != nil {
+		if err := check(sz); err != nil {
+			return err
+		}
+	}
+
+	if size > 0 {
+		data, err := d.read(sz * size)
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.MakeSlice(c.typ, sz, sz))
+		for i := 0; i < sz; i++ {
+			if size == 8 {
+				v.Index(i).SetComplex(complex128(complex64Of(data[i*8:])))
+			} else {
+				v.Index(i).SetComplex(complex128Of(data[i*16:]))
+			}
+		}
+		return nil
+	}
+
+	if err := d.checkElems(sz, minSize(c.elem)); err != nil {
+		return err
+	}
+
+	v.Set(reflect.MakeSlice(c.typ, sz, sz))
+	for i := 0; i < sz; i++ {
+		if err := c.elem.decode(d, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type arrayCodec struct {
+	len  int
+	elem reflectCodec
+}
+
+func (c arrayCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	for i := 0; i < c.len; i++ {
+		if err := c.elem.encode(e, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c arrayCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	for i := 0; i < c.len; i++ {
+		if err := c.elem.decode(d, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mapCodec struct {
+	typ  reflect.Type
+	key  reflectCodec
+	elem reflectCodec
+}
+
+func (c mapCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	e.writeLen(v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		if err := c.key.encode(e, iter.Key()); err != nil {
+			return err
+		}
+
+		if err := c.elem.encode(e, iter.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c mapCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	sz, err := d.readLen()
+	if err != nil {
+		return err
+	}
+
+	if sz < 0 {
+		return fmt.Errorf("invalid length %d", sz)
+	}
+
+	if err := d.checkElems(sz, minSize(c.key)+minSize(c.elem)); err != nil {
+		return err
+	}
+
+	m := reflect.MakeMap(c.typ)
+	v.Set(m)
+	for i := 0; i < sz; i++ {
+		key := reflect.New(c.typ.Key()).Elem()
+		value := reflect.New(c.typ.Elem()).Elem()
+		if err := c.key.decode(d, key); err != nil {
+			return err
+		}
+
+		if err := c.elem.decode(d, value); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, value)
+	}
+	return nil
+}
+
+// maybeCodec is the codec of pointers. name is the name of the type they
+// point to.
+type maybeCodec struct {
+	typ  reflect.Type
+	elem reflectCodec
+	refs bool
+	name string
+}
+
+func (c *maybeCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	if v.IsNil() {
+		e.buf.WriteByte(0)
+		return nil
+	}
+
+	if c.refs {
+		ref := reflectRef{c.typ, v.Pointer()}
+		if id, ok := e.refs[ref]; ok {
+			e.buf.WriteByte(2)
+			e.writeUint64(id)
+			return nil
+		}
+		e.refs[ref] = uint64(len(e.refs))
+	}
+
+	e.buf.WriteByte(1)
+	return c.elem.encode(e, v.Elem())
+}
+
+func (c *maybeCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	return c.decodeLen(d, v, nil)
+}
+
+// decodeLen decodes the pointer, checking the length of the value it points
+// to, if check is not nil, before reading its content. References are
+// checked with the length of the value they reference.
+func (c *maybeCodec) decodeLen(d *reflectDecoder, v reflect.Value, check func(int) error) error {
+	kind, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case kind == 0:
+		v.Set(reflect.Zero(c.typ))
+	case kind == 1:
+		p := reflect.New(c.typ.Elem())
+		if c.refs {
+			d.refs = append(d.refs, p)
+		}
+
+		if check != nil {
+			err = c.elem.(lengthCodec).decodeLen(d, p.Elem(), check)
+		} else {
+			err = c.elem.decode(d, p.Elem())
+		}
+
+		if err != nil {
+			return err
+		}
+		v.Set(p)
+	case kind == 2 && c.refs:
+		id, err := d.readUint64()
+		if err != nil {
+			return err
+		}
+
+		if id >= uint64(len(d.refs)) {
+			return fmt.Errorf("invalid reference: %d", id)
+		}
+
+		ref := d.refs[id]
+		if ref.Type() != c.typ {
+			return fmt.Errorf("reference %d is not a *%s", id, c.name)
+		}
+
+		if check != nil {
+			if err := check(ref.Elem().Len()); err != nil {
+				return err
+			}
+		}
+		v.Set(ref)
+	case c.refs:
+		return fmt.Errorf("invalid reference kind: %d", kind)
+	default:
+		v.Set(reflect.New(c.typ.Elem()))
+		if check != nil {
+			return c.elem.(lengthCodec).decodeLen(d, v.Elem(), check)
+		}
+		return c.elem.decode(d, v.Elem())
+	}
+	return nil
+}
+
+type timeCodec struct{}
+
+func (timeCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	x := v.Interface().(time.Time)
+	sec := x.Unix()
+	ux := uint64(sec) << 1
+	if sec < 0 {
+		ux = ^ux
+	}
+	bs := make([]byte, 13)
+	binary.LittleEndian.PutUint64(bs, ux)
+	binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+	switch x.Location() {
+	case time.UTC:
+		bs[12] = 0
+	case time.Local:
+		bs[12] = 1
+	default:
+		bs[12] = 2
+	}
+	e.buf.Write(bs)
+
+	if bs[12] == 2 {
+		_, offset := x.Zone()
+		name := x.Location().String()
+		uoffset := uint32(int32(offset)) << 1
+		if offset < 0 {
+			uoffset = ^uoffset
+		}
+		e.writeUint32(uoffset)
+		e.writeLen(len(name))
+		e.buf.WriteString(name)
+	}
+	return nil
+}
+
+func (timeCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	bs, err := d.read(13)
+	if err != nil {
+		return err
+	}
+
+	ux := binary.LittleEndian.Uint64(bs)
+	sec := int64(ux >> 1)
+	if ux&1 != 0 {
+		sec = ^sec
+	}
+	nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+	tm := time.Unix(sec, nsec)
+	switch bs[12] {
+	case 0:
+		tm = tm.UTC()
+	case 1:
+		tm = tm.Local()
+	case 2:
+		bs, err := d.read(12)
+		if err != nil {
+			return err
+		}
+
+		ux := binary.LittleEndian.Uint32(bs)
+		offset := int32(ux >> 1)
+		if ux&1 != 0 {
+			offset = ^offset
+		}
+
+		ux2 := binary.LittleEndian.Uint64(bs[4:])
+		x := int64(ux2 >> 1)
+		if ux2&1 != 0 {
+			x = ^x
+		}
+
+		if x < 0 || x > math.MaxInt32 {
+			return fmt.Errorf("invalid time zone name length %d", x)
+		}
+
+		b, err := d.read(int(x))
+		if err != nil {
+			return err
+		}
+
+		name := string(b)
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			loc = time.FixedZone(name, int(offset))
+		} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+			loc = time.FixedZone(name, int(offset))
+		}
+		tm = tm.In(loc)
+	default:
+		return fmt.Errorf("invalid time zone kind: %d", bs[12])
+	}
+
+	v.Set(reflect.ValueOf(tm))
+	return nil
+}
+
+// marshalerCodec is the codec of types that encode and decode themselves
+// with the given methods, such as encoding.BinaryMarshaler.
+type marshalerCodec struct {
+	marshal   string
+	unmarshal string
+}
+
+func (c marshalerCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	out := addressable(v).Addr().MethodByName(c.marshal).Call(nil)
+	if err, _ := out[1].Interface().(error); err != nil {
+		return err
+	}
+
+	b := out[0].Bytes()
+	e.writeLen(len(b))
+	e.buf.Write(b)
+	return nil
+}
+
+func (c marshalerCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	x, err := d.readInt64()
+	if err != nil {
+		return err
+	}
+
+	if x < 0 || x > math.MaxInt32 {
+		return fmt.Errorf("invalid length %d", x)
+	}
+
+	b, err := d.read(int(x))
+	if err != nil {
+		return err
+	}
+
+	out := v.Addr().MethodByName(c.unmarshal).Call([]reflect.Value{reflect.ValueOf(b)})
+	err, _ = out[0].Interface().(error)
+	return err
+}
+
+// delegateCodec is the codec of types with their own bindec methods.
+type delegateCodec struct{}
+
+func (delegateCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	out := addressable(v).Addr().MethodByName("WriteBinary").Call([]reflect.Value{reflect.ValueOf(&e.buf)})
+	err, _ := out[0].Interface().(error)
+	return err
+}
+
+func (delegateCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	out := v.Addr().MethodByName("DecodeBinary").Call([]reflect.Value{reflect.ValueOf(d.reader)})
+	err, _ := out[0].Interface().(error)
+	return err
+}
+
+// recursiveCodec is the codec of a named type that contains itself. Its
+// depth is limited when decoding, like in the helpers of the generated
+// code.
+type recursiveCodec struct {
+	codec reflectCodec
+}
+
+func (c *recursiveCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	return c.codec.encode(e, v)
+}
+
+func (c *recursiveCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > d.maxDepth {
+		return fmt.Errorf("maximum decoding depth of %d exceeded", d.maxDepth)
+	}
+	return c.codec.decode(d, v)
+}
+
+type unionCodec struct {
+	name     string
+	variants []unionVariant
+}
+
+type unionVariant struct {
+	typ   reflect.Type
+	codec reflectCodec
+}
+
+func (c *unionCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	if v.IsNil() {
+		e.buf.WriteByte(0)
+		return nil
+	}
+
+	x := v.Elem()
+	for i, u := range c.variants {
+		if x.Type() == u.typ || (u.typ.Kind() == reflect.Interface && x.Type().Implements(u.typ)) {
+			e.buf.WriteByte(byte(i + 1))
+			return u.codec.encode(e, x)
+		}
+	}
+	return fmt.Errorf("type %s is not part of the union", x.Type())
+}
+
+func (c *unionCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	kind, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	if kind == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if int(kind) > len(c.variants) {
+		return fmt.Errorf("invalid type for union %s: %d", c.name, kind)
+	}
+
+	u := c.variants[kind-1]
+	x := reflect.New(u.typ).Elem()
+	if err := u.codec.decode(d, x); err != nil {
+		return err
+	}
+	v.Set(x)
+	return nil
+}
+
+type structCodec struct {
+	fields []reflectField
+}
+
+// reflectField is a field of a struct, which may be promoted from embedded
+// structs, so it's accessed with an index.
+type reflectField struct {
+	index       []int
+	typ         reflect.Type
+	codec       reflectCodec
+	constraints []*validate.Constraint
+	// getter and setter are the methods to access the field if it's
+	// unexported and from another package.
+	getter, setter string
+}
+
+func (c *structCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	v = addressable(v)
+	for _, f := range c.fields {
+		var fv reflect.Value
+		if f.getter != "" {
+			parent := fieldByIndex(v, f.index[:len(f.index)-1])
+			fv = parent.Addr().MethodByName(f.getter).Call(nil)[0]
+		} else {
+			fv = fieldByIndex(v, f.index)
+		}
+
+		if err := f.codec.encode(e, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *structCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	return c.decodeFields(d, v, len(c.fields))
+}
+
+// decodeRoot decodes the struct as the root type. Data encoded before
+// maybe fields were appended to it ends before them, so they are decoded as
+// nil if there is nothing left, just like the generated code does. The first
+// field is never missing, so empty data still fails with io.EOF.
+func (c *structCodec) decodeRoot(d *reflectDecoder, v reflect.Value) error {
+	optional := len(c.fields)
+	for optional > 1 {
+		if _, ok := c.fields[optional-1].codec.(*maybeCodec); !ok {
+			break
+		}
+		optional--
+	}
+	return c.decodeFields(d, v, optional)
+}
+
+// decodeFields decodes the fields of the struct, where the fields from the
+// given index on are maybes that may be missing at the end of the data.
+func (c *structCodec) decodeFields(d *reflectDecoder, v reflect.Value, optional int) error {
+	for i, f := range c.fields {
+		missing := i >= optional && d.reader.Len() == 0
+		if f.setter == "" {
+			fv := fieldByIndex(v, f.index)
+			if missing {
+				fv.Set(reflect.Zero(f.typ))
+			} else if err := f.decode(d, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tmp := reflect.New(f.typ).Elem()
+		if !missing {
+			if err := f.decode(d, tmp); err != nil {
+				return err
+			}
+		}
+
+		parent := fieldByIndex(v, f.index[:len(f.index)-1])
+		parent.Addr().MethodByName(f.setter).Call([]reflect.Value{tmp})
+	}
+	return nil
+}
+
+// decode decodes the field into v and checks its constraints. Pointers are
+// checked on the values they point to, if any.
+func (f reflectField) decode(d *reflectDecoder, v reflect.Value) error {
+	var before, after []*validate.Constraint
+	for _, c := range f.constraints {
+		if c.Len {
+			before = append(before, c)
+		} else {
+			after = append(after, c)
+		}
+	}
+
+	var err error
+	if len(before) > 0 {
+		err = f.codec.(lengthCodec).decodeLen(d, v, func(n int) error {
+			for _, c := range before {
+				if err := c.CheckLen(n); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	} else {
+		err = f.codec.decode(d, v)
+	}
+
+	if err != nil || len(after) == 0 {
+		return err
+	}
+
+	if _, ok := f.codec.(*maybeCodec); ok {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	value := constraintValue(constraintKind(f.codec), v)
+	for _, c := range after {
+		if err := c.Check(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the field of the addressable struct v with the given
+// index, which can be used even if it's unexported.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		v = v.Field(i)
+		if !v.CanSet() {
+			v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+		}
+	}
+	return v
+}
+
+// embeddedCodec is the codec of a flattened struct embedded as a pointer,
+// which is encoded as the zero value of the struct if it's nil and never
+// nil when decoded.
+type embeddedCodec struct {
+	typ reflect.Type
+	st  *structCodec
+}
+
+func (c embeddedCodec) encode(e *reflectEncoder, v reflect.Value) error {
+	if v.IsNil() {
+		v = reflect.New(c.typ)
+	}
+	return c.st.encode(e, v.Elem())
+}
+
+func (c embeddedCodec) decode(d *reflectDecoder, v reflect.Value) error {
+	p := reflect.New(c.typ)
+	v.Set(p)
+	return c.st.decode(d, p.Elem())
+}
+
+// compileConstraint compiles a constraint of the given field for the kind
+// of the values of its codec, whose pointers are checked on the values they
+// point to.
+func compileConstraint(name, args, field string, c reflectCodec) (*validate.Constraint, error) {
+	return validate.Compile(name, args, field, constraintKind(c))
+}
+
+// constraintKind returns the kind of the values of the given codec that
+// constraints are checked on.
+func constraintKind(c reflectCodec) validate.Kind {
+	if m, ok := c.(*maybeCodec); ok {
+		c = m.elem
+	}
+
+	switch c := c.(type) {
+	case timeCodec:
+		return validate.Time
+	case durationCodec:
+		return validate.Duration
+	case bytesCodec, sliceCodec:
+		return validate.Slice
+	case basicCodec:
+		switch c.kind {
+		case reflect.Bool:
+			return validate.Bool
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return validate.Int
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return validate.Uint
+		case reflect.Float32:
+			return validate.Float32
+		case reflect.Float64:
+			return validate.Float64
+		case reflect.String:
+			return validate.String
+		}
+	}
+	return validate.Invalid
+}
+
+// constraintValue returns the value constraints of the given kind are
+// checked on.
+func constraintValue(kind validate.Kind, v reflect.Value) interface{} {
+	switch kind {
+	case validate.Bool:
+		return v.Bool()
+	case validate.Int:
+		return v.Int()
+	case validate.Uint:
+		return v.Uint()
+	case validate.Float32, validate.Float64:
+		return v.Float()
+	case validate.String:
+		return v.String()
+	case validate.Time:
+		return v.Interface().(time.Time)
+	case validate.Duration:
+		return time.Duration(v.Int())
+	default:
+		return nil
+	}
+}
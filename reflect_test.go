@@ -0,0 +1,346 @@
+package bindec
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/bindec/bench"
+	"github.com/erizocosmico/bindec/internal/testpkg"
+	"github.com/erizocosmico/bindec/internal/testpkg/data"
+	"github.com/erizocosmico/bindec/internal/testpkg/v1/model"
+	modelv2 "github.com/erizocosmico/bindec/internal/testpkg/v2/model"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	RegisterUnion((*EventTestType)(nil), CreatedTestType{}, (*DeletedTestType)(nil))
+	RegisterUnion((*ExprTestType)(nil), LiteralTestType(0), BinaryTestType{})
+	RegisterType(StringTestType(""), IntTestType(0))
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	trueVal := true
+	str := StringTestType("foo")
+	leaf := TreeTestType{4, []TreeTestType{}, map[string]*TreeTestType{}}
+	c := complex(-1.5, 2.25)
+	u, err := url.Parse("https://user@example.com/foo?bar=baz#qux")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		input encoderDecoder
+	}{
+		{"byte", ptrTo(ByteTestType(2))},
+		{"int8", ptrTo(Int8TestType(-2))},
+		{"int16", ptrTo(Int16TestType(math.MinInt16))},
+		{"int32", ptrTo(Int32TestType(-3))},
+		{"int64", ptrTo(Int64TestType(math.MinInt64))},
+		{"uintptr", ptrTo(UintptrTestType(2))},
+		{"float32", ptrTo(Float32TestType(3.14))},
+		{"string", ptrTo(StringTestType("foo"))},
+		{"bool", ptrTo(BoolTestType(true))},
+		{"bytes", &BytesTestType{1, 2, 3}},
+		{"slice", &SliceTestType{1, 2, 3}},
+		{"array", &ArrayTestType{4, 2}},
+		{"map", &MapTestType{1: 2}},
+		{
+			"struct",
+			&StructTestType{
+				Int8:          math.MinInt8,
+				Int64:         math.MaxInt64,
+				Uint64:        math.MaxUint64,
+				String:        "cowabunga",
+				Float64:       6.329840298429048,
+				Pointer:       &trueVal,
+				Slice:         []int16{0, -5, math.MaxInt16},
+				Bytes:         []byte("ay caramba"),
+				Array:         [4]int16{0, 1, -5, 4},
+				NamedStruct:   Struct2{4, "bar"},
+				StructPointer: &Struct2{8, "baz"},
+			},
+		},
+		{
+			"tree",
+			&TreeTestType{
+				Value:    1,
+				Children: []TreeTestType{leaf, {3, []TreeTestType{leaf}, map[string]*TreeTestType{}}},
+				Index:    map[string]*TreeTestType{"leaf": &leaf},
+			},
+		},
+		{"linked list", &StructCyclic{1, &StructCyclic{2, nil}}},
+		{
+			"mutual recursion",
+			&MutualATestType{"a", &MutualBTestType{[]MutualATestType{{Name: "b"}}}},
+		},
+		{
+			"time",
+			&TimeTestType{
+				Time:        time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+				TimePointer: ptrTo(time.Date(1800, 1, 2, 3, 4, 5, 6, time.FixedZone("FOO", -7200))),
+				Duration:    -5 * time.Minute,
+			},
+		},
+		{
+			"delegate",
+			&DelegateTestType{
+				URL:       *u,
+				Int:       big.NewInt(-1234567890),
+				Foo:       bench.Foo{A: 1, B: "foo", C: []byte("bar"), E: []int{1, 2}},
+				Marshaler: MarshalerTestType{42},
+			},
+		},
+		{
+			"union",
+			&UnionTestType{
+				Event:  &DeletedTestType{1, "spam"},
+				Events: []EventTestType{CreatedTestType{2}, nil, (*DeletedTestType)(nil)},
+				Value:  IntTestType(42),
+				Expr:   BinaryTestType{"+", LiteralTestType(1), BinaryTestType{"*", LiteralTestType(2), nil}},
+			},
+		},
+		{"generic", &PairTestType[string, int]{"answer", 42, []int{1, 2}}},
+		{"generic delegate", &PageTestType[Int8TestType]{[]Int8TestType{1, -2, 3}, 4}},
+		{"generic recursive", &ListTestType[string]{"a", &ListTestType[string]{"b", nil}}},
+		{
+			"complex",
+			&ComplexTestType{
+				C64:      complex(1, -1),
+				C128:     complex(math.Pi, math.E),
+				Samples:  []complex64{0, complex(1, 2)},
+				Wide:     []complex128{complex(math.Inf(1), -0.5)},
+				Named:    []IQSampleTestType{complex(0.5, 0.25)},
+				Optional: &c,
+				Fixed:    [2]complex64{1i, -1i},
+				Bounded:  []complex64{},
+			},
+		},
+		{
+			"embedded",
+			&EmbeddedTestType{
+				BaseTestType:   BaseTestType{1, "bar"},
+				AuditTestType:  &AuditTestType{LevelTestType{2}, "baz"},
+				StringTestType: &str,
+				EventTestType:  CreatedTestType{3},
+				Value:          "qux",
+			},
+		},
+		{"nil embedded", &EmbeddedTestType{BaseTestType: BaseTestType{Name: "a"}}},
+		{
+			"accessors",
+			&AccessorTestType{
+				Account:  testpkg.NewAccount(1, "foo", -100),
+				Accounts: []testpkg.Account{testpkg.NewAccount(2, "bar", 200)},
+				Token:    testpkg.NewToken("secret"),
+			},
+		},
+		{
+			"aliases",
+			&AliasTestType{
+				V1:     []model.User{{Name: "foo"}},
+				V2:     []modelv2.User{{Name: "bar", Email: "bar@example.com"}},
+				Points: []data.Point{{X: 1, Y: -1}},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			expected, err := tt.input.EncodeBinary()
+			require.NoError(err)
+
+			output, err := Marshal(tt.input)
+			require.NoError(err)
+			require.Equal(expected, output)
+
+			typ := reflect.TypeOf(tt.input).Elem()
+			want := reflect.New(typ).Interface().(decoder)
+			require.NoError(want.DecodeBinaryFromBytes(output))
+
+			got := reflect.New(typ).Interface()
+			require.NoError(Unmarshal(output, got))
+			require.Equal(want, got)
+		})
+	}
+}
+
+func TestMarshalReferences(t *testing.T) {
+	require := require.New(t)
+
+	shared := &GraphNodeTestType{Name: "shared"}
+	cycle := &GraphNodeTestType{Name: "cycle"}
+	cycle.Next = cycle
+	input := GraphTestType{
+		A:     shared,
+		B:     shared,
+		Nodes: []*GraphNodeTestType{cycle, shared, nil},
+	}
+
+	expected, err := input.EncodeBinary()
+	require.NoError(err)
+
+	opts := ReflectOptions{TrackReferences: true}
+	output, err := MarshalWithOptions(input, opts)
+	require.NoError(err)
+	require.Equal(expected, output)
+
+	var result GraphTestType
+	require.NoError(UnmarshalWithOptions(output, &result, opts))
+	require.True(result.A == result.B)
+	require.True(result.Nodes[1] == result.A)
+	require.True(result.Nodes[0].Next == result.Nodes[0])
+	require.Nil(result.Nodes[2])
+
+	// Without references, shared values are encoded every time.
+	output, err = Marshal(GraphTestType{A: shared, B: shared})
+	require.NoError(err)
+	require.NoError(Unmarshal(output, &result))
+	require.Equal(*shared, *result.B)
+	require.False(result.A == result.B)
+}
+
+func TestMarshalErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input interface{}
+		err   string
+	}{
+		{
+			"not in union",
+			UnionTestType{Value: "not in the union"},
+			"type string is not part of the union",
+		},
+		{
+			"unregistered interface",
+			struct{ V interface{} }{},
+			"struct { V interface {} }: on field V: type contains an interface type which cannot be serialized without declaring its implementations as a union",
+		},
+		{
+			"channel",
+			struct{ C []chan int }{},
+			"struct { C []chan int }: on field C: type contains a channel type which cannot be serialized",
+		},
+		{
+			"conflict",
+			ConflictTestType{},
+			"bindec.ConflictTestType: on field ID: field ID conflicts with field BaseTestType.ID after flattening embedded fields",
+		},
+		{
+			"flattened field",
+			FlattenFieldTestType{},
+			"bindec.FlattenFieldTestType: on field Base: field Base can not be flattened because it's not embedded",
+		},
+		{
+			"unexported field",
+			ForeignSecretTestType{},
+			"bindec.ForeignSecretTestType: on field Secrets.value: unexported field Secrets.value of type testpkg.Secret can not be encoded, the type needs the methods Value() and SetValue(string), or to implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler",
+		},
+		{"nil", nil, "bindec: can not marshal a nil value"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.input)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	deep := StructCyclic{}
+	for i := 0; i < DefaultMaxDepth; i++ {
+		next := deep
+		deep = StructCyclic{i, &next}
+	}
+
+	deepData, err := deep.EncodeBinary()
+	require.NoError(t, err)
+
+	graph := GraphTestType{A: &GraphNodeTestType{Name: "a"}}
+	graphData, err := graph.EncodeBinary()
+	require.NoError(t, err)
+
+	// B is a reference to a value that was never encoded.
+	invalidRef := append(append([]byte{}, graphData[:len(graphData)-9]...), 2, 5, 0, 0, 0, 0, 0, 0, 0)
+	invalidRef = append(invalidRef, graphData[len(graphData)-8:]...)
+
+	structData, err := StructTestType{String: "foo"}.EncodeBinary()
+	require.NoError(t, err)
+
+	timeData, err := TimeTestType{Time: time.Now().In(time.FixedZone("FOO", -7200))}.EncodeBinary()
+	require.NoError(t, err)
+
+	delegateData, err := DelegateTestType{Int: big.NewInt(1), Marshaler: MarshalerTestType{42}}.EncodeBinary()
+	require.NoError(t, err)
+
+	// withLength returns the data with the length prefix at the given
+	// offset replaced by the given one.
+	withLength := func(data []byte, offset int, length uint64) []byte {
+		data = append([]byte(nil), data...)
+		binary.LittleEndian.PutUint64(data[offset:], length)
+		return data
+	}
+
+	// The length of the zone name follows the 13 bytes of the time and the
+	// 4 bytes of the zone offset, and the marshaler is the last field,
+	// encoded as "42" after its length. Lengths are zigzag encoded, so 1 is
+	// -1.
+	marshalerOffset := len(delegateData) - 10
+
+	testCases := []struct {
+		name string
+		data []byte
+		v    encoderDecoder
+		opts ReflectOptions
+	}{
+		{"union type", []byte{3}, new(UnionTestType), ReflectOptions{}},
+		{"max depth", deepData, new(StructCyclic), ReflectOptions{}},
+		{"invalid reference", invalidRef, new(GraphTestType), ReflectOptions{TrackReferences: true}},
+		// The last byte is the trailing StructPointer, which may be missing.
+		{"truncated", structData[:len(structData)-2], new(StructTestType), ReflectOptions{}},
+		{"empty", nil, new(StructTestType), ReflectOptions{}},
+		{"negative zone name length", withLength(timeData, 17, 1), new(TimeTestType), ReflectOptions{}},
+		{"huge zone name length", withLength(timeData, 17, 1<<60), new(TimeTestType), ReflectOptions{}},
+		{"zone name longer than the data", withLength(timeData, 17, uint64(len(timeData))), new(TimeTestType), ReflectOptions{}},
+		{"negative marshaler length", withLength(delegateData, marshalerOffset, 1), new(DelegateTestType), ReflectOptions{}},
+		{"huge marshaler length", withLength(delegateData, marshalerOffset, 1<<60), new(DelegateTestType), ReflectOptions{}},
+		{"marshaler longer than the data", withLength(delegateData, marshalerOffset, uint64(len(delegateData))), new(DelegateTestType), ReflectOptions{}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.v.DecodeBinaryFromBytes(tt.data)
+			require.Error(t, expected)
+
+			v := reflect.New(reflect.TypeOf(tt.v).Elem()).Interface()
+			require.EqualError(t, UnmarshalWithOptions(tt.data, v, tt.opts), expected.Error())
+		})
+	}
+
+	require.Error(t, Unmarshal(structData, StructTestType{}))
+	require.NoError(t, UnmarshalWithOptions(deepData, new(StructCyclic), ReflectOptions{MaxDepth: DefaultMaxDepth + 1}))
+}
+
+func TestUnmarshalInvalidLength(t *testing.T) {
+	// Lengths larger than the data left are not used to allocate values.
+	huge := []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x0f}
+
+	var items struct{ Items []int64 }
+	require.EqualError(t, Unmarshal(huge, &items), "invalid length 576460752303423487, there are only 0 bytes left")
+
+	var empty struct{ Items []struct{} }
+	require.EqualError(t, Unmarshal(huge, &empty), "invalid length 576460752303423487, there are only 0 bytes left")
+
+	var m struct{ Items map[int64]int64 }
+	require.EqualError(t, Unmarshal(append(huge, 1, 2, 3), &m), "invalid length 576460752303423487, there are only 3 bytes left")
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
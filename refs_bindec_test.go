@@ -0,0 +1,775 @@
+// WARNING! This is code generated by bindec, do not modify manually.
+
+package bindec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+var _ = binary.LittleEndian
+var _ = math.Abs
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t GraphTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t GraphTestType) WriteBinary(writer io.Writer) error {
+	refs := make(map[interface{}]uint64)
+	{
+
+		{
+			if x := t.A; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else if id, ok := refs[x]; ok {
+				bs := make([]byte, 9)
+				bs[0] = 2
+				binary.LittleEndian.PutUint64(bs[1:], id)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+			} else {
+				refs[x] = uint64(len(refs))
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					if err := encodeGraphTestType_GraphNodeTestType(writer, refs, (*t.A)); err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.B; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else if id, ok := refs[x]; ok {
+				bs := make([]byte, 9)
+				bs[0] = 2
+				binary.LittleEndian.PutUint64(bs[1:], id)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+			} else {
+				refs[x] = uint64(len(refs))
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					if err := encodeGraphTestType_GraphNodeTestType(writer, refs, (*t.B)); err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			n := len(t.Nodes)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			bs := make([]byte, 8)
+			binary.LittleEndian.PutUint64(bs, ux)
+			_, err := writer.Write(bs)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < n; i++ {
+				if x := t.Nodes[i]; x == nil {
+					if _, err := writer.Write([]byte{0}); err != nil {
+						return err
+					}
+				} else if id, ok := refs[x]; ok {
+					bs := make([]byte, 9)
+					bs[0] = 2
+					binary.LittleEndian.PutUint64(bs[1:], id)
+					if _, err := writer.Write(bs); err != nil {
+						return err
+					}
+				} else {
+					refs[x] = uint64(len(refs))
+					if _, err := writer.Write([]byte{1}); err != nil {
+						return err
+					}
+
+					{
+						if err := encodeGraphTestType_GraphNodeTestType(writer, refs, (*t.Nodes[i])); err != nil {
+							return err
+						}
+					}
+
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *GraphTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *GraphTestType) DecodeBinary(reader io.Reader) error {
+	refs := new([]interface{})
+	var depth int
+	{
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.A = nil
+			case 1:
+				tmp_t_A := new(GraphNodeTestType)
+				*refs = append(*refs, tmp_t_A)
+
+				{
+					if err := decodeGraphTestType_GraphNodeTestType(reader, refs, tmp_t_A, depth+1); err != nil {
+						return err
+					}
+
+				}
+
+				t.A = tmp_t_A
+
+			case 2:
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				id := binary.LittleEndian.Uint64(bs)
+				if id >= uint64(len(*refs)) {
+					return fmt.Errorf("invalid reference: %d", id)
+				}
+
+				ref, ok := (*refs)[id].(*GraphNodeTestType)
+				if !ok {
+					return fmt.Errorf("reference %d is not a *GraphNodeTestType", id)
+				}
+
+				t.A = ref
+
+			default:
+				return fmt.Errorf("invalid reference kind: %d", v[0])
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.B = nil
+			case 1:
+				tmp_t_B := new(GraphNodeTestType)
+				*refs = append(*refs, tmp_t_B)
+
+				{
+					if err := decodeGraphTestType_GraphNodeTestType(reader, refs, tmp_t_B, depth+1); err != nil {
+						return err
+					}
+
+				}
+
+				t.B = tmp_t_B
+
+			case 2:
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				id := binary.LittleEndian.Uint64(bs)
+				if id >= uint64(len(*refs)) {
+					return fmt.Errorf("invalid reference: %d", id)
+				}
+
+				ref, ok := (*refs)[id].(*GraphNodeTestType)
+				if !ok {
+					return fmt.Errorf("reference %d is not a *GraphNodeTestType", id)
+				}
+
+				t.B = ref
+
+			default:
+				return fmt.Errorf("invalid reference kind: %d", v[0])
+			}
+		}
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			t.Nodes = make([]*GraphNodeTestType, sz)
+
+			for i := 0; i < sz; i++ {
+				var v = make([]byte, 1)
+				if _, err := io.ReadFull(reader, v); err != nil {
+					return err
+				}
+
+				switch v[0] {
+				case 0:
+					(t.Nodes)[i] = nil
+				case 1:
+					tmp__t_Nodes__i_ := new(GraphNodeTestType)
+					*refs = append(*refs, tmp__t_Nodes__i_)
+
+					{
+						if err := decodeGraphTestType_GraphNodeTestType(reader, refs, tmp__t_Nodes__i_, depth+1); err != nil {
+							return err
+						}
+
+					}
+
+					(t.Nodes)[i] = tmp__t_Nodes__i_
+
+				case 2:
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					id := binary.LittleEndian.Uint64(bs)
+					if id >= uint64(len(*refs)) {
+						return fmt.Errorf("invalid reference: %d", id)
+					}
+
+					ref, ok := (*refs)[id].(*GraphNodeTestType)
+					if !ok {
+						return fmt.Errorf("reference %d is not a *GraphNodeTestType", id)
+					}
+
+					(t.Nodes)[i] = ref
+
+				default:
+					return fmt.Errorf("invalid reference kind: %d", v[0])
+				}
+			}
+
+		}
+	}
+
+	return nil
+}
+
+// encodeGraphTestType_GraphNodeTestType writes the binary-encoded representation of the recursive
+// type GraphNodeTestType to the given writer.
+func encodeGraphTestType_GraphNodeTestType(writer io.Writer, refs map[interface{}]uint64, t GraphNodeTestType) error {
+	{
+
+		{
+			v := t.Name
+			n := len(v)
+			ux := uint64(n) << 1
+			if n < 0 {
+				ux = ^ux
+			}
+			sz := make([]byte, 8)
+			binary.LittleEndian.PutUint64(sz, ux)
+			if _, err := writer.Write(sz); err != nil {
+				return err
+			}
+
+			_, err := writer.Write([]byte(v))
+			if err != nil {
+				return err
+			}
+		}
+
+		{
+			if x := t.Next; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else if id, ok := refs[x]; ok {
+				bs := make([]byte, 9)
+				bs[0] = 2
+				binary.LittleEndian.PutUint64(bs[1:], id)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+			} else {
+				refs[x] = uint64(len(refs))
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					if err := encodeGraphTestType_GraphNodeTestType(writer, refs, (*t.Next)); err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeGraphTestType_GraphNodeTestType reads the binary representation of the recursive type
+// GraphNodeTestType from the given reader at the given depth.
+func decodeGraphTestType_GraphNodeTestType(reader io.Reader, refs *[]interface{}, t *GraphNodeTestType, depth int) error {
+	if depth > 10000 {
+		return fmt.Errorf("maximum decoding depth of %d exceeded", 10000)
+	}
+
+	{
+
+		{
+			var bs = make([]byte, 8)
+			if _, err := io.ReadFull(reader, bs); err != nil {
+				return err
+			}
+
+			ux := binary.LittleEndian.Uint64(bs)
+			x := int64(ux >> 1)
+			if ux&1 != 0 {
+				x = ^x
+			}
+
+			sz := int(x)
+
+			b := make([]byte, sz)
+			if _, err := io.ReadFull(reader, b); err != nil {
+				return err
+			}
+
+			t.Name = string(b)
+
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.Next = nil
+			case 1:
+				tmp_t_Next := new(GraphNodeTestType)
+				*refs = append(*refs, tmp_t_Next)
+
+				{
+					if err := decodeGraphTestType_GraphNodeTestType(reader, refs, tmp_t_Next, depth+1); err != nil {
+						return err
+					}
+
+				}
+
+				t.Next = tmp_t_Next
+
+			case 2:
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				id := binary.LittleEndian.Uint64(bs)
+				if id >= uint64(len(*refs)) {
+					return fmt.Errorf("invalid reference: %d", id)
+				}
+
+				ref, ok := (*refs)[id].(*GraphNodeTestType)
+				if !ok {
+					return fmt.Errorf("reference %d is not a *GraphNodeTestType", id)
+				}
+
+				t.Next = ref
+
+			default:
+				return fmt.Errorf("invalid reference kind: %d", v[0])
+			}
+		}
+	}
+
+	return nil
+}
+
+// EncodeBinary returns a binary-encoded representation of the type.
+func (t PointerRefsConstraintTestType) EncodeBinary() ([]byte, error) {
+	var writer = bytes.NewBuffer(nil)
+	if err := t.WriteBinary(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// WriteBinary writes the binary-encoded representation of the type to the
+// given writer.
+func (t PointerRefsConstraintTestType) WriteBinary(writer io.Writer) error {
+	refs := make(map[interface{}]uint64)
+	{
+
+		{
+			if x := t.A; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else if id, ok := refs[x]; ok {
+				bs := make([]byte, 9)
+				bs[0] = 2
+				binary.LittleEndian.PutUint64(bs[1:], id)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+			} else {
+				refs[x] = uint64(len(refs))
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := (*t.A)
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.B; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else if id, ok := refs[x]; ok {
+				bs := make([]byte, 9)
+				bs[0] = 2
+				binary.LittleEndian.PutUint64(bs[1:], id)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+			} else {
+				refs[x] = uint64(len(refs))
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					v := (*t.B)
+					n := len(v)
+					ux := uint64(n) << 1
+					if n < 0 {
+						ux = ^ux
+					}
+					sz := make([]byte, 8)
+					binary.LittleEndian.PutUint64(sz, ux)
+					if _, err := writer.Write(sz); err != nil {
+						return err
+					}
+
+					_, err := writer.Write([]byte(v))
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+
+		{
+			if x := t.C; x == nil {
+				if _, err := writer.Write([]byte{0}); err != nil {
+					return err
+				}
+			} else if id, ok := refs[x]; ok {
+				bs := make([]byte, 9)
+				bs[0] = 2
+				binary.LittleEndian.PutUint64(bs[1:], id)
+				if _, err := writer.Write(bs); err != nil {
+					return err
+				}
+			} else {
+				refs[x] = uint64(len(refs))
+				if _, err := writer.Write([]byte{1}); err != nil {
+					return err
+				}
+
+				{
+					x := (*t.C)
+					ux := uint64(x) << 1
+					if x < 0 {
+						ux = ^ux
+					}
+					bs := make([]byte, 8)
+					binary.LittleEndian.PutUint64(bs, ux)
+					_, err := writer.Write(bs)
+					if err != nil {
+						return err
+					}
+				}
+
+			}
+		}
+	}
+
+	return nil
+}
+
+// DecodeBinaryFromBytes fills the type with the given binary-encoded
+// representation of the type.
+func (t *PointerRefsConstraintTestType) DecodeBinaryFromBytes(data []byte) error {
+	var reader = bytes.NewReader(data)
+	return t.DecodeBinary(reader)
+}
+
+// DecodeBinary reads the binary representation of the type from the given
+// reader and fulls the type with it.
+func (t *PointerRefsConstraintTestType) DecodeBinary(reader io.Reader) error {
+	refs := new([]interface{})
+	{
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.A = nil
+			case 1:
+				tmp_t_A := new(string)
+				*refs = append(*refs, tmp_t_A)
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					*tmp_t_A = string(b)
+
+				}
+
+				t.A = tmp_t_A
+
+			case 2:
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				id := binary.LittleEndian.Uint64(bs)
+				if id >= uint64(len(*refs)) {
+					return fmt.Errorf("invalid reference: %d", id)
+				}
+
+				ref, ok := (*refs)[id].(*string)
+				if !ok {
+					return fmt.Errorf("reference %d is not a *string", id)
+				}
+
+				t.A = ref
+
+			default:
+				return fmt.Errorf("invalid reference kind: %d", v[0])
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.B = nil
+			case 1:
+				tmp_t_B := new(string)
+				*refs = append(*refs, tmp_t_B)
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+
+					sz := int(x)
+					if sz > 3 {
+						return fmt.Errorf("field '%v' has a maximum length of %v", "B", 3)
+					}
+
+					b := make([]byte, sz)
+					if _, err := io.ReadFull(reader, b); err != nil {
+						return err
+					}
+
+					*tmp_t_B = string(b)
+
+				}
+
+				t.B = tmp_t_B
+
+			case 2:
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				id := binary.LittleEndian.Uint64(bs)
+				if id >= uint64(len(*refs)) {
+					return fmt.Errorf("invalid reference: %d", id)
+				}
+
+				ref, ok := (*refs)[id].(*string)
+				if !ok {
+					return fmt.Errorf("reference %d is not a *string", id)
+				}
+				{
+					sz := len(*ref)
+					if sz > 3 {
+						return fmt.Errorf("field '%v' has a maximum length of %v", "B", 3)
+					}
+				}
+				t.B = ref
+
+			default:
+				return fmt.Errorf("invalid reference kind: %d", v[0])
+			}
+		}
+
+		{
+			var v = make([]byte, 1)
+			if _, err := io.ReadFull(reader, v); err != nil && err != io.EOF {
+				return err
+			}
+
+			switch v[0] {
+			case 0:
+				t.C = nil
+			case 1:
+				tmp_t_C := new(int)
+				*refs = append(*refs, tmp_t_C)
+
+				{
+					var bs = make([]byte, 8)
+					if _, err := io.ReadFull(reader, bs); err != nil {
+						return err
+					}
+
+					ux := binary.LittleEndian.Uint64(bs)
+					x := int64(ux >> 1)
+					if ux&1 != 0 {
+						x = ^x
+					}
+					*tmp_t_C = int(x)
+
+				}
+
+				t.C = tmp_t_C
+				if *t.C > 3 {
+					return fmt.Errorf("field '%v' has a maximum value of %v", "C", 3)
+				}
+
+			case 2:
+				var bs = make([]byte, 8)
+				if _, err := io.ReadFull(reader, bs); err != nil {
+					return err
+				}
+
+				id := binary.LittleEndian.Uint64(bs)
+				if id >= uint64(len(*refs)) {
+					return fmt.Errorf("invalid reference: %d", id)
+				}
+
+				ref, ok := (*refs)[id].(*int)
+				if !ok {
+					return fmt.Errorf("reference %d is not a *int", id)
+				}
+
+				t.C = ref
+				if *t.C > 3 {
+					return fmt.Errorf("field '%v' has a maximum value of %v", "C", 3)
+				}
+
+			default:
+				return fmt.Errorf("invalid reference kind: %d", v[0])
+			}
+		}
+	}
+
+	return nil
+}
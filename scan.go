@@ -3,59 +3,396 @@ package bindec
 import (
 	"fmt"
 	"go/ast"
-	"go/importer"
-	"go/parser"
 	"go/token"
 	"go/types"
-	"os"
+	"path/filepath"
 	"strings"
+
+	"golang.org/x/tools/go/packages"
 )
 
-func getPackage(path string) (*types.Package, error) {
-	if path == "" {
-		var err error
-		path, err = os.Getwd()
-		if err != nil {
-			return nil, err
+// packageInfo is a type-checked package along with the syntax trees of its
+// files.
+type packageInfo struct {
+	*types.Package
+	fset  *token.FileSet
+	files []*ast.File
+	// dir is the directory of the package.
+	dir string
+	// source is the package declaring the types to generate, if it's not
+	// this one.
+	source *packageInfo
+	// deps are the packages imported by this one, directly or indirectly.
+	deps map[*types.Package]*packages.Package
+}
+
+// getPackage loads the package in the directory at the given path, or the
+// working directory if empty, with the given build tags. If the package has
+// test files in the same package, they are loaded as well.
+func getPackage(path string, tags []string) (*packageInfo, error) {
+	pkgs, err := loadPackages(path, tags, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no Go package found in %s", path)
+	}
+
+	return newPackageInfo(pkgs[0])
+}
+
+// getPackageWithSource loads the package in the directory at the given path,
+// like getPackage, along with the package with the given import path, which
+// declares the types to generate and is resolved from the former. If the
+// import path is the one of the loaded package, it's the same as getPackage.
+func getPackageWithSource(path string, tags []string, source string) (*packageInfo, error) {
+	pkgs, err := packages.Load(loadConfig(path, tags), ".", source)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*packages.Package
+	var src *packages.Package
+	for _, pkg := range pkgs {
+		if pkg.PkgPath != source {
+			candidates = append(candidates, pkg)
+		} else if pkg.ID == source {
+			// Types declared in test files can't be used from other
+			// packages, so the source package is never the test one.
+			src = pkg
 		}
 	}
 
-	fset := token.NewFileSet()
-	pkgs, err := parser.ParseDir(fset, path, nil, parser.ParseComments)
+	pkg := selectPackage(candidates)
+	if pkg == nil {
+		// The source package is the one in the given path.
+		if pkg = selectPackage(pkgs); pkg == nil {
+			return nil, fmt.Errorf("no Go package found in %s", path)
+		}
+		return newPackageInfo(pkg)
+	}
+
+	if src == nil {
+		return nil, fmt.Errorf("package %s not found", source)
+	}
+
+	info, err := newPackageInfo(pkg)
 	if err != nil {
 		return nil, err
 	}
 
-	var files []*ast.File
-	for name, pkg := range pkgs {
-		if strings.HasSuffix(name, "_test") {
-			continue
+	info.source, err = newPackageInfo(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// loadPackages loads the packages matching the given patterns, relative to
+// the directory at the given path, with the given build tags. Only one
+// package is returned for each import path, as selectPackage does.
+func loadPackages(path string, tags []string, patterns ...string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(loadConfig(path, tags), patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	var byPath = make(map[string][]*packages.Package)
+	for _, pkg := range pkgs {
+		if _, ok := byPath[pkg.PkgPath]; !ok {
+			paths = append(paths, pkg.PkgPath)
 		}
+		byPath[pkg.PkgPath] = append(byPath[pkg.PkgPath], pkg)
+	}
 
-		for _, f := range pkg.Files {
-			files = append(files, f)
+	var result []*packages.Package
+	for _, path := range paths {
+		if pkg := selectPackage(byPath[path]); pkg != nil {
+			result = append(result, pkg)
 		}
-		break
+	}
+	return result, nil
+}
+
+// loadConfig returns the configuration to load packages relative to the
+// directory at the given path with the given build tags.
+func loadConfig(path string, tags []string) *packages.Config {
+	cfg := &packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedImports |
+			packages.NeedSyntax |
+			packages.NeedTypes |
+			packages.NeedDeps,
+		Dir:   path,
+		Tests: true,
 	}
 
-	return typeCheck(path, fset, files)
+	if len(tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(tags, ",")}
+	}
+	return cfg
 }
 
-func typeCheck(path string, fset *token.FileSet, files []*ast.File) (*types.Package, error) {
-	cfg := &types.Config{
-		IgnoreFuncBodies:         true,
-		FakeImportC:              true,
-		DisableUnusedImportCheck: true,
-		Importer:                 importer.For("source", nil),
+// newPackageInfo returns the information of a loaded package, or an error if
+// the package could not be loaded.
+func newPackageInfo(pkg *packages.Package) (*packageInfo, error) {
+	for _, e := range pkg.Errors {
+		if e.Kind != packages.TypeError {
+			return nil, e
+		}
 	}
 
-	return cfg.Check(path, fset, files, nil)
+	// Function bodies may use the methods that are going to be generated,
+	// so only errors outside of them are reported.
+	for _, e := range pkg.TypeErrors {
+		if !inFuncBody(pkg.Syntax, e.Pos) {
+			return nil, e
+		}
+	}
+
+	var dir string
+	if len(pkg.GoFiles) > 0 {
+		dir = filepath.Dir(pkg.GoFiles[0])
+	}
+
+	var deps = make(map[*types.Package]*packages.Package)
+	var imports []*packages.Package
+	for _, imp := range pkg.Imports {
+		imports = append(imports, imp)
+	}
+	packages.Visit(imports, nil, func(dep *packages.Package) {
+		if dep.Types != nil {
+			deps[dep.Types] = dep
+		}
+	})
+
+	return &packageInfo{pkg.Types, pkg.Fset, pkg.Syntax, dir, nil, deps}, nil
 }
 
-func findType(pkg *types.Package, typ string) (types.Type, error) {
+// dependency returns the information of the given package, which must be
+// imported by this one or its source package, or nil if it's not.
+func (pkg *packageInfo) dependency(p *types.Package) *packageInfo {
+	if pkg.source != nil {
+		if p == pkg.source.Package {
+			return pkg.source
+		}
+
+		if dep := pkg.source.dependency(p); dep != nil {
+			return dep
+		}
+	}
+
+	dep, ok := pkg.deps[p]
+	if !ok {
+		return nil
+	}
+
+	info, err := newPackageInfo(dep)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// selectPackage returns the package to generate code for among the loaded
+// ones. The package compiled for its tests is preferred, because it also
+// contains the types declared in test files.
+func selectPackage(pkgs []*packages.Package) *packages.Package {
+	var result *packages.Package
+	for _, pkg := range pkgs {
+		if pkg.Name == "main" && strings.HasSuffix(pkg.PkgPath, ".test") ||
+			strings.HasSuffix(pkg.PkgPath, "_test") {
+			continue
+		}
+
+		if result == nil || strings.HasSuffix(pkg.ID, ".test]") {
+			result = pkg
+		}
+	}
+	return result
+}
+
+func inFuncBody(files []*ast.File, pos token.Pos) bool {
+	for _, f := range files {
+		if pos < f.Pos() || pos > f.End() {
+			continue
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Body != nil && fn.Body.Pos() <= pos && pos <= fn.Body.End() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func findType(pkg *packageInfo, typ string) (types.Type, error) {
+	// Methods can't be declared on instantiated generic types.
+	if i := strings.Index(typ, "["); i >= 0 {
+		return nil, fmt.Errorf(
+			"can't generate methods for instantiated type %s, generate them for %s or for a type defined as %s instead",
+			typ, typ[:i], typ,
+		)
+	}
+
 	obj := pkg.Scope().Lookup(typ)
 	if obj == nil {
 		return nil, fmt.Errorf("type %s not found in %s", typ, pkg.Path())
 	}
+
+	// Generic types are instantiated with their own type parameters, just
+	// like they are in the receivers of their methods.
+	if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+		var args = make([]types.Type, named.TypeParams().Len())
+		for i := range args {
+			args[i] = named.TypeParams().At(i)
+		}
+		return types.Instantiate(nil, named, args, false)
+	}
+
 	return obj.Type(), nil
 }
+
+// typesWithDirective returns the types of the package that have a
+// directive with the given name, in the order they are declared.
+func (pkg *packageInfo) typesWithDirective(name string) []*types.TypeName {
+	var result []*types.TypeName
+	for _, f := range pkg.files {
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				obj, ok := pkg.Scope().Lookup(spec.(*ast.TypeSpec).Name.Name).(*types.TypeName)
+				if ok && len(pkg.directives(obj, name)) > 0 {
+					result = append(result, obj)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// tagPos returns the position of the struct tag of the given field, or the
+// position of the field if it has no tag or its syntax was not loaded.
+func (pkg *packageInfo) tagPos(f *types.Var) token.Pos {
+	files := pkg.files
+	if pkg.source != nil {
+		files = append(files[:len(files):len(files)], pkg.source.files...)
+	}
+
+	pos := f.Pos()
+	for _, file := range files {
+		if pos < file.Pos() || pos > file.End() {
+			continue
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			if field, ok := n.(*ast.Field); ok && field.Tag != nil && declares(field, f) {
+				pos = field.Tag.Pos()
+			}
+			return pos == f.Pos()
+		})
+	}
+	return pos
+}
+
+// declares reports whether the given field declares the field f.
+func declares(field *ast.Field, f *types.Var) bool {
+	// The position of embedded fields is the one of their type name, which
+	// may be a pointer or qualified.
+	if len(field.Names) == 0 {
+		return field.Type.Pos() <= f.Pos() && f.Pos() < field.Type.End()
+	}
+
+	for _, name := range field.Names {
+		if name.Pos() == f.Pos() {
+			return true
+		}
+	}
+	return false
+}
+
+// inTestFile reports whether the given position is in a test file.
+func (pkg *packageInfo) inTestFile(pos token.Pos) bool {
+	return strings.HasSuffix(pkg.fset.Position(pos).Filename, "_test.go")
+}
+
+// isGenerated reports whether the given position is in a file generated by
+// bindec.
+func (pkg *packageInfo) isGenerated(pos token.Pos) bool {
+	file := pkg.fset.File(pos)
+	for _, f := range pkg.files {
+		if pkg.fset.File(f.Pos()) != file {
+			continue
+		}
+
+		for _, c := range f.Comments {
+			if c.Pos() > f.Package {
+				break
+			}
+
+			if strings.Contains(c.Text(), "code generated by bindec") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// directives returns the arguments of all the directives with the given
+// name in the doc comment of the declaration of the given type. A directive
+// is a line comment such as "//bindec:name args". Types of the source
+// package have their directives read from it.
+func (pkg *packageInfo) directives(obj *types.TypeName, name string) []string {
+	if pkg.source != nil && obj.Pkg() == pkg.source.Package {
+		return pkg.source.directives(obj, name)
+	}
+
+	if obj.Pkg() != pkg.Package {
+		return nil
+	}
+
+	prefix := "//bindec:" + name
+	var result []string
+	for _, f := range pkg.files {
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if spec.Name.Pos() != obj.Pos() {
+					continue
+				}
+
+				doc := spec.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+
+				if doc == nil {
+					return nil
+				}
+
+				for _, c := range doc.List {
+					if c.Text == prefix || strings.HasPrefix(c.Text, prefix+" ") {
+						result = append(result, strings.TrimSpace(c.Text[len(prefix):]))
+					}
+				}
+				return result
+			}
+		}
+	}
+	return nil
+}
@@ -2,11 +2,12 @@ package bindec
 
 import (
 	"go/types"
+	"strings"
 	"testing"
 )
 
 func TestGetPackage(t *testing.T) {
-	pkg, err := getPackage("")
+	pkg, err := getPackage("", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -14,7 +15,7 @@ func TestGetPackage(t *testing.T) {
 		t.Errorf("expected package name to be bindec, is %q", pkg.Name())
 	}
 
-	pkg, err = getPackage("./cmd/bindec")
+	pkg, err = getPackage("./cmd/bindec", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -24,8 +25,46 @@ func TestGetPackage(t *testing.T) {
 	}
 }
 
+func TestGetPackageTags(t *testing.T) {
+	testCases := []struct {
+		tags    []string
+		present []string
+		missing []string
+	}{
+		{nil, []string{"Always", "Default"}, []string{"Extra"}},
+		{[]string{"extra"}, []string{"Always", "Extra"}, []string{"Default"}},
+	}
+
+	for _, tt := range testCases {
+		pkg, err := getPackage("./internal/testpkg/tagged", tt.tags)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pkg.Name() != "tagged" {
+			t.Errorf("expected package name to be tagged, is %q", pkg.Name())
+		}
+
+		if pkg.Path() != "github.com/erizocosmico/bindec/internal/testpkg/tagged" {
+			t.Errorf("unexpected package path %q", pkg.Path())
+		}
+
+		for _, name := range tt.present {
+			if pkg.Scope().Lookup(name) == nil {
+				t.Errorf("with tags %v: expected type %s to be found", tt.tags, name)
+			}
+		}
+
+		for _, name := range tt.missing {
+			if pkg.Scope().Lookup(name) != nil {
+				t.Errorf("with tags %v: expected type %s not to be found", tt.tags, name)
+			}
+		}
+	}
+}
+
 func TestFindType(t *testing.T) {
-	pkg, err := getPackage("")
+	pkg, err := getPackage("", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -40,3 +79,37 @@ func TestFindType(t *testing.T) {
 		t.Errorf("expecting Options to be *types.Named, is %T", typ)
 	}
 }
+
+func TestDirectives(t *testing.T) {
+	pkg, err := getPackage("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj := pkg.Scope().Lookup("EventTestType").(*types.TypeName)
+	args := pkg.directives(obj, "union")
+	if len(args) != 1 || args[0] != "CreatedTestType *DeletedTestType" {
+		t.Errorf("unexpected union directives: %q", args)
+	}
+
+	obj = pkg.Scope().Lookup("CreatedTestType").(*types.TypeName)
+	if args := pkg.directives(obj, "union"); len(args) != 0 {
+		t.Errorf("expected no union directives, got: %q", args)
+	}
+}
+
+func TestTypesWithDirective(t *testing.T) {
+	pkg, err := getPackage("./internal/testpkg/tagged", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, obj := range pkg.typesWithDirective("generate") {
+		names = append(names, obj.Name())
+	}
+
+	if strings.Join(names, ",") != "Default,Always,Node" {
+		t.Errorf("unexpected types with generate directive: %q", names)
+	}
+}
@@ -0,0 +1,250 @@
+package bindec
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+
+	"github.com/erizocosmico/bindec/schema"
+)
+
+// Schema returns the schema of the types that Generate would generate with
+// the given options, which describes their binary layout so they can be
+// decoded without the generated code. Problems in the declarations of the
+// types are returned as Diagnostics.
+func Schema(opts Options) (*schema.Schema, error) {
+	pkg, err := optionsPackage(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	typs, err := inspect(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg.source != nil {
+		pkg = pkg.source
+	}
+
+	s := &schema.Schema{
+		Version: schema.Version,
+		Package: pkg.Path(),
+		Types:   make([]schema.Named, len(typs)),
+	}
+
+	for i, t := range typs {
+		s.Types[i], err = namedSchema(pkg, t)
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %s", t.Name, err)
+		}
+	}
+
+	return s, nil
+}
+
+func namedSchema(pkg *packageInfo, t NamedType) (schema.Named, error) {
+	typ, err := typeSchema(pkg, t.Type)
+	if err != nil {
+		return schema.Named{}, err
+	}
+
+	named := schema.Named{Name: t.Name, Type: typ, MaxDepth: t.MaxDepth}
+	if len(t.Recursive) == 0 {
+		return named, nil
+	}
+
+	var names []string
+	for name := range t.Recursive {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	named.Definitions = make(map[string]*schema.Type, len(names))
+	for _, name := range names {
+		named.Definitions[name], err = typeSchema(pkg, t.Recursive[name])
+		if err != nil {
+			return schema.Named{}, fmt.Errorf("recursive type %s: %s", name, err)
+		}
+	}
+
+	return named, nil
+}
+
+// basicKinds are the schema kinds of the basic types.
+var basicKinds = map[BasicKind]schema.Kind{
+	String:     schema.String,
+	Bool:       schema.Bool,
+	Int:        schema.Int,
+	Int8:       schema.Int8,
+	Int16:      schema.Int16,
+	Int32:      schema.Int32,
+	Int64:      schema.Int64,
+	Uint:       schema.Uint,
+	Uint8:      schema.Uint8,
+	Uint16:     schema.Uint16,
+	Uint32:     schema.Uint32,
+	Uint64:     schema.Uint64,
+	Uintptr:    schema.Uintptr,
+	Float32:    schema.Float32,
+	Float64:    schema.Float64,
+	Complex64:  schema.Complex64,
+	Complex128: schema.Complex128,
+}
+
+// lengthPrefix is the size of the length prefix of strings, bytes, slices,
+// maps and marshalers.
+const lengthPrefix = 8
+
+// typeSchema returns the schema of a type parsed in the given package.
+func typeSchema(pkg *packageInfo, t Type) (*schema.Type, error) {
+	var result *schema.Type
+	switch t := t.(type) {
+	case Basic:
+		kind, ok := basicKinds[t.Kind]
+		if !ok {
+			return nil, fmt.Errorf("invalid basic kind %d", t.Kind)
+		}
+
+		result = &schema.Type{Kind: kind}
+		if kind == schema.String {
+			result.Prefix = lengthPrefix
+		}
+	case Bytes:
+		result = &schema.Type{Kind: schema.Bytes, Prefix: lengthPrefix}
+	case Duration:
+		result = &schema.Type{Kind: schema.Duration}
+	case Time:
+		result = &schema.Type{Kind: schema.Time}
+	case Maybe:
+		elem, err := typeSchema(pkg, t.Elem)
+		if err != nil {
+			return nil, err
+		}
+		result = &schema.Type{Kind: schema.Maybe, Elem: elem, Refs: t.Refs}
+	case Slice:
+		elem, err := typeSchema(pkg, t.Elem)
+		if err != nil {
+			return nil, err
+		}
+		result = &schema.Type{Kind: schema.Slice, Prefix: lengthPrefix, Elem: elem}
+	case Array:
+		elem, err := typeSchema(pkg, t.Elem)
+		if err != nil {
+			return nil, err
+		}
+		result = &schema.Type{Kind: schema.Array, Len: t.Len, Elem: elem}
+	case Map:
+		key, err := typeSchema(pkg, t.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		elem, err := typeSchema(pkg, t.Elem)
+		if err != nil {
+			return nil, err
+		}
+		result = &schema.Type{Kind: schema.Map, Prefix: lengthPrefix, Key: key, Elem: elem}
+	case Struct:
+		result = &schema.Type{Kind: schema.Struct, Fields: make([]schema.Field, len(t.Fields))}
+		for i, f := range t.Fields {
+			typ, err := typeSchema(pkg, f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %s", f.Name, err)
+			}
+
+			result.Fields[i] = schema.Field{Name: f.Name, Type: typ}
+			for _, c := range f.Specs {
+				result.Fields[i].Constraints = append(result.Fields[i].Constraints, schema.Constraint{
+					Name: c.Name,
+					Args: c.Args,
+				})
+			}
+		}
+	case Embedded:
+		// Flattened embedded pointers are encoded like the struct they
+		// point to.
+		return typeSchema(pkg, t.Struct)
+	case Union:
+		variants, err := variantsSchema(pkg, t.Types, true)
+		if err != nil {
+			return nil, err
+		}
+		result = &schema.Type{Kind: schema.Union, Variants: variants}
+	case TypeParam:
+		variants, err := variantsSchema(pkg, t.Types, false)
+		if err != nil {
+			return nil, err
+		}
+		result = &schema.Type{Kind: schema.TypeParam, Name: t.TypeName, Variants: variants}
+	case Marshaler:
+		result = &schema.Type{Kind: schema.Marshaler, Name: t.TypeName, Prefix: lengthPrefix}
+	case Delegate:
+		layout, err := delegateSchema(pkg, t)
+		if err != nil {
+			return nil, err
+		}
+		result = &schema.Type{Kind: schema.Delegate, Name: t.TypeName, Layout: layout}
+	case Recursive:
+		result = &schema.Type{Kind: schema.Ref, Name: t.TypeName}
+	default:
+		return nil, fmt.Errorf("type %T has no schema", t)
+	}
+
+	if size, ok := FixedSize(t); ok {
+		result.Size = size
+	}
+	return result, nil
+}
+
+func variantsSchema(pkg *packageInfo, types []UnionType, tagged bool) ([]schema.Variant, error) {
+	var variants = make([]schema.Variant, len(types))
+	for i, u := range types {
+		typ, err := typeSchema(pkg, u.Type)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: %s", u.TypeName, err)
+		}
+
+		variants[i] = schema.Variant{Name: u.TypeName, Type: typ}
+		if tagged {
+			variants[i].Tag = i + 1
+		}
+	}
+	return variants, nil
+}
+
+// delegateSchema returns the schema of the type a delegate delegates to, as
+// its generated methods encode it. The type is parsed as if it was being
+// generated in its own package, with the options of its generate directive,
+// if any. If its methods were not generated by bindec, its layout is
+// unknown and nil is returned.
+func delegateSchema(pkg *packageInfo, t Delegate) (*schema.Named, error) {
+	obj := t.named.Obj()
+	dep := pkg.dependency(obj.Pkg())
+	if dep == nil {
+		return nil, fmt.Errorf("package %s of type %s not found", obj.Pkg().Path(), t.TypeName)
+	}
+
+	method, _, _ := types.LookupFieldOrMethod(t.named, true, nil, "WriteBinary")
+	if method == nil || !dep.isGenerated(method.Pos()) {
+		return nil, nil
+	}
+
+	gen := target{name: obj.Name(), recv: "t", maxDepth: DefaultMaxDepth}
+	for _, args := range dep.directives(obj, "generate") {
+		if err := parseGenerateDirective(&gen, args); err != nil {
+			return nil, fmt.Errorf("invalid generate directive of type %s: %s", t.TypeName, err)
+		}
+	}
+
+	parsed, err := parseTargets(newParseContext(dep), []target{gen}, []types.Type{t.named})
+	if err != nil {
+		return nil, fmt.Errorf("type %s: %s", t.TypeName, err)
+	}
+
+	named, err := namedSchema(dep, namedType(dep, parsed[0], t.named))
+	if err != nil {
+		return nil, fmt.Errorf("type %s: %s", t.TypeName, err)
+	}
+	return &named, nil
+}
@@ -0,0 +1,506 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/bindec/internal/validate"
+)
+
+// Compatibility is the effect of a change of a type on the data encoded
+// before the change.
+type Compatibility string
+
+const (
+	// Compatible changes don't prevent decoding data encoded before them.
+	Compatible Compatibility = "compatible"
+	// Breaking changes make data encoded before them fail to decode or be
+	// decoded as different values.
+	Breaking Compatibility = "breaking"
+)
+
+// Change is a difference between two versions of a type.
+type Change struct {
+	// Type is the name of the changed type.
+	Type string `json:"type"`
+	// Path of the changed value from the type, such as "Items[].Name", or
+	// empty if it's the type itself.
+	Path string `json:"path,omitempty"`
+	// Compatibility of the change.
+	Compatibility Compatibility `json:"compatibility"`
+	// Message describes the change.
+	Message string `json:"message"`
+}
+
+// String returns the change as text.
+func (c Change) String() string {
+	name := c.Type
+	if strings.HasPrefix(c.Path, "[") || strings.HasPrefix(c.Path, "(") {
+		name += c.Path
+	} else if c.Path != "" {
+		name += "." + c.Path
+	}
+	return fmt.Sprintf("%s: %s: %s", c.Compatibility, name, c.Message)
+}
+
+// HasBreaking reports whether any of the changes is breaking.
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Compatibility == Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns the changes of the types of the old schema in the new
+// one, in the order of the types of the old schema, followed by the types
+// only in the new one. Changes are breaking if the data encoded by the old
+// type can't be decoded by the new one as the same values, such as
+// reordered, removed or added fields, or changes of the layout of a type.
+// Since decoders read all the fields of a struct, appending a field is a
+// breaking change too, except for maybes appended to the struct of a type
+// after its first field, which are decoded as nil when the data ends before
+// them. Renaming fields,
+// adding types to unions, and relaxing or removing constraints are
+// compatible changes.
+func Compare(old, new *Schema) []Change {
+	var changes []Change
+	for i := range old.Types {
+		o := &old.Types[i]
+		n := lookup(new, o.Name)
+		if n == nil {
+			changes = append(changes, Change{o.Name, "", Breaking, "type removed"})
+			continue
+		}
+		changes = append(changes, CompareNamed(o, n)...)
+	}
+
+	for _, n := range new.Types {
+		if lookup(old, n.Name) == nil {
+			changes = append(changes, Change{n.Name, "", Compatible, "type added"})
+		}
+	}
+
+	return changes
+}
+
+func lookup(s *Schema, name string) *Named {
+	for i := range s.Types {
+		if s.Types[i].Name == name {
+			return &s.Types[i]
+		}
+	}
+	return nil
+}
+
+// CompareNamed returns the changes of the old version of a type in the new
+// one, as Compare does.
+func CompareNamed(old, new *Named) []Change {
+	return compareNamed(old, new, false)
+}
+
+// compareNamed compares two versions of a type. Delegates are followed by
+// the rest of the data, so maybes appended to their layout are not decoded
+// as nil when data encoded before the change is read.
+func compareNamed(old, new *Named, delegate bool) []Change {
+	c := &comparer{
+		typ:      old.Name,
+		old:      old,
+		new:      new,
+		delegate: delegate,
+		visited:  make(map[[2]string]bool),
+	}
+
+	if new.MaxDepth < old.MaxDepth {
+		c.breaking("", "maximum depth decreased from %d to %d", old.MaxDepth, new.MaxDepth)
+	} else if new.MaxDepth > old.MaxDepth {
+		c.compatible("", "maximum depth increased from %d to %d", old.MaxDepth, new.MaxDepth)
+	}
+
+	c.compare("", old.Type, new.Type)
+	return c.changes
+}
+
+type comparer struct {
+	typ      string
+	old, new *Named
+	delegate bool
+	changes  []Change
+	// visited are the pairs of definitions already compared, so recursive
+	// types are only compared once.
+	visited map[[2]string]bool
+}
+
+func (c *comparer) breaking(path, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{c.typ, path, Breaking, fmt.Sprintf(format, args...)})
+}
+
+func (c *comparer) compatible(path, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{c.typ, path, Compatible, fmt.Sprintf(format, args...)})
+}
+
+// sameLayout are kinds that are encoded in the same way and decoded as the
+// same values.
+var sameLayout = map[Kind]Kind{
+	Int:     Int64,
+	Uint:    Uint64,
+	Uintptr: Uint64,
+	Bytes:   String,
+}
+
+func layoutKind(k Kind) Kind {
+	if same, ok := sameLayout[k]; ok {
+		return same
+	}
+	return k
+}
+
+// describe returns the name of a type for the messages of changes.
+func describe(t *Type) string {
+	if t.Name != "" && t.Kind != Ref {
+		return fmt.Sprintf("%s %s", t.Kind, t.Name)
+	}
+	return string(t.Kind)
+}
+
+func (c *comparer) compare(path string, old, new *Type) {
+	var oldName, newName string
+	if old.Kind == Ref {
+		oldName = old.Name
+		old = c.old.Definitions[old.Name]
+	}
+
+	if new.Kind == Ref {
+		newName = new.Name
+		new = c.new.Definitions[new.Name]
+	}
+
+	if old == nil || new == nil {
+		c.breaking(path, "definition of a recursive type not found")
+		return
+	}
+
+	if oldName != "" || newName != "" {
+		pair := [2]string{oldName, newName}
+		if c.visited[pair] {
+			return
+		}
+		c.visited[pair] = true
+	}
+
+	if layoutKind(old.Kind) != layoutKind(new.Kind) {
+		c.breaking(path, "type changed from %s to %s", describe(old), describe(new))
+		return
+	}
+
+	if old.Kind != new.Kind {
+		c.compatible(path, "type changed from %s to %s, which have the same layout", old.Kind, new.Kind)
+	}
+
+	switch old.Kind {
+	case Marshaler:
+		if old.Name != new.Name {
+			c.breaking(path, "type changed from %s to %s", describe(old), describe(new))
+		}
+	case Delegate:
+		c.compareDelegates(path, old, new)
+	case Array:
+		if old.Len != new.Len {
+			c.breaking(path, "array length changed from %d to %d", old.Len, new.Len)
+			return
+		}
+		c.compare(path+"[]", old.Elem, new.Elem)
+	case Slice:
+		c.compare(path+"[]", old.Elem, new.Elem)
+	case Map:
+		c.compare(path+"[key]", old.Key, new.Key)
+		c.compare(path+"[]", old.Elem, new.Elem)
+	case Maybe:
+		if old.Refs != new.Refs {
+			if new.Refs {
+				c.breaking(path, "reference tracking enabled")
+			} else {
+				c.breaking(path, "reference tracking disabled")
+			}
+		}
+		c.compare(path, old.Elem, new.Elem)
+	case Struct:
+		root := old == c.old.Type && new == c.new.Type && !c.delegate
+		c.compareFields(path, old.Fields, new.Fields, root)
+	case Union:
+		c.compareVariants(path, old.Variants, new.Variants)
+	case TypeParam:
+		if old.Name != new.Name || len(old.Variants) != len(new.Variants) {
+			c.breaking(path, "type parameter changed from %s to %s", describe(old), describe(new))
+			return
+		}
+		c.compareVariants(path, old.Variants, new.Variants)
+	}
+}
+
+// compareDelegates compares the layouts of delegates, whose definitions
+// and maximum depth are their own. Delegates with unknown layouts can only
+// be compared by name.
+func (c *comparer) compareDelegates(path string, old, new *Type) {
+	if old.Layout == nil || new.Layout == nil {
+		if old.Name != new.Name {
+			c.breaking(path, "type changed from %s to %s", describe(old), describe(new))
+		} else if old.Layout != nil || new.Layout != nil {
+			c.compatible(path, "layout of %s can't be compared because it's unknown in one of the versions", describe(old))
+		}
+		return
+	}
+
+	if old.Name != new.Name {
+		c.compatible(path, "type changed from %s to %s", describe(old), describe(new))
+	}
+
+	for _, change := range compareNamed(old.Layout, new.Layout, true) {
+		change.Type = c.typ
+		if strings.HasPrefix(change.Path, "[") || strings.HasPrefix(change.Path, "(") {
+			change.Path = path + change.Path
+		} else {
+			change.Path = fieldPath(path, change.Path)
+		}
+		c.changes = append(c.changes, change)
+	}
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldIndex(fields []Field, name string) int {
+	for i, f := range fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// compareFields compares the fields of two versions of a struct. Maybes
+// appended to the struct of the type, given by root, after its first field
+// are compatible.
+func (c *comparer) compareFields(path string, old, new []Field, root bool) {
+	// Fields renamed keep their position and are in neither version with
+	// the other name.
+	renamed := make(map[int]bool)
+	if len(old) == len(new) {
+		for i := range old {
+			if old[i].Name != new[i].Name && fieldIndex(new, old[i].Name) < 0 && fieldIndex(old, new[i].Name) < 0 {
+				renamed[i] = true
+			}
+		}
+	}
+
+	var oldOrder, newOrder []string
+	for _, f := range old {
+		if fieldIndex(new, f.Name) >= 0 {
+			oldOrder = append(oldOrder, f.Name)
+		}
+	}
+
+	for _, f := range new {
+		if fieldIndex(old, f.Name) >= 0 {
+			newOrder = append(newOrder, f.Name)
+		}
+	}
+
+	if strings.Join(oldOrder, ",") != strings.Join(newOrder, ",") {
+		c.breaking(
+			path, "fields reordered from %s to %s",
+			strings.Join(oldOrder, ", "), strings.Join(newOrder, ", "),
+		)
+	}
+
+	for i, f := range old {
+		if renamed[i] {
+			c.compatible(fieldPath(path, new[i].Name), "field renamed from %s", f.Name)
+			c.compare(fieldPath(path, new[i].Name), f.Type, new[i].Type)
+			c.compareConstraints(fieldPath(path, new[i].Name), f.Constraints, new[i].Constraints)
+			continue
+		}
+
+		j := fieldIndex(new, f.Name)
+		if j < 0 {
+			c.breaking(fieldPath(path, f.Name), "field removed")
+			continue
+		}
+
+		c.compare(fieldPath(path, f.Name), f.Type, new[j].Type)
+		c.compareConstraints(fieldPath(path, f.Name), f.Constraints, new[j].Constraints)
+	}
+
+	for i, f := range new {
+		if renamed[i] || fieldIndex(old, f.Name) >= 0 {
+			continue
+		}
+
+		// The first field is never missing, as data without it is empty.
+		appended, optional := true, root && i > 0
+		for _, next := range new[i:] {
+			if fieldIndex(old, next.Name) >= 0 {
+				appended = false
+				break
+			}
+			optional = optional && next.Type.Kind == Maybe
+		}
+
+		switch {
+		case appended && optional:
+			c.compatible(fieldPath(path, f.Name), "maybe field appended, it's nil in data encoded before the change")
+		case appended:
+			c.breaking(fieldPath(path, f.Name), "field appended, data encoded before the change does not contain it")
+		default:
+			c.breaking(fieldPath(path, f.Name), "field inserted at position %d", i+1)
+		}
+	}
+}
+
+func (c *comparer) compareVariants(path string, old, new []Variant) {
+	for i, v := range old {
+		if i >= len(new) {
+			c.breaking(path, "type %s removed", v.Name)
+			continue
+		}
+
+		if v.Name != new[i].Name {
+			if j := variantIndex(new, v.Name); j >= 0 {
+				c.breaking(path, "type %s moved from position %d to %d", v.Name, i+1, j+1)
+			} else {
+				c.breaking(path, "type %s replaced by %s", v.Name, new[i].Name)
+			}
+			continue
+		}
+
+		c.compare(fmt.Sprintf("%s(%s)", path, v.Name), v.Type, new[i].Type)
+	}
+
+	for _, v := range new[min(len(old), len(new)):] {
+		if variantIndex(old, v.Name) < 0 {
+			c.compatible(path, "type %s added", v.Name)
+		}
+	}
+}
+
+func variantIndex(variants []Variant, name string) int {
+	for i, v := range variants {
+		if v.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func constraintIndex(cs []Constraint, name string) int {
+	for i, c := range cs {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *comparer) compareConstraints(path string, old, new []Constraint) {
+	for _, o := range old {
+		i := constraintIndex(new, o.Name)
+		if i < 0 {
+			c.compatible(path, "constraint %s removed", describeConstraint(o))
+			continue
+		}
+
+		n := new[i]
+		if o.Args == n.Args {
+			continue
+		}
+
+		if widened(o, n) {
+			c.compatible(path, "constraint %s relaxed to %s", describeConstraint(o), describeConstraint(n))
+		} else {
+			c.breaking(path, "constraint %s changed to %s", describeConstraint(o), describeConstraint(n))
+		}
+	}
+
+	for _, n := range new {
+		if constraintIndex(old, n.Name) < 0 {
+			c.breaking(path, "constraint %s added, data encoded before may not satisfy it", describeConstraint(n))
+		}
+	}
+}
+
+func describeConstraint(c Constraint) string {
+	if c.Args == "" {
+		return c.Name
+	}
+	return c.Name + "=" + c.Args
+}
+
+// widened reports whether all the values that satisfy the old constraint
+// satisfy the new one as well.
+func widened(old, new Constraint) bool {
+	switch old.Name {
+	case "min", "minlen":
+		o, n, ok := parseBounds(old.Args, new.Args)
+		return ok && n <= o
+	case "max", "maxlen":
+		o, n, ok := parseBounds(old.Args, new.Args)
+		return ok && n >= o
+	case "before", "after":
+		o, err1 := time.Parse(time.RFC3339Nano, old.Args)
+		n, err2 := time.Parse(time.RFC3339Nano, new.Args)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+
+		if old.Name == "before" {
+			return !n.Before(o)
+		}
+		return !n.After(o)
+	case "oneof":
+		values := strings.Fields(new.Args)
+		for _, o := range strings.Fields(old.Args) {
+			if !contains(values, o) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// parseBounds parses the arguments of numeric constraints, which are
+// numbers or durations.
+func parseBounds(old, new string) (float64, float64, bool) {
+	o, err1 := strconv.ParseFloat(old, 64)
+	n, err2 := strconv.ParseFloat(new, 64)
+	if err1 == nil && err2 == nil {
+		return o, n, true
+	}
+
+	// Durations are parsed as they are by the constraints, which also take
+	// integer nanoseconds.
+	od, err1 := validate.ParseDuration(old)
+	nd, err2 := validate.ParseDuration(new)
+	if err1 == nil && err2 == nil {
+		return float64(od), float64(nd), true
+	}
+
+	return 0, 0, false
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
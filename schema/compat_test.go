@@ -0,0 +1,239 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fooSchema returns a schema with a type Foo with the given layout, in JSON.
+func fooSchema(t *testing.T, typ string) *Schema {
+	t.Helper()
+	var named Named
+	err := json.Unmarshal([]byte(`{"name": "Foo", "maxdepth": 10, "type": `+typ+`}`), &named)
+	if err != nil {
+		t.Fatalf("invalid type: %s", err)
+	}
+	return &Schema{Version: Version, Types: []Named{named}}
+}
+
+func TestCompare(t *testing.T) {
+	const (
+		fields   = `{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}}, {"name": "B", "type": {"kind": "string"}}]}`
+		variant  = `{"tag": %d, "name": "%s", "type": {"kind": "int8"}}`
+		delegate = `{"kind": "delegate", "name": "%s", "layout": {"name": "Bar", "maxdepth": %d, "type": {"kind": "slice", "elem": {"kind": "%s"}}}}`
+	)
+
+	testCases := []struct {
+		name     string
+		old, new string
+		expected []string
+	}{
+		{"same", fields, fields, nil},
+		{
+			"reordered",
+			fields,
+			`{"kind": "struct", "fields": [{"name": "B", "type": {"kind": "string"}}, {"name": "A", "type": {"kind": "int32"}}]}`,
+			[]string{"breaking: Foo: fields reordered from A, B to B, A"},
+		},
+		{
+			"removed",
+			fields,
+			`{"kind": "struct", "fields": [{"name": "B", "type": {"kind": "string"}}]}`,
+			[]string{"breaking: Foo.A: field removed"},
+		},
+		{
+			"appended",
+			fields,
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}}, {"name": "B", "type": {"kind": "string"}}, {"name": "C", "type": {"kind": "maybe", "elem": {"kind": "int8"}}}]}`,
+			[]string{"compatible: Foo.C: maybe field appended, it's nil in data encoded before the change"},
+		},
+		{
+			"appended with a maybe",
+			fields,
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}}, {"name": "B", "type": {"kind": "string"}}, {"name": "C", "type": {"kind": "bool"}}, {"name": "D", "type": {"kind": "maybe", "elem": {"kind": "int8"}}}]}`,
+			[]string{
+				"breaking: Foo.C: field appended, data encoded before the change does not contain it",
+				"compatible: Foo.D: maybe field appended, it's nil in data encoded before the change",
+			},
+		},
+		{
+			"maybe appended to an empty struct",
+			`{"kind": "struct"}`,
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "maybe", "elem": {"kind": "int8"}}}]}`,
+			[]string{"breaking: Foo.A: field appended, data encoded before the change does not contain it"},
+		},
+		{
+			"appended to a nested struct",
+			`{"kind": "slice", "elem": ` + fields + `}`,
+			`{"kind": "slice", "elem": {"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}}, {"name": "B", "type": {"kind": "string"}}, {"name": "C", "type": {"kind": "maybe", "elem": {"kind": "int8"}}}]}}`,
+			[]string{"breaking: Foo[].C: field appended, data encoded before the change does not contain it"},
+		},
+		{
+			"appended to a delegate",
+			`{"kind": "delegate", "name": "bar.Bar", "layout": {"name": "Bar", "maxdepth": 10, "type": ` + fields + `}}`,
+			`{"kind": "delegate", "name": "bar.Bar", "layout": {"name": "Bar", "maxdepth": 10, "type": {"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}}, {"name": "B", "type": {"kind": "string"}}, {"name": "C", "type": {"kind": "maybe", "elem": {"kind": "int8"}}}]}}}`,
+			[]string{"breaking: Foo.C: field appended, data encoded before the change does not contain it"},
+		},
+		{
+			"inserted",
+			fields,
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}}, {"name": "C", "type": {"kind": "bool"}}, {"name": "B", "type": {"kind": "string"}}]}`,
+			[]string{"breaking: Foo.C: field inserted at position 2"},
+		},
+		{
+			"renamed",
+			fields,
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}}, {"name": "C", "type": {"kind": "bytes"}}]}`,
+			[]string{
+				"compatible: Foo.C: field renamed from B",
+				"compatible: Foo.C: type changed from string to bytes, which have the same layout",
+			},
+		},
+		{
+			"type changed",
+			`{"kind": "slice", "elem": {"kind": "int32"}}`,
+			`{"kind": "slice", "elem": {"kind": "int64"}}`,
+			[]string{"breaking: Foo[]: type changed from int32 to int64"},
+		},
+		{
+			"marshaler",
+			`{"kind": "marshaler", "name": "time.Month"}`,
+			`{"kind": "marshaler", "name": "big.Int"}`,
+			[]string{"breaking: Foo: type changed from marshaler time.Month to marshaler big.Int"},
+		},
+		{
+			"delegate",
+			fmt.Sprintf(delegate, "bar.Bar", 10, "int32"),
+			fmt.Sprintf(delegate, "bar.Bar", 5, "int64"),
+			[]string{
+				"breaking: Foo: maximum depth decreased from 10 to 5",
+				"breaking: Foo[]: type changed from int32 to int64",
+			},
+		},
+		{
+			"delegate renamed",
+			fmt.Sprintf(delegate, "bar.Bar", 10, "int32"),
+			fmt.Sprintf(delegate, "baz.Bar", 10, "int32"),
+			[]string{"compatible: Foo: type changed from delegate bar.Bar to delegate baz.Bar"},
+		},
+		{
+			"delegate without layout",
+			`{"kind": "delegate", "name": "bar.Bar"}`,
+			`{"kind": "delegate", "name": "baz.Bar"}`,
+			[]string{"breaking: Foo: type changed from delegate bar.Bar to delegate baz.Bar"},
+		},
+		{
+			"references",
+			`{"kind": "maybe", "elem": {"kind": "int8"}}`,
+			`{"kind": "maybe", "refs": true, "elem": {"kind": "int8"}}`,
+			[]string{"breaking: Foo: reference tracking enabled"},
+		},
+		{
+			"constraints",
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}, "constraints": [
+				{"name": "max", "args": "5"}, {"name": "min", "args": "1"}, {"name": "oneof", "args": "1 2"}, {"name": "notzero"}
+			]}]}`,
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "int32"}, "constraints": [
+				{"name": "max", "args": "10"}, {"name": "min", "args": "2"}, {"name": "oneof", "args": "3 2 1"}, {"name": "eq", "args": "2"}
+			]}]}`,
+			[]string{
+				"compatible: Foo.A: constraint max=5 relaxed to max=10",
+				"breaking: Foo.A: constraint min=1 changed to min=2",
+				"compatible: Foo.A: constraint oneof=1 2 relaxed to oneof=3 2 1",
+				"compatible: Foo.A: constraint notzero removed",
+				"breaking: Foo.A: constraint eq=2 added, data encoded before may not satisfy it",
+			},
+		},
+		{
+			"duration constraints",
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "duration"}, "constraints": [
+				{"name": "max", "args": "1h"}, {"name": "min", "args": "1000000000"}
+			]}]}`,
+			`{"kind": "struct", "fields": [{"name": "A", "type": {"kind": "duration"}, "constraints": [
+				{"name": "max", "args": "7200000000000"}, {"name": "min", "args": "2s"}
+			]}]}`,
+			[]string{
+				"compatible: Foo.A: constraint max=1h relaxed to max=7200000000000",
+				"breaking: Foo.A: constraint min=1000000000 changed to min=2s",
+			},
+		},
+		{
+			"union",
+			`{"kind": "union", "variants": [` + fmt.Sprintf(variant, 1, "A") + `, ` + fmt.Sprintf(variant, 2, "B") + `]}`,
+			`{"kind": "union", "variants": [` + fmt.Sprintf(variant, 1, "A") + `, ` + fmt.Sprintf(variant, 2, "B") + `, ` + fmt.Sprintf(variant, 3, "C") + `]}`,
+			[]string{"compatible: Foo: type C added"},
+		},
+		{
+			"union reordered",
+			`{"kind": "union", "variants": [` + fmt.Sprintf(variant, 1, "A") + `, ` + fmt.Sprintf(variant, 2, "B") + `]}`,
+			`{"kind": "union", "variants": [` + fmt.Sprintf(variant, 1, "B") + `, ` + fmt.Sprintf(variant, 2, "A") + `]}`,
+			[]string{
+				"breaking: Foo: type A moved from position 1 to 2",
+				"breaking: Foo: type B moved from position 2 to 1",
+			},
+		},
+		{
+			"recursive",
+			`{"kind": "ref", "name": "Foo"}`,
+			`{"kind": "ref", "name": "Foo"}`,
+			[]string{"breaking: Foo.Value: type changed from int8 to int16"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			old, new := fooSchema(t, tt.old), fooSchema(t, tt.new)
+			if tt.name == "recursive" {
+				old.Types[0].Definitions = map[string]*Type{"Foo": recursiveType(Int8)}
+				new.Types[0].Definitions = map[string]*Type{"Foo": recursiveType(Int16)}
+			}
+
+			var changes []string
+			for _, c := range Compare(old, new) {
+				changes = append(changes, c.String())
+			}
+
+			if !reflect.DeepEqual(changes, tt.expected) {
+				t.Errorf("expected changes %q, got: %q", tt.expected, changes)
+			}
+		})
+	}
+}
+
+// recursiveType returns a struct with a value of the given kind and a
+// slice of itself.
+func recursiveType(kind Kind) *Type {
+	return &Type{Kind: Struct, Fields: []Field{
+		{Name: "Value", Type: &Type{Kind: kind}},
+		{Name: "Children", Type: &Type{Kind: Slice, Elem: &Type{Kind: Ref, Name: "Foo"}}},
+	}}
+}
+
+func TestCompareTypes(t *testing.T) {
+	old := fooSchema(t, `{"kind": "bool"}`)
+	old.Types = append(old.Types, Named{Name: "Bar", Type: &Type{Kind: Bool}, MaxDepth: 10})
+	new := &Schema{Version: Version, Types: []Named{
+		{Name: "Bar", Type: &Type{Kind: Bool}, MaxDepth: 5},
+		{Name: "Baz", Type: &Type{Kind: Bool}, MaxDepth: 10},
+	}}
+
+	var changes []string
+	for _, c := range Compare(old, new) {
+		changes = append(changes, c.String())
+	}
+
+	expected := []string{
+		"breaking: Foo: type removed",
+		"breaking: Bar: maximum depth decreased from 10 to 5",
+		"compatible: Baz: type added",
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected changes %q, got: %q", expected, changes)
+	}
+
+	if !HasBreaking(Compare(old, new)) || HasBreaking(Compare(old, old)) {
+		t.Errorf("unexpected result of HasBreaking")
+	}
+}
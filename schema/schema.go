@@ -0,0 +1,161 @@
+// Package schema describes the binary layout of the types encoded by bindec
+// in a language-neutral way, so the encoded data can be read and written
+// without the Go code generated for the types.
+//
+// Schemas are serialized as JSON. Their format is versioned with Version,
+// which is only incremented when a change in the format would make existing
+// readers misinterpret a schema, such as a change in the meaning of a field
+// or a new kind of type. Adding optional fields does not change the version,
+// so readers must ignore the fields they don't know and reject the schemas
+// with a version they don't support. The format is documented in SCHEMA.md.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Version is the version of the schema format.
+const Version = 1
+
+// Schema describes the types of a package.
+type Schema struct {
+	// Version of the schema format.
+	Version int `json:"version"`
+	// Package is the import path of the package declaring the types.
+	Package string `json:"package"`
+	// Types are the described types, in the order they were generated.
+	Types []Named `json:"types"`
+}
+
+// Named is a named type of a package.
+type Named struct {
+	// Name of the type.
+	Name string `json:"name"`
+	// Type is the layout of the type. If the type contains itself, it's a
+	// reference to one of its definitions.
+	Type *Type `json:"type"`
+	// Definitions are the layouts of the types referenced by the types of
+	// kind Ref, by name. These are the types that contain themselves,
+	// directly or through other types.
+	Definitions map[string]*Type `json:"definitions,omitempty"`
+	// MaxDepth is the maximum depth of references to definitions decoded.
+	MaxDepth int `json:"maxdepth"`
+}
+
+// Kind is the kind of a type, which determines its layout.
+type Kind string
+
+// Kinds of types. Their layouts are described in SCHEMA.md.
+const (
+	Bool       Kind = "bool"
+	Int8       Kind = "int8"
+	Int16      Kind = "int16"
+	Int32      Kind = "int32"
+	Int64      Kind = "int64"
+	Int        Kind = "int"
+	Uint8      Kind = "uint8"
+	Uint16     Kind = "uint16"
+	Uint32     Kind = "uint32"
+	Uint64     Kind = "uint64"
+	Uint       Kind = "uint"
+	Uintptr    Kind = "uintptr"
+	Float32    Kind = "float32"
+	Float64    Kind = "float64"
+	Complex64  Kind = "complex64"
+	Complex128 Kind = "complex128"
+	String     Kind = "string"
+	Bytes      Kind = "bytes"
+	Duration   Kind = "duration"
+	Time       Kind = "time"
+	Array      Kind = "array"
+	Slice      Kind = "slice"
+	Map        Kind = "map"
+	Struct     Kind = "struct"
+	Maybe      Kind = "maybe"
+	Union      Kind = "union"
+	Marshaler  Kind = "marshaler"
+	Delegate   Kind = "delegate"
+	TypeParam  Kind = "typeparam"
+	Ref        Kind = "ref"
+)
+
+// Type is the layout of a type.
+type Type struct {
+	// Kind of the type.
+	Kind Kind `json:"kind"`
+	// Name is the name of the type in Go. It's only given for the types
+	// whose layout is not fully described by their own fields: delegates,
+	// marshalers and type parameters, as well as for references.
+	Name string `json:"name,omitempty"`
+	// Size is the number of bytes of all encoded values of the type, or 0
+	// if values are encoded with different sizes.
+	Size int `json:"size,omitempty"`
+	// Prefix is the number of bytes of the length that prefixes the
+	// encoded values of strings, bytes, slices, maps and marshalers.
+	Prefix int `json:"prefix,omitempty"`
+	// Len is the number of elements of arrays.
+	Len int64 `json:"len,omitempty"`
+	// Key is the type of the keys of maps.
+	Key *Type `json:"key,omitempty"`
+	// Elem is the type of the elements of arrays, slices and maps, and of
+	// the values of maybes.
+	Elem *Type `json:"elem,omitempty"`
+	// Fields are the fields of structs, in the order they are encoded.
+	Fields []Field `json:"fields,omitempty"`
+	// Variants are the types of the values of unions and type parameters.
+	Variants []Variant `json:"variants,omitempty"`
+	// Refs reports whether the values of maybes are tracked, so values
+	// pointed to more than once are only encoded the first time.
+	Refs bool `json:"refs,omitempty"`
+	// Layout is the type delegates are encoded as by their own methods,
+	// which is described just like the named types of the schema, with its
+	// own definitions and maximum depth. It's nil if the methods were not
+	// generated by bindec, in which case the layout is unknown.
+	Layout *Named `json:"layout,omitempty"`
+}
+
+// Field is a field of a struct.
+type Field struct {
+	// Name of the field. Fields promoted from flattened embedded structs
+	// are named after their path, such as "Base.ID".
+	Name string `json:"name"`
+	// Type of the field.
+	Type *Type `json:"type"`
+	// Constraints that the decoded values of the field must satisfy.
+	Constraints []Constraint `json:"constraints,omitempty"`
+}
+
+// Constraint is a constraint of a field, as declared in its struct tag.
+type Constraint struct {
+	// Name of the constraint, such as "maxlen".
+	Name string `json:"name"`
+	// Args are the arguments of the constraint, if any.
+	Args string `json:"args,omitempty"`
+}
+
+// Variant is one of the types a union or a type parameter can have.
+type Variant struct {
+	// Tag is the byte that identifies the type in unions, starting at 1.
+	// Type parameters have no tags, as their types are not encoded.
+	Tag int `json:"tag,omitempty"`
+	// Name of the type.
+	Name string `json:"name"`
+	// Type is the layout of the type.
+	Type *Type `json:"type"`
+}
+
+// Parse parses a schema serialized as JSON. It returns an error if the
+// version of the schema is not supported.
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %s", err)
+	}
+
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported schema version %d, expecting %d", s.Version, Version)
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(`{
+		"version": 1,
+		"package": "foo",
+		"future": true,
+		"types": [{"name": "Foo", "type": {"kind": "int8", "size": 1}, "maxdepth": 10}]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(s.Types) != 1 || s.Types[0].Type.Kind != Int8 || s.Types[0].Type.Size != 1 {
+		t.Errorf("unexpected schema: %+v", s)
+	}
+
+	testCases := []struct {
+		data string
+		err  string
+	}{
+		{`{"version": 2, "types": []}`, "unsupported schema version 2"},
+		{`{"types": []}`, "unsupported schema version 0"},
+		{`[]`, "invalid schema"},
+	}
+
+	for _, tt := range testCases {
+		_, err := Parse([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error %q, got: %v", tt.data, tt.err, err)
+		}
+	}
+}
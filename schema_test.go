@@ -0,0 +1,419 @@
+package bindec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/bindec/bench"
+	"github.com/erizocosmico/bindec/dynamic"
+	"github.com/erizocosmico/bindec/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	s, err := Schema(Options{
+		Types:           []string{"DurationTestType", "StructCyclic", "EmbeddedTestType"},
+		TrackReferences: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.Version != schema.Version || s.Package != "github.com/erizocosmico/bindec" || len(s.Types) != 3 {
+		t.Fatalf("unexpected schema: %+v", s)
+	}
+
+	int64Type := &schema.Type{Kind: schema.Int, Size: 8}
+	expected := []schema.Named{
+		{
+			Name: "DurationTestType",
+			Type: &schema.Type{Kind: schema.Struct, Size: 8, Fields: []schema.Field{
+				{
+					Name:        "Duration",
+					Type:        &schema.Type{Kind: schema.Duration, Size: 8},
+					Constraints: []schema.Constraint{{Name: "max", Args: "1h"}, {Name: "min", Args: "1s"}},
+				},
+			}},
+			MaxDepth: DefaultMaxDepth,
+		},
+		{
+			Name: "StructCyclic",
+			Type: &schema.Type{Kind: schema.Ref, Name: "StructCyclic"},
+			Definitions: map[string]*schema.Type{
+				"StructCyclic": {Kind: schema.Struct, Fields: []schema.Field{
+					{Name: "Value", Type: int64Type},
+					{Name: "Cycle", Type: &schema.Type{
+						Kind: schema.Maybe,
+						Elem: &schema.Type{Kind: schema.Ref, Name: "StructCyclic"},
+						Refs: true,
+					}},
+				}},
+			},
+			MaxDepth: DefaultMaxDepth,
+		},
+	}
+
+	for i, e := range expected {
+		if !reflect.DeepEqual(s.Types[i], e) {
+			t.Errorf("unexpected type %d:\n%s\nexpected:\n%s", i, toJSON(t, s.Types[i]), toJSON(t, e))
+		}
+	}
+
+	// Flattened embedded pointers are encoded like the struct they point to.
+	audit := s.Types[2].Type.Fields[2]
+	if audit.Name != "AuditTestType" || audit.Type.Kind != schema.Struct || len(audit.Type.Fields) != 2 {
+		t.Errorf("unexpected embedded field: %s", toJSON(t, audit))
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	parsed, err := schema.Parse(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(parsed, s) {
+		t.Errorf("schema changed after serializing it")
+	}
+}
+
+func TestSchemaDelegate(t *testing.T) {
+	s, err := Schema(Options{Types: []string{"DelegateTestType"}})
+	require.NoError(t, err)
+
+	fields := s.Types[0].Type.Fields
+	require.Len(t, fields, 4)
+
+	// The layout of marshalers is unknown.
+	require.Equal(t, &schema.Type{Kind: schema.Marshaler, Name: "url.URL", Prefix: 8}, fields[0].Type)
+
+	foo := fields[2].Type
+	require.Equal(t, schema.Delegate, foo.Kind)
+	require.Equal(t, "bench.Foo", foo.Name)
+	require.NotNil(t, foo.Layout)
+	require.Equal(t, "Foo", foo.Layout.Name)
+	require.Equal(t, DefaultMaxDepth, foo.Layout.MaxDepth)
+
+	var names []string
+	for _, f := range foo.Layout.Type.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"A", "B", "C", "D", "E", "F", "G"}, names)
+}
+
+func toJSON(t *testing.T, v interface{}) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return string(data)
+}
+
+func TestDynamicDecode(t *testing.T) {
+	s, err := Schema(Options{
+		Types: []string{"StructTestType", "TreeTestType", "GraphTestType", "UnionTestType", "TimeTestType", "DelegateTestType"},
+		TypeOptions: map[string]TypeOptions{
+			"GraphTestType": {TrackReferences: true},
+		},
+	})
+	require.NoError(t, err)
+
+	trueVal := true
+	shared := &GraphNodeTestType{Name: "shared"}
+	leaf := TreeTestType{4, nil, nil}
+	now := time.Date(2021, 3, 4, 5, 6, 7, 8, time.FixedZone("FOO", -7200))
+
+	testCases := []struct {
+		name     string
+		input    encoder
+		expected interface{}
+	}{
+		{
+			"StructTestType",
+			StructTestType{
+				Int8:          math.MinInt8,
+				Int16:         -2,
+				Int64:         math.MaxInt64,
+				Uint64:        math.MaxUint64,
+				String:        "cowabunga",
+				Float32:       1.5,
+				Bool:          true,
+				Pointer:       &trueVal,
+				Slice:         []int16{-5, 5},
+				Bytes:         []byte("ay"),
+				Array:         [4]int16{1, 2, 3, 4},
+				StructPointer: &Struct2{8, "baz"},
+				Ignored:       42,
+			},
+			[]interface{}{
+				int64(math.MinInt8), int64(-2), int64(0), int64(math.MaxInt64), int64(0),
+				uint64(0), uint64(0), uint64(0), uint64(0), uint64(math.MaxUint64), uint64(0),
+				"cowabunga", float64(1.5), float64(0), true,
+				[]interface{}{true}, nil,
+				[]interface{}{int64(-5), int64(5)},
+				[]byte("ay"),
+				[]interface{}{int64(1), int64(2), int64(3), int64(4)},
+				[]interface{}{int64(0), ""},
+				[]interface{}{int64(0), ""},
+				[]interface{}{[]interface{}{int64(8), "baz"}},
+			},
+		},
+		{
+			"TreeTestType",
+			TreeTestType{
+				Value:    1,
+				Children: []TreeTestType{leaf},
+				Index:    map[string]*TreeTestType{"leaf": &leaf},
+			},
+			[]interface{}{
+				int64(1),
+				[]interface{}{[]interface{}{int64(4), []interface{}{}, map[interface{}]interface{}{}}},
+				map[interface{}]interface{}{
+					"leaf": []interface{}{[]interface{}{int64(4), []interface{}{}, map[interface{}]interface{}{}}},
+				},
+			},
+		},
+		{
+			"GraphTestType",
+			GraphTestType{A: shared, B: shared, Nodes: []*GraphNodeTestType{shared, nil}},
+			[]interface{}{
+				[]interface{}{[]interface{}{"shared", nil}},
+				"ref 0",
+				[]interface{}{"ref 0", nil},
+			},
+		},
+		{
+			"UnionTestType",
+			UnionTestType{
+				Event:  CreatedTestType{2},
+				Events: []EventTestType{nil, &DeletedTestType{3, "spam"}},
+				Expr:   LiteralTestType(1),
+			},
+			[]interface{}{
+				[]interface{}{"CreatedTestType", []interface{}{int64(2)}},
+				[]interface{}{nil, []interface{}{"*DeletedTestType", []interface{}{[]interface{}{int64(3), "spam"}}}},
+				nil,
+				[]interface{}{"LiteralTestType", int64(1)},
+			},
+		},
+		{
+			"TimeTestType",
+			TimeTestType{Time: now, NilTime: &now, Duration: -5 * time.Minute},
+			[]interface{}{
+				now.Format(time.RFC3339Nano),
+				nil,
+				[]interface{}{now.Format(time.RFC3339Nano)},
+				-5 * time.Minute,
+			},
+		},
+		{
+			"DelegateTestType",
+			DelegateTestType{
+				URL:       url.URL{Scheme: "http", Host: "example.com"},
+				Foo:       bench.Foo{A: 1, B: "b", E: []int{2}, G: true},
+				Marshaler: MarshalerTestType{3},
+			},
+			[]interface{}{
+				[]byte("http://example.com"),
+				nil,
+				[]interface{}{
+					int64(1), "b", []byte(nil),
+					[]interface{}{uint64(0), ""},
+					[]interface{}{int64(2)},
+					[]interface{}{int64(0), int64(0)},
+					true,
+				},
+				[]byte("3"),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			output, err := tt.input.EncodeBinary()
+			require.NoError(err)
+
+			v, err := dynamic.Decode(s, tt.name, output)
+			require.NoError(err)
+			require.Equal(len(output), v.Size)
+			require.Equal(tt.expected, plainValue(t, v))
+
+			// Truncated data is decoded until the end of the data.
+			v, err = dynamic.Decode(s, tt.name, output[:len(output)-1])
+			require.Error(err)
+			require.NotNil(v)
+			require.IsType(&dynamic.Error{}, err)
+		})
+	}
+}
+
+// plainValue returns the values of a decoded value without their positions,
+// checking that the values contained in it are contiguous.
+func plainValue(t *testing.T, v *dynamic.Value) interface{} {
+	offset := v.Offset
+	check := func(child *dynamic.Value) interface{} {
+		if child.Offset < offset {
+			t.Errorf("value at offset %d overlaps the previous one, which ends at %d", child.Offset, offset)
+		}
+		offset = child.Offset + child.Size
+		return plainValue(t, child)
+	}
+
+	switch v.Type.Kind {
+	case schema.Struct, schema.Array, schema.Slice:
+		var result = []interface{}{}
+		for _, f := range v.Fields {
+			result = append(result, check(f.Value))
+		}
+		for _, e := range v.Elems {
+			result = append(result, check(e))
+		}
+		return result
+	case schema.Map:
+		var result = make(map[interface{}]interface{})
+		for i, k := range v.Keys {
+			key := check(k)
+			result[key] = check(v.Elems[i])
+		}
+		return result
+	case schema.Maybe:
+		if v.Ref {
+			return fmt.Sprintf("ref %d", v.ID)
+		} else if v.Null() {
+			return nil
+		}
+		return []interface{}{check(v.Elems[0])}
+	case schema.Union:
+		if v.Null() {
+			return nil
+		}
+		return []interface{}{v.Variant, check(v.Elems[0])}
+	case schema.Delegate:
+		return check(v.Elems[0])
+	default:
+		if tm, ok := v.Data.(time.Time); ok {
+			return tm.Format(time.RFC3339Nano)
+		}
+		return v.Data
+	}
+}
+
+func TestDynamicJSON(t *testing.T) {
+	s, err := Schema(Options{
+		Types: []string{"StructTestType", "TreeTestType", "UnionTestType", "TimeTestType", "ComplexTestType", "GraphTestType", "DelegateTestType"},
+		TypeOptions: map[string]TypeOptions{
+			"GraphTestType": {TrackReferences: true},
+		},
+	})
+	require.NoError(t, err)
+
+	trueVal := true
+	leaf := TreeTestType{4, nil, nil}
+	now := time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC)
+	c128 := complex(math.Inf(1), math.NaN())
+
+	testCases := []struct {
+		name  string
+		input encoderDecoder
+	}{
+		{
+			"StructTestType",
+			&StructTestType{
+				Int8:          math.MinInt8,
+				Int64:         math.MaxInt64,
+				Uint64:        math.MaxUint64,
+				String:        "cowabunga",
+				Float32:       1.5,
+				Pointer:       &trueVal,
+				Slice:         []int16{-5, 5},
+				Bytes:         []byte("ay"),
+				Array:         [4]int16{1, 2, 3, 4},
+				StructPointer: &Struct2{8, "baz"},
+			},
+		},
+		{
+			"TreeTestType",
+			&TreeTestType{
+				Value:    1,
+				Children: []TreeTestType{leaf},
+				Index:    map[string]*TreeTestType{"leaf": &leaf, "nil": nil},
+			},
+		},
+		{
+			"UnionTestType",
+			&UnionTestType{
+				Event:  CreatedTestType{2},
+				Events: []EventTestType{nil, &DeletedTestType{3, "spam"}},
+				Expr:   BinaryTestType{Op: "+", Left: LiteralTestType(1), Right: LiteralTestType(2)},
+			},
+		},
+		{
+			"TimeTestType",
+			&TimeTestType{Time: now, TimePointer: &now, Duration: -5 * time.Minute},
+		},
+		{
+			"ComplexTestType",
+			&ComplexTestType{
+				C64:      complex(1.5, -2),
+				C128:     c128,
+				Samples:  []complex64{1i},
+				Named:    []IQSampleTestType{2},
+				Optional: &c128,
+				Bounded:  []complex64{1, 2},
+			},
+		},
+		{
+			"DelegateTestType",
+			&DelegateTestType{
+				URL: url.URL{Scheme: "http", Host: "example.com"},
+				Int: big.NewInt(5),
+				Foo: bench.Foo{A: 1, B: "b", C: []byte("c"), E: []int{2}, G: true},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			data, err := tt.input.EncodeBinary()
+			require.NoError(err)
+
+			doc, err := dynamic.BinaryToJSON(s, tt.name, data)
+			require.NoError(err)
+			require.True(json.Valid(doc), string(doc))
+
+			result, err := dynamic.JSONToBinary(s, tt.name, doc)
+			require.NoError(err)
+			require.Equal(data, result, string(doc))
+		})
+	}
+
+	// References are converted to copies of the values they reference.
+	shared := &GraphNodeTestType{Name: "shared"}
+	data, err := (&GraphTestType{A: shared, B: shared}).EncodeBinary()
+	require.NoError(t, err)
+
+	doc, err := dynamic.BinaryToJSON(s, "GraphTestType", data)
+	require.NoError(t, err)
+
+	data, err = dynamic.JSONToBinary(s, "GraphTestType", doc)
+	require.NoError(t, err)
+
+	var graph GraphTestType
+	require.NoError(t, graph.DecodeBinaryFromBytes(data))
+	require.Equal(t, "shared", graph.A.Name)
+	require.Equal(t, "shared", graph.B.Name)
+	require.False(t, graph.A == graph.B, "expected copies of the shared value")
+}
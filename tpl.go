@@ -1,37 +1,88 @@
 package bindec
 
-const methodsTpl = `
-// EncodeBinary returns a binary-encoded representation of the type.
-func (%[1]s %[2]s) EncodeBinary() ([]byte, error) {
+const encodeBytesTpl = `
+// %[3]s returns a binary-encoded representation of the type.
+func (%[1]s %[2]s) %[3]s() ([]byte, error) {
 	var writer = bytes.NewBuffer(nil)
-	if err := %[1]s.WriteBinary(writer); err != nil {
+	if err := %[1]s.%[4]s(writer); err != nil {
 		return nil, err
 	}
 	return writer.Bytes(), nil
 }
+`
 
-// WriteBinary writes the binary-encoded representation of the type to the
+const encodeBytesInlineTpl = `
+// %[3]s returns a binary-encoded representation of the type.
+func (%[1]s %[2]s) %[3]s() ([]byte, error) {
+	var buf = bytes.NewBuffer(nil)
+	err := func(writer io.Writer) error {
+		%[4]s
+		return nil
+	}(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+`
+
+const writeTpl = `
+// %[3]s writes the binary-encoded representation of the type to the
 // given writer.
-func (%[1]s %[2]s) WriteBinary(writer io.Writer) error {
-	%[3]s
+func (%[1]s %[2]s) %[3]s(writer io.Writer) error {
+	%[4]s
 	return nil
 }
+`
 
-// DecodeBinaryFromBytes fills the type with the given binary-encoded
+const decodeBytesTpl = `
+// %[3]s fills the type with the given binary-encoded
 // representation of the type.
-func (%[1]s *%[2]s) DecodeBinaryFromBytes(data []byte) error {
+func (%[1]s *%[2]s) %[3]s(data []byte) error {
 	var reader = bytes.NewReader(data)
-	return %[1]s.DecodeBinary(reader)
+	return %[1]s.%[4]s(reader)
+}
+`
+
+const decodeBytesInlineTpl = `
+// %[3]s fills the type with the given binary-encoded
+// representation of the type.
+func (%[1]s *%[2]s) %[3]s(data []byte) error {
+	var reader io.Reader = bytes.NewReader(data)
+	%[4]s
+	return nil
 }
+`
 
-// DecodeBinary reads the binary representation of the type from the given
+const decodeTpl = `
+// %[3]s reads the binary representation of the type from the given
 // reader and fulls the type with it.
-func (%[1]s *%[2]s) DecodeBinary(reader io.Reader) error {
+func (%[1]s *%[2]s) %[3]s(reader io.Reader) error {
 	%[4]s
 	return nil
 }
 `
 
+const encodeFunctionTpl = `
+// %[4]s writes the binary-encoded representation of the given value
+// to the writer.
+func %[4]s(writer io.Writer, v *%[2]s) error {
+	%[1]s := *v
+	%[3]s
+	return nil
+}
+`
+
+const decodeFunctionTpl = `
+// %[4]s reads the binary representation of the type from the given
+// reader and fills the given value with it.
+func %[4]s(reader io.Reader, v *%[2]s) error {
+	%[1]s := v
+	%[3]s
+	return nil
+}
+`
+
 const fileTpl = `
 // WARNING! This is code generated by bindec, do not modify manually.
 
@@ -80,9 +131,9 @@ const (
 	writeString = `
 {
 	v := %s
-	len := len(v)
-	ux := uint64(len) << 1
-	if len < 0 {
+	n := len(v)
+	ux := uint64(n) << 1
+	if n < 0 {
 		ux = ^ux
 	}
 	sz := make([]byte, 8)
@@ -488,6 +539,168 @@ const (
 		return err
 	}
 }
+`
+
+	readComplex64 = `
+{
+	var bs = make([]byte, 8)
+	if _, err := io.ReadFull(reader, bs); err != nil {
+		return err
+	}
+	re := math.Float32frombits(binary.LittleEndian.Uint32(bs))
+	im := math.Float32frombits(binary.LittleEndian.Uint32(bs[4:]))
+	%[3]s%[2]s = %[1]s(complex(re, im))
+
+	%[4]s
+	%[5]s
+}
+`
+
+	writeComplex64 = `
+{
+	c := complex64(%s)
+	bs := make([]byte, 8)
+	binary.LittleEndian.PutUint32(bs, math.Float32bits(real(c)))
+	binary.LittleEndian.PutUint32(bs[4:], math.Float32bits(imag(c)))
+	_, err := writer.Write(bs)
+	if err != nil {
+		return err
+	}
+}
+`
+
+	readComplex128 = `
+{
+	var bs = make([]byte, 16)
+	if _, err := io.ReadFull(reader, bs); err != nil {
+		return err
+	}
+	re := math.Float64frombits(binary.LittleEndian.Uint64(bs))
+	im := math.Float64frombits(binary.LittleEndian.Uint64(bs[8:]))
+	%[3]s%[2]s = %[1]s(complex(re, im))
+
+	%[4]s
+	%[5]s
+}
+`
+
+	writeComplex128 = `
+{
+	c := complex128(%s)
+	bs := make([]byte, 16)
+	binary.LittleEndian.PutUint64(bs, math.Float64bits(real(c)))
+	binary.LittleEndian.PutUint64(bs[8:], math.Float64bits(imag(c)))
+	_, err := writer.Write(bs)
+	if err != nil {
+		return err
+	}
+}
+`
+
+	readComplex64Slice = `
+{
+	var bs = make([]byte, 8)
+	if _, err := io.ReadFull(reader, bs); err != nil {
+		return err
+	}
+
+	ux := binary.LittleEndian.Uint64(bs)
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+
+	sz := int(x)
+	if sz < 0 || sz > math.MaxInt32 {
+		return fmt.Errorf("invalid slice length %%d", sz)
+	}
+
+	%[5]s
+
+	data := make([]byte, sz*8)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return err
+	}
+
+	%[1]s%[2]s = make(%[3]s, sz)
+	for i := 0; i < sz; i++ {
+		re := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8:]))
+		im := math.Float32frombits(binary.LittleEndian.Uint32(data[i*8+4:]))
+		(%[1]s%[2]s)[i] = %[4]s(complex(re, im))
+	}
+
+	%[6]s
+}
+`
+
+	writeComplex64Slice = `
+{
+	v := %s
+	n := len(v)
+	bs := make([]byte, 8+n*8)
+	binary.LittleEndian.PutUint64(bs, uint64(n)<<1)
+	for i, c := range v {
+		binary.LittleEndian.PutUint32(bs[8+i*8:], math.Float32bits(real(c)))
+		binary.LittleEndian.PutUint32(bs[12+i*8:], math.Float32bits(imag(c)))
+	}
+
+	if _, err := writer.Write(bs); err != nil {
+		return err
+	}
+}
+`
+
+	readComplex128Slice = `
+{
+	var bs = make([]byte, 8)
+	if _, err := io.ReadFull(reader, bs); err != nil {
+		return err
+	}
+
+	ux := binary.LittleEndian.Uint64(bs)
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+
+	sz := int(x)
+	if sz < 0 || sz > math.MaxInt32 {
+		return fmt.Errorf("invalid slice length %%d", sz)
+	}
+
+	%[5]s
+
+	data := make([]byte, sz*16)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return err
+	}
+
+	%[1]s%[2]s = make(%[3]s, sz)
+	for i := 0; i < sz; i++ {
+		re := math.Float64frombits(binary.LittleEndian.Uint64(data[i*16:]))
+		im := math.Float64frombits(binary.LittleEndian.Uint64(data[i*16+8:]))
+		(%[1]s%[2]s)[i] = %[4]s(complex(re, im))
+	}
+
+	%[6]s
+}
+`
+
+	writeComplex128Slice = `
+{
+	v := %s
+	n := len(v)
+	bs := make([]byte, 8+n*16)
+	binary.LittleEndian.PutUint64(bs, uint64(n)<<1)
+	for i, c := range v {
+		binary.LittleEndian.PutUint64(bs[8+i*16:], math.Float64bits(real(c)))
+		binary.LittleEndian.PutUint64(bs[16+i*16:], math.Float64bits(imag(c)))
+	}
+
+	if _, err := writer.Write(bs); err != nil {
+		return err
+	}
+}
 `
 
 	readBytes = `
@@ -520,9 +733,9 @@ const (
 	writeBytes = `
 {
 	v := %s
-	len := len(v)
-	ux := uint64(len) << 1
-	if len < 0 {
+	n := len(v)
+	ux := uint64(n) << 1
+	if n < 0 {
 		ux = ^ux
 	}
 	sz := make([]byte, 8)
@@ -536,5 +749,395 @@ const (
 		return err
 	}
 }
+`
+
+	readTime = `
+{
+	var bs = make([]byte, 13)
+	if _, err := io.ReadFull(reader, bs); err != nil {
+		return err
+	}
+
+	ux := binary.LittleEndian.Uint64(bs)
+	sec := int64(ux >> 1)
+	if ux&1 != 0 {
+		sec = ^sec
+	}
+	nsec := int64(binary.LittleEndian.Uint32(bs[8:]))
+
+	tm := time.Unix(sec, nsec)
+	switch bs[12] {
+	case 0:
+		tm = tm.UTC()
+	case 1:
+		tm = tm.Local()
+	case 2:
+		var bs = make([]byte, 12)
+		if _, err := io.ReadFull(reader, bs); err != nil {
+			return err
+		}
+
+		ux := binary.LittleEndian.Uint32(bs)
+		offset := int32(ux >> 1)
+		if ux&1 != 0 {
+			offset = ^offset
+		}
+
+		ux2 := binary.LittleEndian.Uint64(bs[4:])
+		x := int64(ux2 >> 1)
+		if ux2&1 != 0 {
+			x = ^x
+		}
+
+		if x < 0 || x > math.MaxInt32 {
+			return fmt.Errorf("invalid time zone name length %%d", x)
+		}
+
+		// The length is not trusted to allocate the bytes to read.
+		b, err := io.ReadAll(io.LimitReader(reader, x))
+		if err != nil {
+			return err
+		}
+		if int64(len(b)) < x {
+			return io.ErrUnexpectedEOF
+		}
+
+		name := string(b)
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			loc = time.FixedZone(name, int(offset))
+		} else if _, off := tm.In(loc).Zone(); off != int(offset) {
+			loc = time.FixedZone(name, int(offset))
+		}
+		tm = tm.In(loc)
+	default:
+		return fmt.Errorf("invalid time zone kind: %%d", bs[12])
+	}
+
+	%[3]s%[2]s = %[1]s(tm)
+
+	%[4]s
+	%[5]s
+}
+`
+
+	writeTime = `
+{
+	x := %s
+	sec := x.Unix()
+	ux := uint64(sec) << 1
+	if sec < 0 {
+		ux = ^ux
+	}
+	bs := make([]byte, 13)
+	binary.LittleEndian.PutUint64(bs, ux)
+	binary.LittleEndian.PutUint32(bs[8:], uint32(x.Nanosecond()))
+
+	switch x.Location() {
+	case time.UTC:
+		bs[12] = 0
+	case time.Local:
+		bs[12] = 1
+	default:
+		bs[12] = 2
+	}
+
+	if _, err := writer.Write(bs); err != nil {
+		return err
+	}
+
+	if bs[12] == 2 {
+		_, offset := x.Zone()
+		name := x.Location().String()
+		uoffset := uint32(int32(offset)) << 1
+		if offset < 0 {
+			uoffset = ^uoffset
+		}
+		bs := make([]byte, 12)
+		binary.LittleEndian.PutUint32(bs, uoffset)
+		binary.LittleEndian.PutUint64(bs[4:], uint64(len(name))<<1)
+		if _, err := writer.Write(bs); err != nil {
+			return err
+		}
+
+		if _, err := writer.Write([]byte(name)); err != nil {
+			return err
+		}
+	}
+}
+`
+
+	readMarshaler = `
+{
+	var bs = make([]byte, 8)
+	if _, err := io.ReadFull(reader, bs); err != nil {
+		return err
+	}
+
+	ux := binary.LittleEndian.Uint64(bs)
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+
+	if x < 0 || x > math.MaxInt32 {
+		return fmt.Errorf("invalid length %%d", x)
+	}
+
+	sz := int(x)
+	%[4]s
+
+	// The length is not trusted to allocate the bytes to read.
+	b, err := io.ReadAll(io.LimitReader(reader, x))
+	if err != nil {
+		return err
+	}
+	if len(b) < sz {
+		return io.ErrUnexpectedEOF
+	}
+
+	if err := (%[3]s%[2]s).%[6]s(b); err != nil {
+		return err
+	}
+
+	%[5]s
+}
+`
+
+	writeMarshaler = `
+{
+	v, err := %[1]s.%[2]s()
+	if err != nil {
+		return err
+	}
+
+	n := len(v)
+	ux := uint64(n) << 1
+	if n < 0 {
+		ux = ^ux
+	}
+	sz := make([]byte, 8)
+	binary.LittleEndian.PutUint64(sz, ux)
+	if _, err := writer.Write(sz); err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(v); err != nil {
+		return err
+	}
+}
+`
+
+	readDelegate = `
+{
+	if err := (%[2]s%[1]s).DecodeBinary(reader); err != nil {
+		return err
+	}
+
+	%[3]s
+	%[4]s
+}
+`
+
+	writeDelegate = `
+{
+	if err := %s.WriteBinary(writer); err != nil {
+		return err
+	}
+}
+`
+
+	readRecursive = `
+{
+	if err := decode%[2]s(reader, %[5]s%[1]s, depth+1); err != nil {
+		return err
+	}
+
+	%[3]s
+	%[4]s
+}
+`
+
+	writeRecursive = `
+{
+	if err := encode%[2]s(writer, %[3]s%[1]s); err != nil {
+		return err
+	}
+}
+`
+
+	readRef = `
+{
+	var v = make([]byte, 1)
+	if _, err := io.ReadFull(reader, v); %[7]s {
+		return err
+	}
+
+	switch v[0] {
+	case 0:
+		%[2]s = nil
+	case 1:
+		%[1]s := new(%[3]s)
+		*refs = append(*refs, %[1]s)
+		%[4]s
+		%[2]s = %[1]s
+		%[5]s
+	case 2:
+		var bs = make([]byte, 8)
+		if _, err := io.ReadFull(reader, bs); err != nil {
+			return err
+		}
+
+		id := binary.LittleEndian.Uint64(bs)
+		if id >= uint64(len(*refs)) {
+			return fmt.Errorf("invalid reference: %%d", id)
+		}
+
+		ref, ok := (*refs)[id].(*%[3]s)
+		if !ok {
+			return fmt.Errorf("reference %%d is not a *%[3]s", id)
+		}
+		%[6]s
+		%[2]s = ref
+		%[5]s
+	default:
+		return fmt.Errorf("invalid reference kind: %%d", v[0])
+	}
+}
+`
+
+	writeRef = `
+{
+	if x := %[1]s; x == nil {
+		if _, err := writer.Write([]byte{0}); err != nil {
+			return err
+		}
+	} else if id, ok := refs[x]; ok {
+		bs := make([]byte, 9)
+		bs[0] = 2
+		binary.LittleEndian.PutUint64(bs[1:], id)
+		if _, err := writer.Write(bs); err != nil {
+			return err
+		}
+	} else {
+		refs[x] = uint64(len(refs))
+		if _, err := writer.Write([]byte{1}); err != nil {
+			return err
+		}
+
+		%[2]s
+	}
+}
+`
+
+	readUnion = `
+{
+	var v = make([]byte, 1)
+	if _, err := io.ReadFull(reader, v); err != nil {
+		return err
+	}
+
+	switch v[0] {
+	case 0:
+		%[1]s = nil
+	%[2]s
+	default:
+		return fmt.Errorf("invalid type for union %[3]s: %%d", v[0])
+	}
+
+	%[4]s
+	%[5]s
+}
+`
+
+	unionDecoderCase = `
+	case %[1]d:
+		var %[2]s %[3]s
+		%[4]s
+		%[5]s = %[2]s
+`
+
+	writeUnion = `
+{
+	switch u := %[1]s.(type) {
+	case nil:
+		if _, err := writer.Write([]byte{0}); err != nil {
+			return err
+		}
+	%[2]s
+	default:
+		return fmt.Errorf("type %%T is not part of the union", u)
+	}
+}
+`
+
+	unionEncoderCase = `
+	case %[1]s:
+		if _, err := writer.Write([]byte{%[2]d}); err != nil {
+			return err
+		}
+
+		%[3]s
+`
+
+	readTypeParam = `
+{
+	switch p := any(&%[1]s).(type) {
+	%[2]s
+	default:
+		return fmt.Errorf("type %%T of type parameter %[3]s can not be decoded", %[1]s)
+	}
+
+	%[4]s
+	%[5]s
+}
+`
+
+	readTypeParamDelegate = `
+{
+	d, ok := any(&%[1]s).(interface{ DecodeBinary(io.Reader) error })
+	if !ok {
+		return fmt.Errorf("type %%T of type parameter %[2]s can not be decoded", %[1]s)
+	}
+
+	if err := d.DecodeBinary(reader); err != nil {
+		return err
+	}
+
+	%[3]s
+	%[4]s
+}
+`
+
+	writeTypeParam = `
+{
+	switch v := any(%[1]s).(type) {
+	%[2]s
+	default:
+		return fmt.Errorf("type %%T of type parameter %[3]s can not be encoded", v)
+	}
+}
 `
 )
+
+const encodeHelperTpl = `
+// encode%[1]s writes the binary-encoded representation of the recursive
+// type %[3]s to the given writer.
+func encode%[1]s%[5]s(writer io.Writer, %[6]s%[2]s %[3]s) error {
+	%[4]s
+	return nil
+}
+`
+
+const decodeHelperTpl = `
+// decode%[1]s reads the binary representation of the recursive type
+// %[3]s from the given reader at the given depth.
+func decode%[1]s%[6]s(reader io.Reader, %[7]s%[2]s *%[3]s, depth int) error {
+	if depth > %[5]d {
+		return fmt.Errorf("maximum decoding depth of %%d exceeded", %[5]d)
+	}
+
+	%[4]s
+	return nil
+}
+`
@@ -2,12 +2,18 @@ package bindec
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/token"
 	"go/types"
+	"math"
+	"path"
 	"reflect"
 	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Type can generate decoders and encoders for a given type.
@@ -56,6 +62,10 @@ const (
 	Float32 = types.Float32
 	// Float64 type.
 	Float64 = types.Float64
+	// Complex64 type.
+	Complex64 = types.Complex64
+	// Complex128 type.
+	Complex128 = types.Complex128
 )
 
 // Basic type.
@@ -97,6 +107,10 @@ func (t Basic) Encoder(recv string) string {
 		return fmt.Sprintf(writeFloat32, recv)
 	case types.Float64:
 		return fmt.Sprintf(writeFloat64, recv)
+	case types.Complex64:
+		return fmt.Sprintf(writeComplex64, recv)
+	case types.Complex128:
+		return fmt.Sprintf(writeComplex128, recv)
 	default:
 		return ""
 	}
@@ -137,6 +151,10 @@ func (t Basic) Decoder(recv string, root bool, constraints ...Constraint) string
 		return fmt.Sprintf(readFloat32, t.TypeName, recv, prefix, bcs, acs)
 	case types.Float64:
 		return fmt.Sprintf(readFloat64, t.TypeName, recv, prefix, bcs, acs)
+	case types.Complex64:
+		return fmt.Sprintf(readComplex64, t.TypeName, recv, prefix, bcs, acs)
+	case types.Complex128:
+		return fmt.Sprintf(readComplex128, t.TypeName, recv, prefix, bcs, acs)
 	default:
 		return ""
 	}
@@ -146,10 +164,17 @@ func (t Basic) Decoder(recv string, root bool, constraints ...Constraint) string
 type Maybe struct {
 	ElemType string
 	Elem     Type
+	// Refs reports whether the values pointed to are tracked, so values
+	// pointed to more than once are only encoded the first time.
+	Refs bool
 }
 
 // Encoder implements the Type interface.
 func (t Maybe) Encoder(recv string) string {
+	if t.Refs {
+		return fmt.Sprintf(writeRef, recv, t.Elem.Encoder(fmt.Sprintf("(*%s)", recv)))
+	}
+
 	return fmt.Sprintf(`
 {
 	if x := %s; x == nil {
@@ -169,12 +194,43 @@ func (t Maybe) Encoder(recv string) string {
 
 // Decoder implements the Type interface.
 func (t Maybe) Decoder(recv string, root bool, constraints ...Constraint) string {
+	return t.decoder(recv, root, false, constraints...)
+}
+
+// decoder returns the code to decode the maybe. If eof is true, data ending
+// before it is decoded as nil.
+func (t Maybe) decoder(recv string, root, eof bool, constraints ...Constraint) string {
 	tmpIdent := tmpIdent(recv)
-	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	readErr := "err != nil"
+	if eof {
+		readErr = "err != nil && err != io.EOF"
+	}
+
+	// Lengths are checked while reading the value pointed to, and the rest
+	// of constraints once the pointer has been set.
+	beforecs, aftercs := splitConstraints(constraints)
+	if t.Refs {
+		var refcs string
+		if len(beforecs) > 0 {
+			refcs = fmt.Sprintf("{\n\tsz := len(*ref)\n%s}", constraintsToCode(beforecs, "ref"))
+		}
+
+		return fmt.Sprintf(
+			readRef,
+			tmpIdent,
+			recvPrefix(root)+recv,
+			t.ElemType,
+			t.Elem.Decoder(tmpIdent, true, beforecs...),
+			constraintsToCode(aftercs, recvPrefix(root)+recv),
+			refcs,
+			readErr,
+		)
+	}
+
 	return fmt.Sprintf(`
 {
 	var v = make([]byte, 1)
-	if _, err := io.ReadFull(reader, v); err != nil {
+	if _, err := io.ReadFull(reader, v); %[6]s {
 		return err
 	}
 
@@ -184,15 +240,16 @@ func (t Maybe) Decoder(recv string, root bool, constraints ...Constraint) string
 		var %[1]s %[3]s
 		%[4]s
 		%[2]s = &%[1]s
+		%[5]s
 	}
 }
 `,
 		tmpIdent,
-		recv,
+		recvPrefix(root)+recv,
 		t.ElemType,
-		t.Elem.Decoder(tmpIdent, false),
-		beforecs,
-		aftercs,
+		t.Elem.Decoder(tmpIdent, false, beforecs...),
+		constraintsToCode(aftercs, recvPrefix(root)+recv),
+		readErr,
 	)
 }
 
@@ -204,11 +261,15 @@ type Slice struct {
 
 // Encoder implements the Type interface.
 func (t Slice) Encoder(recv string) string {
+	if tpl, ok := bulkSliceEncoders[bulkKind(t.Elem)]; ok {
+		return fmt.Sprintf(tpl, recv)
+	}
+
 	return fmt.Sprintf(`
 {
-	len := len(%s)
-	ux := uint64(len) << 1
-	if len < 0 {
+	n := len(%s)
+	ux := uint64(n) << 1
+	if n < 0 {
 		ux = ^ux
 	}
 	bs := make([]byte, 8)
@@ -218,7 +279,7 @@ func (t Slice) Encoder(recv string) string {
 		return err
 	}
 	
-	for i := 0; i < len; i++ %s
+	for i := 0; i < n; i++ %s
 }
 `, recv, strings.TrimSpace(t.Elem.Encoder(recv+"[i]")))
 }
@@ -226,6 +287,18 @@ func (t Slice) Encoder(recv string) string {
 // Decoder implements the Type interface.
 func (t Slice) Decoder(recv string, root bool, constraints ...Constraint) string {
 	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	if tpl, ok := bulkSliceDecoders[bulkKind(t.Elem)]; ok {
+		return fmt.Sprintf(
+			tpl,
+			recvPrefix(root),
+			recv,
+			t.TypeName,
+			t.Elem.(Basic).TypeName,
+			beforecs,
+			aftercs,
+		)
+	}
+
 	return fmt.Sprintf(`
 {
 	var bs = make([]byte, 8)
@@ -262,6 +335,29 @@ func (t Slice) Decoder(recv string, root bool, constraints ...Constraint) string
 	)
 }
 
+// bulkSliceEncoders and bulkSliceDecoders are the templates for slices of
+// fixed size elements that are written and read all at once, instead of
+// element by element.
+var (
+	bulkSliceEncoders = map[BasicKind]string{
+		Complex64:  writeComplex64Slice,
+		Complex128: writeComplex128Slice,
+	}
+	bulkSliceDecoders = map[BasicKind]string{
+		Complex64:  readComplex64Slice,
+		Complex128: readComplex128Slice,
+	}
+)
+
+// bulkKind returns the kind of the given type if it's a basic type or
+// types.Invalid otherwise.
+func bulkKind(t Type) BasicKind {
+	if b, ok := t.(Basic); ok {
+		return b.Kind
+	}
+	return types.Invalid
+}
+
 // Array type of fixed size.
 type Array struct {
 	Len  int64
@@ -307,9 +403,9 @@ type Map struct {
 func (t Map) Encoder(recv string) string {
 	return fmt.Sprintf(`
 {
-	len := len(%[1]s)
-	ux := uint64(len) << 1
-	if len < 0 {
+	n := len(%[1]s)
+	ux := uint64(n) << 1
+	if n < 0 {
 		ux = ^ux
 	}
 	bs := make([]byte, 8)
@@ -382,6 +478,10 @@ func (t Struct) Encoder(recv string) string {
 	var buf bytes.Buffer
 	buf.WriteString("{\n")
 	for _, f := range t.Fields {
+		if f.Accessors != nil {
+			buf.WriteString(f.Type.Encoder(recv + "." + f.Accessors.Getter + "()"))
+			continue
+		}
 		buf.WriteString(f.Type.Encoder(recv + "." + f.Name))
 	}
 	buf.WriteString("}\n")
@@ -390,10 +490,53 @@ func (t Struct) Encoder(recv string) string {
 
 // Decoder implements the Type interface.
 func (t Struct) Decoder(recv string, root bool, constraints ...Constraint) string {
+	return t.decoder(recv, len(t.Fields), constraints...)
+}
+
+// rootDecoder returns the code to decode the struct as the generated type.
+// Data encoded before maybe fields were appended to it ends before them, so
+// they are decoded as nil if there is nothing left. The first field is never
+// missing, so empty data still fails with io.EOF.
+func (t Struct) rootDecoder(recv string) string {
+	optional := len(t.Fields)
+	for optional > 1 {
+		if _, ok := t.Fields[optional-1].Type.(Maybe); !ok {
+			break
+		}
+		optional--
+	}
+	return t.decoder(recv, optional)
+}
+
+// decoder returns the code to decode the struct, where the fields from the
+// given index on are maybes that may be missing at the end of the data.
+func (t Struct) decoder(recv string, optional int, constraints ...Constraint) string {
+	fieldDecoder := func(i int, recv string) string {
+		f := t.Fields[i]
+		if i >= optional {
+			return f.Type.(Maybe).decoder(recv, false, true, f.Constraints...)
+		}
+		return f.Type.Decoder(recv, false, f.Constraints...)
+	}
+
 	var buf bytes.Buffer
 	buf.WriteString("{\n")
-	for _, f := range t.Fields {
-		buf.WriteString(f.Type.Decoder(recv+"."+f.Name, false, f.Constraints...))
+	for i, f := range t.Fields {
+		if f.Accessors != nil {
+			tmp := tmpIdent(recv + "." + f.Name)
+			fmt.Fprintf(
+				&buf,
+				"{\nvar %s %s\n%s\n%s.%s(%s)\n}\n",
+				tmp,
+				f.Accessors.TypeName,
+				fieldDecoder(i, tmp),
+				recv,
+				f.Accessors.Setter,
+				tmp,
+			)
+			continue
+		}
+		buf.WriteString(fieldDecoder(i, recv+"."+f.Name))
 	}
 	beforecs, aftercs := constraintsForTpl(constraints, recv)
 	buf.WriteString(beforecs)
@@ -407,6 +550,62 @@ type StructField struct {
 	Name        string
 	Type        Type
 	Constraints []Constraint
+	// Accessors are the methods used to get and set the field if it can't
+	// be accessed directly, or nil.
+	Accessors *Accessors
+	// Pos is the position of the field declaration.
+	Pos token.Position
+	// Specs are the constraints as declared in the struct tag of the
+	// field, in the same order as Constraints.
+	Specs []ConstraintSpec
+}
+
+// ConstraintSpec is a constraint as declared in a struct tag.
+type ConstraintSpec struct {
+	// Name of the constraint, such as "maxlen".
+	Name string
+	// Args are the arguments of the constraint, or empty if it has none.
+	Args string
+}
+
+// Accessors are the methods of a struct to get and set one of its fields.
+type Accessors struct {
+	TypeName string
+	Getter   string
+	Setter   string
+}
+
+// Embedded is a flattened struct embedded as a pointer. Its fields are
+// encoded as if the pointer was not nil, so a nil pointer is encoded as the
+// zero value of the struct and it's never nil when decoded.
+type Embedded struct {
+	TypeName string
+	Struct   Struct
+}
+
+// Encoder implements the Type interface.
+func (t Embedded) Encoder(recv string) string {
+	tmp := tmpIdent(recv)
+	return fmt.Sprintf(`
+{
+	%[2]s := %[1]s
+	if %[2]s == nil {
+		%[2]s = new(%[3]s)
+	}
+	%[4]s
+}
+`, recv, tmp, t.TypeName, t.Struct.Encoder("(*"+tmp+")"))
+}
+
+// Decoder implements the Type interface.
+func (t Embedded) Decoder(recv string, root bool, constraints ...Constraint) string {
+	recv = recvPrefix(root) + recv
+	return fmt.Sprintf(`
+{
+	%[1]s = new(%[2]s)
+	%[3]s
+}
+`, recv, t.TypeName, t.Struct.Decoder("(*"+recv+")", false, constraints...))
 }
 
 // Bytes is a special type for []byte.
@@ -432,45 +631,504 @@ func (t Bytes) Decoder(recv string, root bool, constraints ...Constraint) string
 	)
 }
 
-func typeName(ctx *parseContext, typ types.Type) string {
-	if named, ok := typ.(*types.Named); ok {
-		pkgName := named.Obj().Pkg().Name()
-		pkgPath := named.Obj().Pkg().Path()
-		typeName := named.Obj().Name()
+// Time is a special type for time.Time.
+type Time struct {
+	TypeName string
+}
+
+// Encoder implements the Type interface.
+func (t Time) Encoder(recv string) string {
+	return fmt.Sprintf(writeTime, recv)
+}
+
+// Decoder implements the Type interface.
+func (t Time) Decoder(recv string, root bool, constraints ...Constraint) string {
+	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	return fmt.Sprintf(
+		readTime,
+		t.TypeName,
+		recv,
+		recvPrefix(root),
+		beforecs,
+		aftercs,
+	)
+}
+
+// Duration is a special type for time.Duration. It is encoded just like an
+// int64, but constraints on it take durations as arguments.
+type Duration struct {
+	TypeName string
+}
+
+// Encoder implements the Type interface.
+func (t Duration) Encoder(recv string) string {
+	return Basic{t.TypeName, Int64}.Encoder(recv)
+}
+
+// Decoder implements the Type interface.
+func (t Duration) Decoder(recv string, root bool, constraints ...Constraint) string {
+	return Basic{t.TypeName, Int64}.Decoder(recv, root, constraints...)
+}
+
+// Marshaler is a type that knows how to encode and decode itself to and from
+// bytes, such as an encoding.BinaryMarshaler. Its encoded representation is
+// prefixed with its length.
+type Marshaler struct {
+	TypeName string
+	// Marshal is the name of the method returning the encoded bytes.
+	Marshal string
+	// Unmarshal is the name of the method decoding the bytes.
+	Unmarshal string
+}
+
+// Encoder implements the Type interface.
+func (t Marshaler) Encoder(recv string) string {
+	return fmt.Sprintf(writeMarshaler, recv, t.Marshal)
+}
+
+// Decoder implements the Type interface.
+func (t Marshaler) Decoder(recv string, root bool, constraints ...Constraint) string {
+	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	return fmt.Sprintf(
+		readMarshaler,
+		t.TypeName,
+		recv,
+		recvPrefix(root),
+		beforecs,
+		aftercs,
+		t.Unmarshal,
+	)
+}
+
+// Delegate is a type that already has bindec methods, usually because they
+// were generated in another package.
+type Delegate struct {
+	TypeName string
+	// named is the type delegated to.
+	named *types.Named
+}
+
+// Encoder implements the Type interface.
+func (t Delegate) Encoder(recv string) string {
+	return fmt.Sprintf(writeDelegate, recv)
+}
 
-		// If the path of the package starts with a / means it's an absolute
-		// path, so it must be the current package. In that case we need to
-		// ignore the package name in the type name and not import it.
-		// TODO: fix this for windows
-		if !strings.HasPrefix(pkgPath, "/") {
-			ctx.addImport(pkgPath)
-			return pkgName + "." + typeName
+// Decoder implements the Type interface.
+func (t Delegate) Decoder(recv string, root bool, constraints ...Constraint) string {
+	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	return fmt.Sprintf(
+		readDelegate,
+		recv,
+		recvPrefix(root),
+		beforecs,
+		aftercs,
+	)
+}
+
+// marshalers are the pairs of methods types can implement to encode and
+// decode themselves, in order of preference.
+var marshalers = [][2]string{
+	{"MarshalBinary", "UnmarshalBinary"},
+	{"GobEncode", "GobDecode"},
+}
+
+// parseDelegate returns the type that delegates the encoding and decoding
+// of t to its own methods, or nil if it does not have the needed methods.
+func parseDelegate(ctx *parseContext, t *types.Named) Type {
+	// Types of the package being generated may have stale bindec methods
+	// from a previous generation, so they are never delegated to.
+	if t.Obj().Pkg() != ctx.pkg.Package &&
+		hasMethod(t, "WriteBinary", []typeMatcher{isNamedType("io", "Writer")}, isError) &&
+		hasMethod(t, "DecodeBinary", []typeMatcher{isNamedType("io", "Reader")}, isError) {
+		return Delegate{TypeName: typeName(ctx, t), named: t}
+	}
+
+	for _, m := range marshalers {
+		if hasMethod(t, m[0], nil, isBytes, isError) &&
+			hasMethod(t, m[1], []typeMatcher{isBytes}, isError) {
+			ctx.addImport("fmt")
+			return Marshaler{typeName(ctx, t), m[0], m[1]}
 		}
+	}
+
+	return nil
+}
+
+type typeMatcher func(types.Type) bool
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func isBytes(t types.Type) bool {
+	return types.Identical(t, types.NewSlice(types.Typ[types.Byte]))
+}
+
+func isNamedType(pkgPath, name string) typeMatcher {
+	return func(t types.Type) bool {
+		return isNamed(t, pkgPath, name)
+	}
+}
+
+// hasMethod reports whether an addressable value of type t has a method with
+// the given name whose parameters and results match the given ones.
+func hasMethod(t types.Type, name string, params []typeMatcher, results ...typeMatcher) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := fn.Type().(*types.Signature)
+	return !sig.Variadic() &&
+		tupleMatches(sig.Params(), params) &&
+		tupleMatches(sig.Results(), results)
+}
+
+func tupleMatches(tuple *types.Tuple, matchers []typeMatcher) bool {
+	if tuple.Len() != len(matchers) {
+		return false
+	}
+
+	for i, m := range matchers {
+		if !m(tuple.At(i).Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Recursive is a named type that contains itself, directly or through other
+// types. Instead of inlining its encoder and decoder, they are generated as
+// helper functions that call themselves.
+type Recursive struct {
+	TypeName string
+	// helper is the name of the helpers to encode and decode the type,
+	// without their encode and decode prefixes.
+	helper string
+	// typeArgs are the type arguments the helpers are called with if the
+	// generated type is generic, such as [T].
+	typeArgs string
+	// refs reports whether the helpers take the tracked references.
+	refs bool
+}
+
+// Encoder implements the Type interface.
+func (t Recursive) Encoder(recv string) string {
+	return fmt.Sprintf(writeRecursive, recv, t.helper+t.typeArgs, refsArg(t.refs))
+}
+
+// Decoder implements the Type interface.
+func (t Recursive) Decoder(recv string, root bool, constraints ...Constraint) string {
+	if !root {
+		recv = "&" + recv
+	}
+	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	return fmt.Sprintf(
+		readRecursive,
+		recv,
+		t.helper+t.typeArgs,
+		beforecs,
+		aftercs,
+		refsArg(t.refs),
+	)
+}
+
+// refsArg returns the argument passing the tracked references to a helper,
+// if it takes them.
+func refsArg(refs bool) string {
+	if refs {
+		return "refs, "
+	}
+	return ""
+}
 
-		return typeName
+// Union is an interface type whose value can only be one of a closed set
+// of types. The type of the value is encoded with its position in the set.
+type Union struct {
+	TypeName string
+	Types    []UnionType
+}
+
+// UnionType is one of the types of a union.
+type UnionType struct {
+	TypeName string
+	Type     Type
+}
+
+// Encoder implements the Type interface.
+func (t Union) Encoder(recv string) string {
+	var buf bytes.Buffer
+	for i, u := range t.Types {
+		fmt.Fprintf(&buf, unionEncoderCase, u.TypeName, i+1, u.Type.Encoder("u"))
+	}
+
+	return fmt.Sprintf(writeUnion, recv, buf.String())
+}
+
+// Decoder implements the Type interface.
+func (t Union) Decoder(recv string, root bool, constraints ...Constraint) string {
+	tmpIdent := tmpIdent(recv)
+	recv = recvPrefix(root) + recv
+
+	var buf bytes.Buffer
+	for i, u := range t.Types {
+		fmt.Fprintf(
+			&buf,
+			unionDecoderCase,
+			i+1,
+			tmpIdent,
+			u.TypeName,
+			u.Type.Decoder(tmpIdent, false),
+			recv,
+		)
 	}
 
-	return typ.String()
+	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	return fmt.Sprintf(
+		readUnion,
+		recv,
+		buf.String(),
+		t.TypeName,
+		beforecs,
+		aftercs,
+	)
+}
+
+// TypeParam is a type parameter of a generic type. Since its type is only
+// known at runtime, it's encoded and decoded depending on the type of the
+// value, which can be one of the types of the union of its constraint.
+// If the constraint is not a union of types, the value is encoded and
+// decoded with its bindec methods.
+type TypeParam struct {
+	TypeName string
+	Types    []UnionType
+}
+
+// Encoder implements the Type interface.
+func (t TypeParam) Encoder(recv string) string {
+	if len(t.Types) == 0 {
+		return fmt.Sprintf(writeDelegate, recv)
+	}
+
+	var buf bytes.Buffer
+	for _, u := range t.Types {
+		fmt.Fprintf(&buf, "case %s:\n%s", u.TypeName, u.Type.Encoder("v"))
+	}
+
+	return fmt.Sprintf(writeTypeParam, recv, buf.String(), t.TypeName)
+}
+
+// Decoder implements the Type interface.
+func (t TypeParam) Decoder(recv string, root bool, constraints ...Constraint) string {
+	beforecs, aftercs := constraintsForTpl(constraints, recv)
+	recv = recvPrefix(root) + recv
+	if len(t.Types) == 0 {
+		return fmt.Sprintf(readTypeParamDelegate, recv, t.TypeName, beforecs, aftercs)
+	}
+
+	var buf bytes.Buffer
+	for _, u := range t.Types {
+		fmt.Fprintf(&buf, "case *%s:\n%s", u.TypeName, u.Type.Decoder("p", true))
+	}
+
+	return fmt.Sprintf(readTypeParam, recv, buf.String(), t.TypeName, beforecs, aftercs)
+}
+
+func isNamed(typ types.Type, pkgPath, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+
+	return named.Obj().Pkg().Path() == pkgPath && named.Obj().Name() == name
+}
+
+func typeName(ctx *parseContext, typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		// Types of the current package are not qualified.
+		if pkg == ctx.pkg.Package {
+			return ""
+		}
+
+		return ctx.addNamedImport(pkg.Path(), pkg.Name())
+	})
 }
 
 type parseContext struct {
-	imports map[string]struct{}
-	decls   map[string]struct{}
-	seen    []string
+	pkg *packageInfo
+	// imports is a map from the imported package paths to their names.
+	imports map[string]string
+	// reserved contains the identifiers that can't be used as names of
+	// imported packages, besides the ones of other imports.
+	reserved map[string]bool
+	decls    map[string]struct{}
+	// recursive contains the named types that contain themselves.
+	recursive map[string]bool
+	// helpers contains the helpers of the recursive types by name.
+	helpers map[string]helper
+	// root is the name of the type whose methods are generated, which the
+	// helpers of its recursive types are named after, so the helpers
+	// generated for different types never conflict, even in different
+	// files. rootType is its type name.
+	root     string
+	rootType string
+	// typeParams are the type parameters of the root type, such as
+	// [T int | string], if it's generic.
+	typeParams string
+	// typeArgs are the type arguments helpers are called with if the root
+	// type is generic, such as [T].
+	typeArgs string
+	// field reports whether the root type is the type of a field instead
+	// of a type being generated, so it can delegate to its methods.
+	field bool
+	// refs reports whether the values pointed to are tracked.
+	refs bool
+	// refsUsed reports whether any tracked pointer was found.
+	refsUsed *bool
+	// union contains the names of the types of the union for the interface
+	// types being parsed, which are declared in unionPkg.
+	union    []string
+	unionPkg *types.Package
+	seen     []string
+	// path is the path of the field being parsed from the root type.
+	path string
 }
 
-func newParseContext() *parseContext {
+// helper is a function to encode and decode a recursive type.
+type helper struct {
+	Name     string
+	TypeName string
+	Type     Type
+	// Refs reports whether the helper takes the tracked references.
+	Refs bool
+	// TypeParams are the type parameters of the helper, if any.
+	TypeParams string
+}
+
+func newParseContext(pkg *packageInfo) *parseContext {
+	reserved := make(map[string]bool)
+	for _, ident := range generatedIdents {
+		reserved[ident] = true
+	}
+
 	return &parseContext{
-		imports: make(map[string]struct{}),
-		decls:   make(map[string]struct{}),
+		pkg:      pkg,
+		imports:  make(map[string]string),
+		reserved: reserved,
+		decls:    make(map[string]struct{}),
 	}
 }
 
+// generatedIdents are the identifiers declared by the generated code that
+// would shadow imported packages with the same name.
+var generatedIdents = []string{
+	"b", "bs", "buf", "c", "d", "data", "depth", "err", "i", "id", "im",
+	"k", "key", "loc", "n", "name", "nsec", "off", "offset", "ok", "p",
+	"ptr", "re", "reader", "ref", "refs", "ru", "s", "sec", "sz", "tm",
+	"u", "uoffset", "ux", "ux2", "v", "value", "writer", "x",
+}
+
+// templateImports are the packages imported by the generated code by name.
+// Their names can't be used by any other imported package.
+var templateImports = map[string]string{
+	"binary":  "encoding/binary",
+	"bytes":   "bytes",
+	"fmt":     "fmt",
+	"io":      "io",
+	"math":    "math",
+	"net":     "net",
+	"regexp":  "regexp",
+	"strings": "strings",
+	"time":    "time",
+	"unicode": "unicode",
+	"url":     "net/url",
+}
+
 func (ctx *parseContext) clone() *parseContext {
 	seen := make([]string, len(ctx.seen))
 	copy(seen, ctx.seen)
 
-	return &parseContext{ctx.imports, ctx.decls, seen}
+	return &parseContext{
+		ctx.pkg,
+		ctx.imports,
+		ctx.reserved,
+		ctx.decls,
+		ctx.recursive,
+		ctx.helpers,
+		ctx.root,
+		ctx.rootType,
+		ctx.typeParams,
+		ctx.typeArgs,
+		ctx.field,
+		ctx.refs,
+		ctx.refsUsed,
+		ctx.union,
+		ctx.unionPkg,
+		seen,
+		ctx.path,
+	}
+}
+
+// forRoot returns a new context to parse the given root type of a set of
+// generated methods, which is declared with the given name. Helpers and
+// declarations belong to these methods.
+func (ctx *parseContext) forRoot(name string, typ types.Type) *parseContext {
+	ctx = ctx.clone()
+	ctx.seen = nil
+	ctx.recursive = make(map[string]bool)
+	ctx.helpers = make(map[string]helper)
+	ctx.decls = make(map[string]struct{})
+	ctx.root = name
+	ctx.rootType = typeName(ctx, typ)
+	ctx.typeParams, ctx.typeArgs = "", ""
+	ctx.refsUsed = new(bool)
+
+	if named, ok := typ.(*types.Named); ok && named.TypeParams().Len() > 0 {
+		var params, args = make([]string, named.TypeParams().Len()), make([]string, named.TypeParams().Len())
+		for i := range params {
+			tp := named.TypeParams().At(i)
+			args[i] = tp.Obj().Name()
+			params[i] = args[i] + " " + typeName(ctx, tp.Constraint())
+		}
+		ctx.typeParams = "[" + strings.Join(params, ", ") + "]"
+		ctx.typeArgs = "[" + strings.Join(args, ", ") + "]"
+	}
+	return ctx
+}
+
+// helperName returns the name of the helpers of the recursive type with
+// the given name, without their encode and decode prefixes.
+func (ctx *parseContext) helperName(typeName string) string {
+	if typeName == ctx.rootType {
+		return identifier(ctx.root)
+	}
+	return identifier(ctx.root) + "_" + identifier(typeName)
+}
+
+// recursiveType returns the type referring to the recursive type with the
+// given name, which is encoded and decoded with its helpers.
+func (ctx *parseContext) recursiveType(typeName string) Recursive {
+	return Recursive{typeName, ctx.helperName(typeName), ctx.typeArgs, ctx.refs}
+}
+
+func (ctx *parseContext) addHelper(typeName string, typ Type) {
+	name := ctx.helperName(typeName)
+	ctx.helpers[name] = helper{name, typeName, typ, ctx.refs, ctx.typeParams}
+}
+
+func (ctx *parseContext) getHelpers() []helper {
+	var names []string
+	for name := range ctx.helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result = make([]helper, len(names))
+	for i, name := range names {
+		result[i] = ctx.helpers[name]
+	}
+	return result
 }
 
 func (ctx *parseContext) markSeen(typ types.Type) {
@@ -488,21 +1146,80 @@ func (ctx *parseContext) isSeen(typ types.Type) bool {
 	return false
 }
 
+// addImport adds a package used by the generated code, which is always
+// referred to by the last element of its path.
 func (ctx *parseContext) addImport(pkg string) {
-	ctx.imports[pkg] = struct{}{}
+	ctx.imports[pkg] = path.Base(pkg)
+}
+
+// addNamedImport adds the package with the given path and name to the
+// imports and returns the name it must be referred to with. If the name is
+// already in use, an alias is generated for the package.
+func (ctx *parseContext) addNamedImport(pkg, name string) string {
+	if n, ok := ctx.imports[pkg]; ok {
+		return n
+	}
+
+	alias := name
+	for i := 2; !ctx.isImportNameAvailable(pkg, alias); i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+
+	ctx.imports[pkg] = alias
+	return alias
+}
+
+func (ctx *parseContext) isImportNameAvailable(pkg, name string) bool {
+	if path, ok := templateImports[name]; ok && path != pkg {
+		return false
+	}
+
+	if ctx.reserved[name] || ctx.pkg.Scope().Lookup(name) != nil {
+		return false
+	}
+
+	for _, n := range ctx.imports {
+		if n == name {
+			return false
+		}
+	}
+	return true
 }
 
 func (ctx *parseContext) addDecl(decl string) {
 	ctx.decls[decl] = struct{}{}
 }
 
+// getImports returns the import specs of all the imported packages, sorted
+// by path, with the packages of the standard library first and separated
+// from the rest by an empty line. Packages whose name is not the last
+// element of their path are imported with an explicit name.
 func (ctx *parseContext) getImports() []string {
-	var result []string
-	for i := range ctx.imports {
-		result = append(result, i)
+	var std, other []string
+	for p := range ctx.imports {
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			other = append(other, p)
+		} else {
+			std = append(std, p)
+		}
 	}
+	sort.Strings(std)
+	sort.Strings(other)
 
-	sort.Strings(result)
+	var result []string
+	for _, paths := range [][]string{std, other} {
+		if len(paths) > 0 && len(result) > 0 {
+			result = append(result, "")
+		}
+
+		for _, p := range paths {
+			if name := ctx.imports[p]; name != path.Base(p) {
+				result = append(result, fmt.Sprintf("%s %q", name, p))
+			} else {
+				result = append(result, fmt.Sprintf("%q", p))
+			}
+		}
+	}
 	return result
 }
 
@@ -528,28 +1245,73 @@ func stringContains(slice []string, str string) bool {
 func parseType(ctx *parseContext, t types.Type) (Type, error) {
 	switch t := t.(type) {
 	case *types.Named:
-		if ctx.isSeen(t) {
-			return nil, fmt.Errorf("there is a cyclic reference, type %s was already seen", t)
+		// time.Time has unexported fields, so it can't be encoded as any
+		// other struct.
+		if isNamed(t, "time", "Time") {
+			ctx.addImport("fmt")
+			return Time{typeName(ctx, t)}, nil
+		}
+
+		if isNamed(t, "time", "Duration") {
+			return Duration{typeName(ctx, t)}, nil
+		}
+
+		// The type being generated can not delegate to its own methods.
+		if len(ctx.seen) > 0 || ctx.field {
+			if typ := parseDelegate(ctx, t); typ != nil {
+				return typ, nil
+			}
+		}
+
+		if _, ok := ctx.helpers[ctx.helperName(typeName(ctx, t))]; ok || ctx.isSeen(t) {
+			ctx.recursive[t.String()] = true
+			ctx.addImport("fmt")
+			return ctx.recursiveType(typeName(ctx, t)), nil
+		}
+
+		if _, ok := t.Underlying().(*types.Interface); ok && len(ctx.union) == 0 {
+			if args := ctx.pkg.directives(t.Obj(), "union"); len(args) > 0 {
+				ctx.union = strings.Fields(strings.Join(args, " "))
+				ctx.unionPkg = t.Obj().Pkg()
+			}
 		}
 
 		ctx.markSeen(t)
-		typ, err := parseType(ctx, t.Underlying())
+		var typ Type
+		var err error
+		if st, ok := t.Underlying().(*types.Struct); ok {
+			typ, err = parseStruct(ctx, st, t)
+		} else {
+			typ, err = parseType(ctx, t.Underlying())
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		return replaceTypeName(typ, typeName(ctx, t)), nil
+		typ = replaceTypeName(typ, typeName(ctx, t))
+		if ctx.recursive[t.String()] {
+			ctx.addHelper(typeName(ctx, t), typ)
+			return ctx.recursiveType(typeName(ctx, t)), nil
+		}
+
+		return typ, nil
 	case *types.Struct:
-		return parseStruct(ctx, t)
+		return parseStruct(ctx, t, nil)
 	case *types.Pointer:
 		elem, err := parseType(ctx, t.Elem())
 		if err != nil {
 			return nil, err
 		}
 
+		if ctx.refs {
+			*ctx.refsUsed = true
+			ctx.addImport("fmt")
+		}
+
 		return Maybe{
 			typeName(ctx, t.Elem()),
 			elem,
+			ctx.refs,
 		}, nil
 	case *types.Array:
 		elem, err := parseType(ctx, t.Elem())
@@ -587,6 +1349,10 @@ func parseType(ctx *parseContext, t types.Type) (Type, error) {
 			return nil, err
 		}
 
+		if _, ok := bulkSliceDecoders[bulkKind(elem)]; ok {
+			ctx.addImport("fmt")
+		}
+
 		return Slice{tn, elem}, nil
 	case *types.Basic:
 		switch t.Kind() {
@@ -604,65 +1370,461 @@ func parseType(ctx *parseContext, t types.Type) (Type, error) {
 			types.Uint64,
 			types.Uintptr,
 			types.Float32,
-			types.Float64:
+			types.Float64,
+			types.Complex64,
+			types.Complex128:
 			return Basic{typeName(ctx, t), t.Kind()}, nil
 		default:
-			return nil, fmt.Errorf("type contains a basic type which cannot be serialized (unsafe pointer or complex number)")
+			return nil, fmt.Errorf("type contains a basic type which cannot be serialized (unsafe pointer)")
 		}
+	case *types.TypeParam:
+		return parseTypeParam(ctx, t)
 	case *types.Chan:
 		return nil, fmt.Errorf("type contains a channel type which cannot be serialized")
 	case *types.Signature:
 		return nil, fmt.Errorf("type contains a function type which cannot be serialized")
 	case *types.Interface:
-		return nil, fmt.Errorf("type contains an interface type which cannot be serialized")
+		if len(ctx.union) == 0 {
+			return nil, fmt.Errorf("type contains an interface type which cannot be serialized without declaring its implementations as a union")
+		}
+
+		return parseUnion(ctx, t)
 	default:
 		return nil, fmt.Errorf("invalid type received: %T", t)
 	}
 }
 
-func parseStruct(ctx *parseContext, t *types.Struct) (Type, error) {
-	var s Struct
+func parseUnion(ctx *parseContext, t *types.Interface) (Type, error) {
+	if len(ctx.union) > math.MaxUint8 {
+		return nil, fmt.Errorf("unions can have at most %d types, got %d", math.MaxUint8, len(ctx.union))
+	}
+
+	var u = Union{TypeName: typeName(ctx, t)}
+	for _, name := range ctx.union {
+		var ptr bool
+		if strings.HasPrefix(name, "*") {
+			ptr = true
+			name = name[1:]
+		}
+
+		// The types are declared along with the union, which is not in the
+		// package being generated when functions of another one are.
+		pkg := ctx.unionPkg
+		if pkg == nil {
+			pkg = ctx.pkg.Package
+		}
+
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s of union not found in %s", name, pkg.Path())
+		}
+
+		var typ = obj.Type()
+		if ptr {
+			typ = types.NewPointer(typ)
+		}
+
+		if !types.Implements(typ, t) {
+			return nil, fmt.Errorf("type %s of union does not implement the interface", typ)
+		}
+
+		vctx := ctx.clone()
+		vctx.union = nil
+		vt, err := parseType(vctx, typ)
+		if _, ok := err.(Diagnostics); ok {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("on type %s of union: %s", typ, err)
+		}
+
+		u.Types = append(u.Types, UnionType{typeName(ctx, typ), vt})
+	}
+
+	ctx.addImport("fmt")
+	return u, nil
+}
+
+func parseTypeParam(ctx *parseContext, t *types.TypeParam) (Type, error) {
+	ctx.addImport("fmt")
+	iface := t.Constraint().Underlying().(*types.Interface)
+
+	var terms []types.Type
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch e := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < e.Len(); j++ {
+				term := e.Term(j)
+				if term.Tilde() {
+					return nil, fmt.Errorf("type parameter %s has an approximation constraint %s, only exact types are supported", t, term)
+				}
+				terms = append(terms, term.Type())
+			}
+		default:
+			if _, ok := e.Underlying().(*types.Interface); !ok {
+				terms = append(terms, e)
+			}
+		}
+	}
+
+	tp := TypeParam{TypeName: t.Obj().Name()}
+	if len(terms) == 0 {
+		if !hasMethod(t, "WriteBinary", []typeMatcher{isNamedType("io", "Writer")}, isError) {
+			return nil, fmt.Errorf("type parameter %s must be constrained to a union of types or to types with a WriteBinary method", t)
+		}
+		return tp, nil
+	}
+
+	for _, term := range terms {
+		tctx := ctx.clone()
+		tctx.union = nil
+		typ, err := parseType(tctx, term)
+		if _, ok := err.(Diagnostics); ok {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("on type %s of type parameter %s: %s", term, t, err)
+		}
+
+		tp.Types = append(tp.Types, UnionType{typeName(ctx, term), typ})
+	}
+
+	return tp, nil
+}
+
+// parseStruct parses a struct type, whose named type is given if it has
+// one.
+func parseStruct(ctx *parseContext, t *types.Struct, named *types.Named) (Type, error) {
+	diags := checkPromotedFields(ctx, t, "", false, make(map[string]string), nil)
+
+	fields, err := parseFields(ctx, t, named, "", false)
+	if err != nil {
+		diags = append(diags, err.(Diagnostics)...)
+	}
+
+	if len(diags) > 0 {
+		return nil, diags
+	}
+
+	return Struct{fields}, nil
+}
+
+// parseFields parses the fields of a struct. Fields promoted from flattened
+// embedded structs are named after their path from the struct, which is
+// given by prefix. If flatten is true, all embedded fields are flattened.
+func parseFields(
+	ctx *parseContext,
+	t *types.Struct,
+	named *types.Named,
+	prefix string,
+	flatten bool,
+) ([]StructField, error) {
+	var fields []StructField
+	var diags Diagnostics
 	for i := 0; i < t.NumFields(); i++ {
 		f := t.Field(i)
 		cfg, err := parseTag(t.Tag(i))
 		if err != nil {
-			return nil, fmt.Errorf("error parsing tag of field %s: %s", f.Name(), err)
+			diags = append(diags, fieldDiagnostics(ctx, f, ctx.pkg.tagPos(f), fmt.Errorf("invalid struct tag: %s", err))...)
+			continue
 		}
 
-		if cfg.ignore {
+		// Blank fields can't be accessed.
+		if cfg.ignore || f.Name() == "_" {
 			continue
 		}
 
-		ft, err := parseType(ctx.clone(), f.Type())
-		if err != nil {
-			return nil, fmt.Errorf("on field %s: %s", f.Name(), err)
+		var accessors *Accessors
+		if !f.Exported() && f.Pkg() != ctx.pkg.Package {
+			accessors = findAccessors(ctx, named, f)
+			if accessors == nil {
+				diags = append(diags, fieldDiagnostics(ctx, f, f.Pos(), fmt.Errorf(
+					"unexported field %s of type %s can not be encoded, the type needs the methods %s() and %s(%s), or to implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler",
+					fieldPath(ctx.path, f.Name()),
+					typeName(ctx, typeOrStruct(named, t)),
+					exportedName(f.Name()),
+					"Set"+exportedName(f.Name()),
+					typeName(ctx, f.Type()),
+				))...)
+				continue
+			}
 		}
 
-		var cs = make([]string, 0, len(cfg.constraints))
-		for name := range cfg.constraints {
-			cs = append(cs, name)
+		if err := checkFlatten(f, cfg, flatten); err != nil {
+			diags = append(diags, fieldDiagnostics(ctx, f, ctx.pkg.tagPos(f), err)...)
+			continue
 		}
-		sort.Strings(cs)
 
-		var constraints = make([]Constraint, len(cs))
-		for i, name := range cs {
-			c, err := parseConstraint(ctx, name, cfg.constraints[name], f.Name(), ft)
+		if f.Embedded() && (cfg.flatten || flatten) {
+			fs, err := parseEmbedded(ctx, f, prefix)
 			if err != nil {
-				return nil, fmt.Errorf(
-					"on constraint %q of field %q: %s",
-					name, f.Name(), err,
-				)
+				diags = append(diags, fieldDiagnostics(ctx, f, f.Pos(), err)...)
+				continue
 			}
-			constraints[i] = c
+
+			fields = append(fields, fs...)
+			continue
+		}
+
+		fctx := ctx.clone()
+		fctx.union = cfg.union
+		fctx.unionPkg = f.Pkg()
+		fctx.path = fieldPath(ctx.path, f.Name())
+		ft, err := parseType(fctx, f.Type())
+		if err != nil {
+			diags = append(diags, fieldDiagnostics(ctx, f, f.Pos(), err)...)
+			continue
+		}
+
+		constraints, specs, errs := parseConstraints(ctx, cfg, f.Name(), ft)
+		for _, err := range errs {
+			diags = append(diags, fieldDiagnostics(ctx, f, ctx.pkg.tagPos(f), err)...)
 		}
 
-		s.Fields = append(s.Fields, StructField{f.Name(), ft, constraints})
+		fields = append(fields, StructField{
+			Name:        prefix + f.Name(),
+			Type:        ft,
+			Constraints: constraints,
+			Accessors:   accessors,
+			Pos:         ctx.pkg.fset.Position(f.Pos()),
+			Specs:       specs,
+		})
+	}
+
+	if len(diags) > 0 {
+		return nil, diags
+	}
+	return fields, nil
+}
+
+// checkFlatten returns an error if the field is flattened but it's not
+// embedded, or if it's flattened and has constraints or unions. If flatten
+// is true, the field is flattened if it's embedded, even if its tag does
+// not say so.
+func checkFlatten(f *types.Var, cfg *fieldConfig, flatten bool) error {
+	if cfg.flatten && !f.Embedded() {
+		return fmt.Errorf("field %s can not be flattened because it's not embedded", f.Name())
+	}
+
+	if f.Embedded() && (cfg.flatten || flatten) && (len(cfg.constraints) > 0 || len(cfg.union) > 0) {
+		return fmt.Errorf("flattened field %s can not have constraints or unions", f.Name())
 	}
-	return s, nil
+	return nil
+}
+
+// parseConstraints parses the constraints in the tag of the field with the
+// given name and type. Constraints are sorted by name, so the generated code
+// is always the same. The errors of all invalid constraints are returned.
+func parseConstraints(ctx *parseContext, cfg *fieldConfig, field string, typ Type) ([]Constraint, []ConstraintSpec, []error) {
+	var cs = make([]string, 0, len(cfg.constraints))
+	for name := range cfg.constraints {
+		cs = append(cs, name)
+	}
+	sort.Strings(cs)
+
+	var constraints = make([]Constraint, 0, len(cs))
+	var specs = make([]ConstraintSpec, 0, len(cs))
+	var errs []error
+	for _, name := range cs {
+		c, err := parseConstraint(ctx, name, cfg.constraints[name], field, typ)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("on constraint %q: %s", name, err))
+			continue
+		}
+		constraints = append(constraints, c)
+		specs = append(specs, ConstraintSpec{name, cfg.constraints[name]})
+	}
+	return constraints, specs, errs
+}
+
+// parseEmbedded parses the fields of a flattened embedded struct. Fields of
+// embedded structs are promoted, unless the struct is embedded as a pointer,
+// in which case they are all part of an Embedded type.
+func parseEmbedded(ctx *parseContext, f *types.Var, prefix string) ([]StructField, error) {
+	typ := f.Type()
+	ptr, isPtr := typ.(*types.Pointer)
+	if isPtr {
+		typ = ptr.Elem()
+	}
+
+	st, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("embedded field %s can not be flattened because it's not a struct or a pointer to a struct", f.Name())
+	}
+
+	if ctx.isSeen(typ) {
+		return nil, fmt.Errorf("embedded field %s can not be flattened because its type is recursive", f.Name())
+	}
+
+	named, _ := typ.(*types.Named)
+	ectx := ctx.clone()
+	ectx.markSeen(typ)
+	ectx.path = fieldPath(ctx.path, f.Name())
+	if !isPtr {
+		return parseFields(ectx, st, named, prefix+f.Name()+".", true)
+	}
+
+	fields, err := parseFields(ectx, st, named, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return []StructField{{
+		Name: prefix + f.Name(),
+		Type: Embedded{typeName(ctx, typ), Struct{fields}},
+		Pos:  ctx.pkg.fset.Position(f.Pos()),
+	}}, nil
+}
+
+// findAccessors returns the accessors of the given unexported field of a
+// named type, which are a method named after the field that returns its
+// value and a method with the same name prefixed with "Set" that sets it.
+func findAccessors(ctx *parseContext, named *types.Named, f *types.Var) *Accessors {
+	if named == nil {
+		return nil
+	}
+
+	getter := exportedName(f.Name())
+	setter := "Set" + getter
+	isFieldType := func(t types.Type) bool {
+		return types.Identical(t, f.Type())
+	}
+
+	if !hasMethod(named, getter, nil, isFieldType) ||
+		!hasMethod(named, setter, []typeMatcher{isFieldType}) {
+		return nil
+	}
+
+	return &Accessors{typeName(ctx, f.Type()), getter, setter}
+}
+
+func exportedName(name string) string {
+	r, n := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[n:]
+}
+
+func fieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func typeOrStruct(named *types.Named, t *types.Struct) types.Type {
+	if named != nil {
+		return named
+	}
+	return t
+}
+
+// checkPromotedFields returns an error if two fields of the struct have the
+// same name once the fields of flattened embedded structs are promoted.
+// Names contains the paths of the fields seen so far by name.
+func checkPromotedFields(
+	ctx *parseContext,
+	t *types.Struct,
+	prefix string,
+	flatten bool,
+	names map[string]string,
+	seen []string,
+) Diagnostics {
+	var diags Diagnostics
+	for i := 0; i < t.NumFields(); i++ {
+		f := t.Field(i)
+		cfg, err := parseTag(t.Tag(i))
+		if err != nil || cfg.ignore {
+			continue
+		}
+
+		if f.Embedded() && (cfg.flatten || flatten) {
+			typ := f.Type()
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+
+			// Invalid and recursive embedded fields are reported when the
+			// fields are parsed.
+			st, ok := typ.Underlying().(*types.Struct)
+			if ok && !stringContains(seen, typ.String()) {
+				diags = append(diags, checkPromotedFields(
+					ctx,
+					st,
+					prefix+f.Name()+".",
+					true,
+					names,
+					append(seen, typ.String()),
+				)...)
+			}
+			continue
+		}
+
+		if other, ok := names[f.Name()]; ok {
+			diags = append(diags, Diagnostic{
+				Pos:   ctx.pkg.fset.Position(f.Pos()),
+				Field: fieldPath(ctx.path, prefix+f.Name()),
+				Message: fmt.Sprintf(
+					"field %s conflicts with field %s after flattening embedded fields",
+					prefix+f.Name(), other,
+				),
+			})
+			continue
+		}
+		names[f.Name()] = prefix + f.Name()
+	}
+	return diags
+}
+
+// CheckTag checks the bindec struct tag of a field of a struct declared in
+// the given package, whose syntax trees are needed to find the directives
+// of its types. It returns an error if the tag has unknown options or
+// constraints, constraints with invalid arguments or constraints that can't
+// be used with the type of the field, including fields of types that can't
+// be encoded. Other problems that are not in the tag, such as fields of
+// types that can't be encoded without constraints, are not reported.
+func CheckTag(fset *token.FileSet, files []*ast.File, pkg *types.Package, f *types.Var, tag string) error {
+	cfg, err := parseTag(tag)
+	if err != nil || cfg.ignore {
+		return err
+	}
+
+	if err := checkFlatten(f, cfg, false); err != nil {
+		return err
+	}
+
+	for _, name := range cfg.union {
+		name = strings.TrimPrefix(name, "*")
+		if _, ok := pkg.Scope().Lookup(name).(*types.TypeName); !ok {
+			return fmt.Errorf("type %s of union not found in %s", name, pkg.Path())
+		}
+	}
+
+	if len(cfg.constraints) == 0 {
+		return nil
+	}
+
+	// The field is parsed as it is when its struct is generated, so its
+	// type may delegate to its methods.
+	ctx := newParseContext(&packageInfo{pkg, fset, files, "", nil, nil}).forRoot(f.Name(), f.Type())
+	ctx.field = true
+	ctx.union = cfg.union
+	ctx.unionPkg = pkg
+	typ, err := parseType(ctx, f.Type())
+	if err != nil {
+		var diags Diagnostics
+		if errors.As(err, &diags) {
+			err = errors.New(diags[0].Message)
+		}
+		return fmt.Errorf("constraints can not be used on field %s because its type can not be encoded: %s", f.Name(), err)
+	}
+
+	if _, _, errs := parseConstraints(ctx, cfg, f.Name(), typ); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
 }
 
 type fieldConfig struct {
 	ignore      bool
+	flatten     bool
+	union       []string
 	constraints map[string]string
 }
 
@@ -684,12 +1846,30 @@ func parseTag(tag string) (*fieldConfig, error) {
 			continue
 		}
 
+		if t == "flatten" {
+			cfg.flatten = true
+			continue
+		}
+
 		parts := strings.Split(t, "=")
 		if len(parts) > 2 {
 			return nil, fmt.Errorf("invalid format for constraint in struct tag: %q", t)
 		}
 
 		c := parts[0]
+		if c == "union" {
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("union requires the types of the union as arguments")
+			}
+
+			for _, u := range strings.Split(parts[1], "|") {
+				if u = strings.TrimSpace(u); u != "" {
+					cfg.union = append(cfg.union, u)
+				}
+			}
+			continue
+		}
+
 		argsRequired, ok := constraints[c]
 		if !ok {
 			return nil, fmt.Errorf("constraint not found: %q", c)
@@ -713,15 +1893,19 @@ func parseTag(tag string) (*fieldConfig, error) {
 }
 
 func tmpIdent(recv string) string {
+	return "tmp_" + identifier(recv)
+}
+
+func identifier(s string) string {
 	var runes []rune
-	for _, ru := range recv {
+	for _, ru := range s {
 		if unicode.IsDigit(ru) || unicode.IsLetter(ru) || ru == '_' {
 			runes = append(runes, ru)
 		} else {
 			runes = append(runes, '_')
 		}
 	}
-	return "tmp_" + string(runes)
+	return string(runes)
 }
 
 func recvPrefix(root bool) string {
@@ -745,6 +1929,9 @@ func replaceTypeName(t Type, name string) Type {
 	case Bytes:
 		t.TypeName = name
 		return t
+	case Union:
+		t.TypeName = name
+		return t
 	default:
 		return t
 	}
@@ -1,6 +1,26 @@
 package bindec
 
-//go:generate ./bindec_bin -type=StructTestType,MapTestType,ArrayTestType,SliceTestType,ByteTestType,Uint16TestType,Uint32TestType,Uint64TestType,UintTestType,Int8TestType,Int16TestType,Int32TestType,Int64TestType,IntTestType,UintptrTestType,Float32TestType,Float64TestType,StringTestType,BytesTestType,BoolTestType,AlphaTestType,AlphanumTestType,NumericTestType,HexadecimalTestType,EmailTestType,URLTestType,Base64TestType,ContainsTestType,StartsWithTestType,EndsWithTestType,EqTestType,NeqTestType,UUIDTestType,IPTestType,IPv4TestType,IPv6TestType,OneOfTestType,MaxTestType,MinTestType,MaxLenTestType,MinLenTestType -o bindec_test.go
+import (
+	"io"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/bindec/bench"
+	"github.com/erizocosmico/bindec/internal/testpkg"
+	"github.com/erizocosmico/bindec/internal/testpkg/data"
+	"github.com/erizocosmico/bindec/internal/testpkg/v1/model"
+	modelv2 "github.com/erizocosmico/bindec/internal/testpkg/v2/model"
+)
+
+//go:generate ./bindec_bin -type=StructTestType,MapTestType,ArrayTestType,SliceTestType,ByteTestType,Uint16TestType,Uint32TestType,Uint64TestType,UintTestType,Int8TestType,Int16TestType,Int32TestType,Int64TestType,IntTestType,UintptrTestType,Float32TestType,Float64TestType,StringTestType,BytesTestType,BoolTestType,AlphaTestType,AlphanumTestType,NumericTestType,HexadecimalTestType,EmailTestType,URLTestType,Base64TestType,ContainsTestType,StartsWithTestType,EndsWithTestType,EqTestType,NeqTestType,UUIDTestType,IPTestType,IPv4TestType,IPv6TestType,OneOfTestType,MaxTestType,MinTestType,MaxLenTestType,MinLenTestType,TimeTestType,BeforeTestType,AfterTestType,NotZeroTestType,DurationTestType,DurationNanosTestType,PointerConstraintTestType,TrailingMaybeTestType,DelegateTestType,StructCyclic,TreeTestType,MutualATestType,UnionTestType,PairTestType,PageTestType,ListTestType,ComplexTestType,EmbeddedTestType,AccessorTestType,AliasTestType -o bindec_test.go
+//go:generate ./bindec_bin -refs -type=GraphTestType,PointerRefsConstraintTestType -o refs_bindec_test.go
+//go:generate ./bindec_bin -methods=encode -type=EncodeOnlyTestType -o encode_bindec_test.go
+//go:generate ./bindec_bin -methods=bytes -type=BytesOnlyTestType -o bytes_bindec_test.go
+//go:generate ./bindec_bin -methods=WriteBinary:Encode,DecodeBinary:Decode -type=RenamedTestType -o renamed_bindec_test.go
+//go:generate ./bindec_bin -functions -package=github.com/erizocosmico/bindec/internal/testpkg -type=Invoice,Account,Receipt -o functions_bindec_test.go
 
 type (
 	MapTestType   map[byte]uint16
@@ -102,9 +122,25 @@ type StructTestType struct {
 `
 
 type StructCyclic struct {
+	Value int
 	Cycle *StructCyclic
 }
 
+type TreeTestType struct {
+	Value    int
+	Children []TreeTestType
+	Index    map[string]*TreeTestType
+}
+
+type MutualATestType struct {
+	Name string
+	B    *MutualBTestType
+}
+
+type MutualBTestType struct {
+	As []MutualATestType
+}
+
 type AlphaTestType struct {
 	S string `bindec:"alpha"`
 }
@@ -258,3 +294,274 @@ type OneOfTestType struct {
 	Float32 float32 `bindec:"oneof=3.14 1.1 2.2"`
 	Float64 float64 `bindec:"oneof=3.14 1.1 2.2"`
 }
+
+type TimeTestType struct {
+	Time        time.Time
+	TimePointer *time.Time
+	NilTime     *time.Time
+	Duration    time.Duration
+}
+
+type BeforeTestType struct {
+	Time time.Time `bindec:"before=2019-01-01T00:00:00Z"`
+}
+
+type AfterTestType struct {
+	Time time.Time `bindec:"after=2019-01-01T00:00:00Z"`
+}
+
+type NotZeroTestType struct {
+	Time     time.Time     `bindec:"notzero"`
+	Duration time.Duration `bindec:"notzero"`
+}
+
+type DurationTestType struct {
+	Duration time.Duration `bindec:"min=1s,max=1h"`
+}
+
+// DurationNanosTestType has the constraints time.Duration fields could have
+// when they were checked as any other int64.
+type DurationNanosTestType struct {
+	Duration time.Duration `bindec:"min=1000,max=1000000"`
+	OneOf    time.Duration `bindec:"oneof=1000 2000"`
+}
+
+type PointerConstraintTestType struct {
+	String *string    `bindec:"maxlen=5,alpha"`
+	Int    *int       `bindec:"min=1"`
+	Time   *time.Time `bindec:"after=2019-01-01T00:00:00Z"`
+	Slice  *[]int     `bindec:"maxlen=2"`
+	Nil    *string    `bindec:"minlen=1"`
+}
+
+type PointerRefsConstraintTestType struct {
+	A *string
+	B *string `bindec:"maxlen=3"`
+	C *int    `bindec:"max=3"`
+}
+
+type TrailingMaybeTestType struct {
+	ID   int64
+	Name *string
+	Tags *[]string
+}
+
+type DelegateTestType struct {
+	URL       url.URL
+	Int       *big.Int
+	Foo       bench.Foo
+	Marshaler MarshalerTestType
+}
+
+type MarshalerTestType struct {
+	n int
+}
+
+func (t MarshalerTestType) MarshalBinary() ([]byte, error) {
+	return []byte(strconv.Itoa(t.n)), nil
+}
+
+func (t *MarshalerTestType) UnmarshalBinary(data []byte) error {
+	n, err := strconv.Atoi(string(data))
+	if err != nil {
+		return err
+	}
+	t.n = n
+	return nil
+}
+
+type GraphTestType struct {
+	A     *GraphNodeTestType
+	B     *GraphNodeTestType
+	Nodes []*GraphNodeTestType
+}
+
+type GraphNodeTestType struct {
+	Name string
+	Next *GraphNodeTestType
+}
+
+//bindec:union CreatedTestType *DeletedTestType
+type EventTestType interface {
+	isEvent()
+}
+
+type CreatedTestType struct {
+	ID int
+}
+
+func (CreatedTestType) isEvent() {}
+
+type DeletedTestType struct {
+	ID     int
+	Reason string
+}
+
+func (*DeletedTestType) isEvent() {}
+
+//bindec:union LiteralTestType BinaryTestType
+type ExprTestType interface {
+	isExpr()
+}
+
+type LiteralTestType int
+
+func (LiteralTestType) isExpr() {}
+
+type BinaryTestType struct {
+	Op    string
+	Left  ExprTestType
+	Right ExprTestType
+}
+
+func (BinaryTestType) isExpr() {}
+
+type UnionTestType struct {
+	Event  EventTestType
+	Events []EventTestType
+	Value  interface{} `bindec:"union=StringTestType|IntTestType"`
+	Expr   ExprTestType
+}
+
+type PairTestType[K int | string, V int | string] struct {
+	Key   K
+	Value V
+	Extra []V
+}
+
+type binaryWriter interface {
+	WriteBinary(io.Writer) error
+}
+
+type PageTestType[T binaryWriter] struct {
+	Items []T
+	Next  int
+}
+
+type ListTestType[T int | string] struct {
+	Value T
+	Next  *ListTestType[T]
+}
+
+type IQSampleTestType complex64
+
+type ComplexTestType struct {
+	C64      complex64
+	C128     complex128
+	Samples  []complex64
+	Wide     []complex128
+	Named    []IQSampleTestType
+	Optional *complex128
+	Fixed    [2]complex64
+	Bounded  []complex64 `bindec:"maxlen=4"`
+}
+
+type BaseTestType struct {
+	ID   int
+	Name string `bindec:"maxlen=5"`
+}
+
+type LevelTestType struct {
+	Level int
+}
+
+type AuditTestType struct {
+	LevelTestType
+	CreatedBy string
+}
+
+type EmbeddedTestType struct {
+	BaseTestType   `bindec:"flatten"`
+	*AuditTestType `bindec:"flatten"`
+	*StringTestType
+	EventTestType
+	Value string
+}
+
+type ConflictTestType struct {
+	BaseTestType `bindec:"flatten"`
+	*AuditTestType
+	ID int
+}
+
+type FlattenInterfaceTestType struct {
+	EventTestType `bindec:"flatten"`
+}
+
+type FlattenFieldTestType struct {
+	Base BaseTestType `bindec:"flatten"`
+}
+
+type FlattenRecursiveTestType struct {
+	*FlattenRecursiveTestType `bindec:"flatten"`
+}
+
+type ForeignUnexportedTestType struct {
+	strings.Builder `bindec:"flatten"`
+	Value           int
+}
+
+type AccessorTestType struct {
+	Account  testpkg.Account
+	Accounts []testpkg.Account
+	Token    testpkg.Token
+}
+
+type ForeignSecretTestType struct {
+	Secrets map[string]testpkg.Secret
+}
+
+type AliasTestType struct {
+	V1     []model.User
+	V2     []modelv2.User
+	Points []data.Point
+}
+
+type EncodeOnlyTestType struct {
+	Name  string
+	Items []int
+}
+
+type BytesOnlyTestType struct {
+	Name  string
+	Items []int
+}
+
+type RenamedTestType struct {
+	Name  string
+	Items []int
+}
+
+// EncodeBinary is not generated for RenamedTestType, so it can have its own.
+func (t RenamedTestType) EncodeBinary() string {
+	return t.Name
+}
+
+type MethodConflictTestType struct {
+	WriteBinary string
+}
+
+type FunctionConflictTestType struct {
+	Name string
+}
+
+// EncodeFunctionConflictTestType has the name of a generated function.
+func EncodeFunctionConflictTestType() {}
+
+type HelperConflictTestType struct {
+	Next *HelperConflictTestType
+}
+
+// decodeHelperConflictTestType has the name of a generated helper.
+func decodeHelperConflictTestType() {}
+
+type DiagnosticsTestType struct {
+	Ch    chan int
+	Name  string `bindec:"foo"`
+	Fn    func()
+	Inner struct {
+		Ch chan int
+	}
+	Count int `bindec:"max=foo"`
+	Valid int
+}